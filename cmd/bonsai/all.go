@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+	"github.com/kriscoleman/bonsai/internal/theme"
+	"github.com/kriscoleman/bonsai/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	allBulk     bool
+	allAge      string
+	allDryRun   bool
+	allRemote   string
+	allVerbose  bool
+	allForce    bool
+	allForge    string
+	allArchive  bool
+	allBasis    string
+	allUnpushed bool
+)
+
+var allCmd = &cobra.Command{
+	Use:   "all",
+	Short: "🌳 Prune stale branches locally and on the remote together",
+	Long: `🌳 Prune stale branches locally and on the remote together
+
+See each branch once, with its local branch and its counterpart on the remote
+side by side, and prune the local copy, the remote copy, or the whole branch
+in a single cut.`,
+	RunE: runAllCleanup,
+}
+
+func init() {
+	rootCmd.AddCommand(allCmd)
+
+	allCmd.Flags().BoolVar(&allBulk, "bulk", false, "Delete both sides of all stale branches without interaction")
+	allCmd.Flags().StringVar(&allAge, "age", "2w", "Age threshold for stale branches (e.g., 2w, 14d, 336h)")
+	allCmd.Flags().BoolVar(&allDryRun, "dry-run", false, "Show what would be deleted without actually deleting")
+	allCmd.Flags().StringVar(&allRemote, "remote", "origin", "Remote whose branches are paired with the local ones")
+	allCmd.Flags().BoolVarP(&allVerbose, "verbose", "v", false, "Show detailed error messages")
+	allCmd.Flags().BoolVarP(&allForce, "force", "f", false, "Force delete local branches (git branch -D) even if not fully merged")
+	allCmd.Flags().BoolVar(&allUnpushed, "include-unpushed", false, "With --bulk --force, also delete local branches whose commits exist nowhere else")
+	allCmd.Flags().StringVar(&allForge, "forge", "", "Look up pull/merge requests on a forge to decide what to prune (github, gitlab, auto, none)")
+	allCmd.Flags().StringVar(&allBasis, "age-basis", "", "What branch ages count from: committer, author, last-checkout or created (defaults to committer)")
+	allCmd.Flags().BoolVar(&allArchive, "archive", false, "Keep each branch as an archive/<branch> tag before deleting it")
+}
+
+func runAllCleanup(cmd *cobra.Command, args []string) error {
+	ageThreshold, err := config.ParseDuration(allAge)
+	if err != nil {
+		return fmt.Errorf("invalid age format: %w", err)
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	// Refuse to prune while a rebase, merge, cherry-pick or bisect is unfinished
+	op, err := checkInProgressOperation(repo, allDryRun)
+	if err != nil {
+		return err
+	}
+
+	// Protect branches listed in the config file and on the forge
+	cfg, err := loadConfig(repoPath)
+	if err != nil {
+		return err
+	}
+	basis, err := ageBasis(cfg, allBasis)
+	if err != nil {
+		return err
+	}
+	provider, err := newForgeProvider(repo, cfg, allRemote, allForge)
+	if err != nil {
+		return err
+	}
+	if err := configureProtection(repo, cfg, provider, allRemote); err != nil {
+		return err
+	}
+
+	startSession(cmd, args, repo, cfg, provider, allRemote, ageThreshold, basis)
+
+	local, err := repo.ListLocalBranches()
+	if err != nil {
+		return err
+	}
+
+	// Pins and snoozes shared by others keep their branches out of pruning
+	syncHolds(repo, allRemote)
+
+	remote, err := repo.ListRemoteBranches(allRemote)
+	if err != nil {
+		return err
+	}
+	remote = withoutQuarantined(remote)
+
+	for _, branches := range [][]*git.Branch{local, remote} {
+		if err := repo.ApplyAgeBasis(branches, basis); err != nil {
+			return err
+		}
+	}
+
+	upstreams, err := repo.Upstreams()
+	if err != nil {
+		return err
+	}
+
+	pairs := git.PairBranches(local, remote, upstreams)
+
+	var sides []*git.Branch
+	for _, pair := range pairs {
+		sides = append(sides, pair.Sides()...)
+	}
+
+	// Branches involved in an unfinished operation must never be pruned
+	protectInvolvedBranches(op, sides)
+
+	// Open pull requests protect branches, merged or closed ones qualify them
+	if err := attachPullRequests(provider, sides); err != nil {
+		return err
+	}
+
+	stalePairs := prune.PairCandidates(pairs, ageThreshold)
+
+	var staleSides []*git.Branch
+	for _, pair := range stalePairs {
+		staleSides = append(staleSides, pair.Sides()...)
+	}
+
+	// Commits on no remote and no branch that stays would be lost with them
+	if err := repo.ApplyUnpushed(local, staleSides); err != nil {
+		return err
+	}
+	session.SetCandidates(fullNames(staleSides))
+
+	if len(stalePairs) == 0 {
+		successStyle := lipgloss.NewStyle().
+			Foreground(theme.Colors().Leaf).
+			Bold(true)
+
+		successBox := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(theme.Colors().Highlight).
+			Padding(0, 1).
+			MarginTop(1).
+			MarginBottom(1)
+
+		content := lipgloss.JoinVertical(lipgloss.Left,
+			fmt.Sprintf("🌳 Your repository and %s are perfectly maintained!", allRemote),
+			"   No stale branches found - a true work of art.")
+
+		fmt.Fprintln(stdout, successBox.Render(successStyle.Render(content)))
+		return nil
+	}
+
+	// Without a terminal only dry runs and unattended runs can go on
+	if err := requireTerminal(allDryRun, allBulk); err != nil {
+		return err
+	}
+
+	printBranchSummary(staleSides, "local and remote", ageThreshold, basis, allDryRun)
+
+	if allDryRun {
+		return errCandidates
+	}
+
+	pruneOpts := prune.Options{
+		Force:   allForce,
+		Archive: allArchive,
+		Record:  recordPruned,
+	}
+
+	if allBulk {
+		if allForce && !allUnpushed {
+			if staleSides = withoutAtRisk(staleSides); len(staleSides) == 0 {
+				return nil
+			}
+		}
+		return runBulkDeletion(repo, staleSides, false, allVerbose, pruneOpts)
+	}
+
+	// The detail pane compares branches against the base branch when one can be found
+	pruneOpts.Base, _ = resolveBaseBranch(repo, cfg, allRemote)
+
+	return ui.RunUnifiedSelection(repo, pairs, ageThreshold, allRemote, allVerbose, pruneOpts)
+}
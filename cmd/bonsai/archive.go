@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/theme"
+	"github.com/kriscoleman/bonsai/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveRestoreAs   string
+	archiveRestorePush bool
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "🗄️  Browse and restore archived branches",
+	Long: `🗄️  Browse and restore archived branches
+
+Branches pruned with --archive are kept as annotated archive/<branch> tags.
+List them, or bring one back as a branch when you need it again.`,
+}
+
+var archiveListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List archived branches",
+	Args:  cobra.NoArgs,
+	RunE:  runArchiveList,
+}
+
+var archiveRestoreCmd = &cobra.Command{
+	Use:   "restore <branch|tag>",
+	Short: "Recreate a branch from its archive tag",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runArchiveRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.AddCommand(archiveListCmd)
+	archiveCmd.AddCommand(archiveRestoreCmd)
+
+	archiveRestoreCmd.Flags().StringVar(&archiveRestoreAs, "as", "", "Name of the restored branch (defaults to the original name)")
+	archiveRestoreCmd.Flags().BoolVar(&archiveRestorePush, "push", false, "Push the restored branch back to the remote it was archived from")
+}
+
+func runArchiveList(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	archives, err := repo.ListArchives()
+	if err != nil {
+		return err
+	}
+
+	if len(archives) == 0 {
+		printSummaryBox("🌳 No archived branches",
+			"   Prune with --archive to keep branches as archive/<branch> tags.")
+		return nil
+	}
+
+	nameStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Highlight).
+		Bold(true)
+	detailStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Muted).
+		Italic(true)
+	authorStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Accent)
+
+	fmt.Fprintln(stdout)
+	for _, archive := range archives {
+		archived := "unknown date"
+		if !archive.ArchivedAt.IsZero() {
+			archived = "archived " + ui.FormatAge(time.Since(archive.ArchivedAt))
+		}
+
+		commit := archive.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+
+		fmt.Fprintf(stdout, "  • %s %s\n", nameStyle.Render(archive.FullName()),
+			detailStyle.Render(fmt.Sprintf("(%s, %s, %s)", archive.Tag, commit, archived)))
+		if archive.Author != "" {
+			fmt.Fprintf(stdout, "    👤 %s\n", authorStyle.Render(archive.Author))
+		}
+	}
+
+	printSummaryBox(fmt.Sprintf("🗄️  %d archived branch(es)", len(archives)),
+		"   Restore one with: bonsai archive restore <branch>")
+
+	return nil
+}
+
+func runArchiveRestore(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	archives, err := repo.ListArchives()
+	if err != nil {
+		return err
+	}
+
+	archive := findArchive(archives, args[0])
+	if archive == nil {
+		return fmt.Errorf("no archive found for %q (see bonsai archive list)", args[0])
+	}
+
+	branchName := archive.Branch
+	if archiveRestoreAs != "" {
+		branchName = archiveRestoreAs
+	}
+
+	if err := repo.RestoreArchive(archive, branchName); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", archive.Tag, err)
+	}
+
+	lines := []string{fmt.Sprintf("🌱 Restored %s from %s", branchName, archive.Tag)}
+
+	if archiveRestorePush {
+		if archive.Remote == "" {
+			return fmt.Errorf("%s was archived from a local branch; there is no remote to push to", archive.Tag)
+		}
+		if err := repo.PushBranch(archive.Remote, branchName); err != nil {
+			return fmt.Errorf("failed to push %s to %s: %w", branchName, archive.Remote, err)
+		}
+		lines = append(lines, fmt.Sprintf("   Pushed to %s/%s", archive.Remote, branchName))
+	}
+
+	printSummaryBox(lines...)
+	return nil
+}
+
+// findArchive looks an archive up by tag name first, then by the branch it
+// preserves. When a branch was archived several times the newest wins.
+func findArchive(archives []*git.Archive, name string) *git.Archive {
+	var found *git.Archive
+	for _, archive := range archives {
+		if archive.Tag == name {
+			return archive
+		}
+		if archive.Branch == name || archive.FullName() == name {
+			if found == nil || archive.ArchivedAt.After(found.ArchivedAt) {
+				found = archive
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/bundle"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+var restoreFromBundle string
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore --from-bundle <dir|file> [branch...]",
+	Short: "🌱 Re-import branches exported with --bundle-dir",
+	Long: `🌱 Re-import branches exported with --bundle-dir
+
+Point --from-bundle at a bundle directory to restore the latest export of each
+named branch (or every branch in its manifest), or at a single .bundle file.
+Branches are recreated locally under their original names.`,
+	RunE: runRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVar(&restoreFromBundle, "from-bundle", "", "Bundle directory (with manifest.json) or .bundle file to restore from")
+	_ = restoreCmd.MarkFlagRequired("from-bundle")
+}
+
+// resolveBaseBranch returns the configured base branch, or detects it from the remote
+func resolveBaseBranch(repo *git.Repository, cfg *config.Config, remote string) (string, error) {
+	if cfg.BaseBranch != "" {
+		return cfg.BaseBranch, nil
+	}
+	return repo.DefaultBranch(remote)
+}
+
+// bundleRestore is one branch to re-import from a bundle file
+type bundleRestore struct {
+	path   string
+	ref    string
+	branch string
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(restoreFromBundle)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", restoreFromBundle, err)
+	}
+
+	var restores []bundleRestore
+	if info.IsDir() {
+		restores, err = restoresFromManifest(restoreFromBundle, args)
+	} else {
+		restores, err = restoresFromFile(repo, restoreFromBundle, args)
+	}
+	if err != nil {
+		return err
+	}
+
+	successStyle := lipgloss.NewStyle().Foreground(theme.Colors().Success)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Colors().Warning)
+
+	fmt.Fprintln(stdout)
+	successCount := 0
+	errorCount := 0
+	for _, restore := range restores {
+		if err := repo.FetchBundle(restore.path, restore.ref, restore.branch); err != nil {
+			fmt.Fprintln(stdout, errorStyle.Render(fmt.Sprintf("  ✗ Failed to restore %s: %v", restore.branch, err)))
+			errorCount++
+			continue
+		}
+		fmt.Fprintln(stdout, successStyle.Render(fmt.Sprintf("  ✓ Restored %s from %s", restore.branch, filepath.Base(restore.path))))
+		successCount++
+	}
+
+	printSummaryBox("🌱 Restore complete!",
+		fmt.Sprintf("   %d branches restored, %d failed", successCount, errorCount))
+
+	return nil
+}
+
+// restoresFromManifest picks the latest export of each requested branch, or
+// of every branch in the manifest when none are named
+func restoresFromManifest(dir string, names []string) ([]bundleRestore, error) {
+	manifest, err := bundle.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Entries) == 0 {
+		return nil, fmt.Errorf("no %s found in %s", bundle.ManifestFile, dir)
+	}
+
+	if len(names) == 0 {
+		seen := map[string]bool{}
+		for _, entry := range manifest.Entries {
+			if !seen[entry.FullName()] {
+				seen[entry.FullName()] = true
+				names = append(names, entry.FullName())
+			}
+		}
+	}
+
+	var restores []bundleRestore
+	for _, name := range names {
+		entry, ok := manifest.Latest(name)
+		if !ok {
+			return nil, fmt.Errorf("%s is not in the bundle manifest", name)
+		}
+		if entry.Bundle == "" {
+			// Every commit was already on the base branch, so there is nothing to import
+			if len(names) == 1 {
+				return nil, fmt.Errorf("%s had no commits beyond %s; recreate it from %s", name, entry.Base, entry.Commit)
+			}
+			continue
+		}
+		restores = append(restores, bundleRestore{
+			path:   manifest.Path(entry),
+			ref:    entry.Ref,
+			branch: entry.Branch,
+		})
+	}
+
+	return restores, nil
+}
+
+// restoresFromFile imports the requested heads of a single bundle file, or all of them
+func restoresFromFile(repo *git.Repository, path string, names []string) ([]bundleRestore, error) {
+	heads, err := repo.BundleHeads(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var restores []bundleRestore
+	for _, ref := range heads {
+		branch := bundleBranchName(ref)
+		if len(wanted) > 0 && !wanted[branch] {
+			continue
+		}
+		restores = append(restores, bundleRestore{path: path, ref: ref, branch: branch})
+	}
+
+	if len(restores) == 0 {
+		return nil, fmt.Errorf("no matching branches found in %s", path)
+	}
+
+	return restores, nil
+}
+
+// bundleBranchName turns a ref stored in a bundle back into a branch name,
+// e.g. refs/remotes/origin/feature/x becomes feature/x
+func bundleBranchName(ref string) string {
+	if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+		return name
+	}
+	if name, ok := strings.CutPrefix(ref, "refs/remotes/"); ok {
+		if _, branch, ok := strings.Cut(name, "/"); ok {
+			return branch
+		}
+		return name
+	}
+	return ref
+}
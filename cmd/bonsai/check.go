@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/check"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkLocal         bool
+	checkRemote        string
+	checkAge           string
+	checkBasis         string
+	checkMaxStale      int
+	checkMaxAge        string
+	checkMaxNoUpstream int
+	checkFormat        string
+	checkForge         string
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "🔎 Fail when branches break the repository's limits, for CI",
+	Long: `🔎 Fail when branches break the repository's limits, for CI
+
+Check the remote's branches (or the local ones with --local) against limits
+such as how many stale branches there may be, without changing anything.
+Limits come from the flags or the check section of the config file, and
+staleness is judged exactly as pruning judges it: by the age threshold and
+age basis, protected branches, pins, snoozes and pull requests.
+
+Exits with 5 when a limit is broken. Results can be written as GitHub Actions
+annotations or JUnit XML.`,
+	Example: `  bonsai check --max-stale 20 --max-age 6M
+  bonsai check --local --max-no-upstream 0
+  bonsai check --max-stale 20 --format junit > bonsai.xml`,
+	RunE: runCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().BoolVar(&checkLocal, "local", false, "Check local branches instead of the remote's")
+	checkCmd.Flags().StringVar(&checkRemote, "remote", "", "Remote whose branches are checked (defaults to the configured remote)")
+	checkCmd.Flags().StringVar(&checkAge, "age", "", "Age at which a branch is stale (defaults to the configured threshold)")
+	checkCmd.Flags().StringVar(&checkBasis, "age-basis", "", "What branch ages count from: committer, author, last-checkout or created (defaults to committer)")
+	checkCmd.Flags().IntVar(&checkMaxStale, "max-stale", -1, "Fail when more branches than this are stale")
+	checkCmd.Flags().StringVar(&checkMaxAge, "max-age", "", "Fail for every branch older than this (e.g., 6M)")
+	checkCmd.Flags().IntVar(&checkMaxNoUpstream, "max-no-upstream", -1, "Fail when more local branches than this have no upstream (needs --local)")
+	checkCmd.Flags().StringVar(&checkFormat, "format", check.FormatText, "Output format: "+strings.Join(check.Formats, ", "))
+	checkCmd.Flags().StringVar(&checkForge, "forge", "", "Look up pull/merge requests on a forge to judge staleness (github, gitlab, auto, none)")
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(check.Formats, checkFormat) {
+		return fmt.Errorf("unsupported output format: %s (available: %s)", checkFormat, strings.Join(check.Formats, ", "))
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(repoPath)
+	if err != nil {
+		return err
+	}
+	remote := checkRemote
+	if remote == "" {
+		remote = cfg.RemoteName
+	}
+
+	policy, err := checkPolicy(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	basis, err := ageBasis(cfg, checkBasis)
+	if err != nil {
+		return err
+	}
+
+	// Protection and pull requests count just as they do when pruning
+	provider, err := newForgeProvider(repo, cfg, remote, checkForge)
+	if err != nil {
+		return err
+	}
+	if err := configureProtection(repo, cfg, provider, remote); err != nil {
+		return err
+	}
+
+	var branches []*git.Branch
+	var upstreams map[string]git.Upstream
+	if checkLocal {
+		if branches, err = repo.ListLocalBranches(); err != nil {
+			return err
+		}
+		if upstreams, err = repo.Upstreams(); err != nil {
+			return err
+		}
+	} else {
+		syncHolds(repo, remote)
+		if branches, err = repo.ListRemoteBranches(remote); err != nil {
+			return err
+		}
+		branches = withoutQuarantined(branches)
+	}
+
+	if err := repo.ApplyAgeBasis(branches, basis); err != nil {
+		return err
+	}
+
+	if err := attachPullRequests(provider, branches); err != nil {
+		return err
+	}
+
+	report := check.Run(branches, upstreams, policy)
+	if checkFormat == check.FormatText {
+		printCheckReport(report)
+	} else if err := report.Write(stdout, checkFormat); err != nil {
+		return err
+	}
+
+	if !report.Passed() {
+		return &exitStatus{code: exitViolations, err: fmt.Errorf("%d of %d check(s) failed", report.Failed(), len(report.Rules))}
+	}
+	return nil
+}
+
+// checkPolicy combines the limits in the config file with those given as flags
+func checkPolicy(cmd *cobra.Command, cfg *config.Config) (check.Policy, error) {
+	policy := check.Policy{
+		Threshold:     cfg.RemoteAgeThreshold,
+		MaxStale:      cfg.Check.MaxStale,
+		MaxAge:        cfg.Check.MaxAge,
+		MaxNoUpstream: cfg.Check.MaxNoUpstream,
+	}
+	if checkLocal {
+		policy.Threshold = cfg.LocalAgeThreshold
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("age") {
+		threshold, err := config.ParseDuration(checkAge)
+		if err != nil {
+			return policy, fmt.Errorf("invalid age format: %w", err)
+		}
+		policy.Threshold = threshold
+	}
+	if flags.Changed("max-stale") {
+		policy.MaxStale = checkMaxStale
+	}
+	if flags.Changed("max-age") {
+		maxAge, err := config.ParseDuration(checkMaxAge)
+		if err != nil {
+			return policy, fmt.Errorf("invalid max age: %w", err)
+		}
+		policy.MaxAge = maxAge
+	}
+	if flags.Changed("max-no-upstream") {
+		if !checkLocal {
+			return policy, fmt.Errorf("--max-no-upstream applies to local branches; add --local")
+		}
+		policy.MaxNoUpstream = checkMaxNoUpstream
+	}
+
+	// Only local branches have upstreams
+	if !checkLocal {
+		policy.MaxNoUpstream = -1
+	}
+
+	if policy.Empty() {
+		return policy, fmt.Errorf("nothing to check: set --max-stale, --max-age or --max-no-upstream, or limits under check in the config file")
+	}
+	return policy, nil
+}
+
+// printCheckReport lists every rule with what broke it
+func printCheckReport(report *check.Report) {
+	passedStyle := lipgloss.NewStyle().Foreground(theme.Colors().Success)
+	failedStyle := lipgloss.NewStyle().Foreground(theme.Colors().Warning).Bold(true)
+	detailStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Muted).
+		Italic(true)
+
+	fmt.Fprintln(stdout)
+	for _, rule := range report.Rules {
+		title := fmt.Sprintf("%s (%s)", rule.Name, rule.Limit)
+		if rule.Passed() {
+			fmt.Fprintln(stdout, passedStyle.Render("  ✓ "+title))
+			continue
+		}
+
+		fmt.Fprintln(stdout, failedStyle.Render("  ✗ "+title))
+		for _, failure := range rule.Failures {
+			fmt.Fprintln(stdout, detailStyle.Render("      "+failure.Message))
+		}
+	}
+
+	if report.Passed() {
+		printSummaryBox("🌳 Every branch is within limits",
+			fmt.Sprintf("   %d branch(es) checked", report.Branches))
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/kriscoleman/bonsai/internal/ui"
+)
+
+// Exit codes, so that scripts can tell outcomes apart
+const (
+	exitOK         = 0 // Nothing to do, or everything was done
+	exitError      = 1 // Bonsai could not run, e.g. a bad flag or not a repository
+	exitCandidates = 2 // A dry run found branches to prune
+	exitPartial    = 3 // Some branches could not be pruned
+	exitFailed     = 4 // No branch could be pruned
+	exitViolations = 5 // bonsai check found branches breaking a limit
+)
+
+// errCandidates ends a dry run that found branches to prune
+var errCandidates = &exitStatus{code: exitCandidates}
+
+// exitStatus ends bonsai with a particular exit code. Without an error
+// nothing is reported.
+type exitStatus struct {
+	code int
+	err  error
+}
+
+func (e *exitStatus) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitStatus) Unwrap() error { return e.err }
+
+// pruneOutcome is the result of pruning branches: nothing when all of them
+// were pruned, otherwise how many failed
+func pruneOutcome(pruned, failed int) error {
+	if failed == 0 {
+		return nil
+	}
+	if pruned == 0 {
+		return &exitStatus{code: exitFailed, err: fmt.Errorf("none of %d branch(es) could be pruned", failed)}
+	}
+	return &exitStatus{code: exitPartial, err: fmt.Errorf("%d of %d branch(es) could not be pruned", failed, pruned+failed)}
+}
+
+// exitCode is the exit code bonsai ends with after err
+func exitCode(err error) int {
+	var status *exitStatus
+	if errors.As(err, &status) {
+		return status.code
+	}
+
+	var deleteErr *ui.DeleteError
+	if errors.As(err, &deleteErr) {
+		if deleteErr.Deleted == 0 {
+			return exitFailed
+		}
+		return exitPartial
+	}
+
+	if err != nil {
+		return exitError
+	}
+	return exitOK
+}
+
+// isTerminal reports whether bonsai can talk to a user, with both its input
+// and its output attached to a terminal
+func isTerminal() bool {
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd())
+}
+
+// requireTerminal refuses to go on without a terminal when bonsai would
+// need to ask the user something: in interactive mode, where the UI would
+// hang or misbehave, and for confirmations that --yes doesn't answer
+func requireTerminal(dryRun, unattended bool) error {
+	switch {
+	case dryRun || isTerminal():
+		return nil
+	case unattended && assumeYes:
+		return nil
+	case unattended:
+		return fmt.Errorf("confirmation needs a terminal; pass --yes to proceed without it")
+	default:
+		return fmt.Errorf("interactive mode needs a terminal; use --dry-run to list stale branches or --bulk --yes to prune them unattended")
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/forge"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/theme"
+)
+
+// newForgeProvider creates the pull request provider selected by the --forge
+// flag, falling back to the config file. It returns nil when pull request
+// awareness is disabled. Forge settings only come from the user's own config,
+// so a token from the environment goes to the API behind the remote URL or to
+// a base URL the user chose, never to one picked by a cloned repository.
+func newForgeProvider(repo *git.Repository, cfg *config.Config, remote, name string) (forge.Provider, error) {
+	if name == "" {
+		name = cfg.Forge.Provider
+	}
+
+	if name == "" || name == "none" {
+		return nil, nil
+	}
+
+	switch name {
+	case "github", "gitlab", "auto":
+	default:
+		return nil, fmt.Errorf("unsupported forge: %s (expected github, gitlab, auto or none)", name)
+	}
+
+	remoteURL, err := repo.RemoteURL(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "auto" {
+		name = forge.Detect(remoteURL)
+		if name == "" {
+			return nil, fmt.Errorf("could not detect the forge hosting %s; use --forge github or --forge gitlab", remote)
+		}
+	}
+
+	switch name {
+	case "github":
+		return forge.NewGitHub(remoteURL, forge.Options{
+			Token:   firstNonEmpty(cfg.Forge.GitHub.Token, os.Getenv("GITHUB_TOKEN"), os.Getenv("GH_TOKEN")),
+			BaseURL: cfg.Forge.GitHub.BaseURL,
+		})
+	case "gitlab":
+		return forge.NewGitLab(remoteURL, forge.Options{
+			Token:   firstNonEmpty(cfg.Forge.GitLab.Token, os.Getenv("GITLAB_TOKEN")),
+			BaseURL: cfg.Forge.GitLab.BaseURL,
+		})
+	}
+
+	return nil, fmt.Errorf("unsupported forge: %s", name)
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// configureProtection merges the config file's protected branches and the
+// forge's server-side protection rules (cached in the state directory) into
+// the repository's protection matcher
+func configureProtection(repo *git.Repository, cfg *config.Config, provider forge.Provider, remote string) error {
+	matcher := git.NewProtectionMatcher(cfg.ProtectedBranches...)
+
+	if provider != nil {
+		stateDir, err := repo.StateDir()
+		if err != nil {
+			return err
+		}
+
+		cachePath := filepath.Join(stateDir, fmt.Sprintf("protection-%s.json", remote))
+		patterns, err := forge.CachedProtectionRules(provider, cachePath, cfg.Forge.ProtectionTTL)
+		if err != nil {
+			return fmt.Errorf("failed to fetch branch protection rules from %s: %w", provider.Name(), err)
+		}
+		matcher.Add(patterns...)
+	}
+
+	repo.Protection = matcher
+	return nil
+}
+
+// attachPullRequests looks up the pull (or merge) request for each branch and
+// records it on the branch so filtering and display can take it into account
+func attachPullRequests(provider forge.Provider, branches []*git.Branch) error {
+	if provider == nil {
+		return nil
+	}
+
+	// Current and protected branches are never pruned, so skip the lookup
+	var names []string
+	for _, branch := range branches {
+		if branch.IsCurrent || branch.IsProtected {
+			continue
+		}
+		names = append(names, branch.Name)
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Muted).
+		Italic(true)
+	fmt.Fprintln(stdout, infoStyle.Render(fmt.Sprintf("🔎 Checking pull requests on %s...", provider.Name())))
+
+	pullRequests, err := provider.PullRequests(names)
+	if err != nil {
+		return fmt.Errorf("failed to fetch pull requests from %s: %w", provider.Name(), err)
+	}
+
+	for _, branch := range branches {
+		branch.PullRequest = pullRequests[branch.Name]
+	}
+
+	return nil
+}
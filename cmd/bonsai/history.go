@@ -0,0 +1,425 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/forge"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/history"
+	"github.com/kriscoleman/bonsai/internal/prune"
+	"github.com/kriscoleman/bonsai/internal/theme"
+	"github.com/kriscoleman/bonsai/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// session records the running command in the history log; nil when the
+// command doesn't keep one
+var session *history.Session
+
+// sessionPath is the history file the session is appended to
+var sessionPath string
+
+var (
+	historySince  string
+	historyUntil  string
+	historyBranch string
+	historyJSON   bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "🧾 Browse the log of past pruning sessions",
+	Long: `🧾 Browse the log of past pruning sessions
+
+Every run of local, remote and all is logged with its flags, the
+configuration in effect, the branches it found and what happened to them,
+and so are scheduled runs and the branches git hooks prune.
+List the sessions, narrow them down by date or branch, and show one in full.
+The log is for auditing; it can't bring branches back.`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <number>",
+	Short: "Show the full details of a session",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runHistoryShow,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyShowCmd)
+
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only sessions after this date (YYYY-MM-DD) or this long ago (e.g., 2w)")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "Only sessions before this date (YYYY-MM-DD) or this long ago (e.g., 2w)")
+	historyCmd.Flags().StringVar(&historyBranch, "branch", "", "Only sessions that found or pruned this branch (name or glob)")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Write the sessions as JSON")
+	historyShowCmd.Flags().BoolVar(&historyJSON, "json", false, "Write the session as JSON")
+}
+
+// startSession begins logging the running command. Without a state
+// directory to log to, the command runs unlogged.
+func startSession(cmd *cobra.Command, args []string, repo *git.Repository, cfg *config.Config, provider forge.Provider, remote string, age time.Duration, basis git.AgeBasis) {
+	stateDir, err := repo.StateDir()
+	if err != nil {
+		return
+	}
+	sessionPath = filepath.Join(stateDir, history.FileName)
+
+	session = history.Start(strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" "))
+	session.Args = args
+	session.User = repo.UserIdentity()
+	if session.User == "" {
+		if current, err := user.Current(); err == nil {
+			session.User = current.Username
+		}
+	}
+
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if session.Flags == nil {
+			session.Flags = map[string]string{}
+		}
+		session.Flags[flag.Name] = flag.Value.String()
+	})
+
+	session.Config = history.Config{
+		AgeBasis:  string(basis),
+		Remote:    remote,
+		Protected: cfg.ProtectedBranches,
+	}
+	if age > 0 {
+		session.Config.Age = age.String()
+	}
+	if provider != nil {
+		session.Config.Forge = provider.Name()
+	}
+}
+
+// finishSession appends the session, if there is one, to the history log
+func finishSession(err error) {
+	if session == nil {
+		return
+	}
+
+	session.Finish(err, exitCode(err))
+	if err := history.Append(sessionPath, session); err != nil {
+		fmt.Fprintf(stderr, "⚠️  %v\n", err)
+	}
+}
+
+// recordPruned logs what happened to a pruned branch; it is the Record hook
+// of the prune options
+func recordPruned(branch *git.Branch, result *prune.Result, err error) {
+	entry := history.Result{Branch: branch.FullName(), Action: history.ActionDeleted}
+	if result != nil {
+		var details []string
+		if result.ArchiveTag != "" {
+			entry.Action = history.ActionArchived
+			details = append(details, "tag "+result.ArchiveTag)
+		}
+		if result.Bundle != "" {
+			details = append(details, "bundle "+result.Bundle)
+		}
+		if result.Salvage != nil {
+			details = append(details, "patches in "+result.Salvage.Dir)
+		}
+		entry.Detail = strings.Join(details, ", ")
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	session.Record(entry)
+}
+
+// errorText is the message of err, or nothing without an error
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// fullNames returns the full names of the branches
+func fullNames(branches []*git.Branch) []string {
+	names := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		names = append(names, branch.FullName())
+	}
+	return names
+}
+
+// loadHistory reads the repository's history log
+func loadHistory() ([]*history.Session, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, err
+	}
+	stateDir, err := repo.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	return history.Load(filepath.Join(stateDir, history.FileName))
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	var filter history.Filter
+	var err error
+	if filter.Since, err = parseHistoryTime(historySince, false); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.Until, err = parseHistoryTime(historyUntil, true); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+	filter.Branch = historyBranch
+
+	sessions, err := loadHistory()
+	if err != nil {
+		return err
+	}
+
+	matching := []*history.Session{}
+	for _, s := range sessions {
+		if filter.Match(s) {
+			matching = append(matching, s)
+		}
+	}
+
+	if historyJSON {
+		return writeJSON(matching)
+	}
+
+	if len(matching) == 0 {
+		if len(sessions) == 0 {
+			printSummaryBox("🧾 No sessions logged yet",
+				"   Runs of bonsai local, remote and all are logged here.")
+		} else {
+			printSummaryBox("🧾 No sessions match",
+				fmt.Sprintf("   None of the %d logged session(s) pass the filters.", len(sessions)))
+		}
+		return nil
+	}
+
+	numberStyle := lipgloss.NewStyle().Foreground(theme.Colors().Muted)
+	commandStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Highlight).
+		Bold(true)
+	detailStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Muted).
+		Italic(true)
+	successStyle := lipgloss.NewStyle().Foreground(theme.Colors().Success)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Colors().Warning)
+
+	width := len(strconv.Itoa(matching[len(matching)-1].Number)) + 1
+
+	fmt.Fprintln(stdout)
+	for _, s := range matching {
+		outcome := successStyle.Render(sessionOutcome(s))
+		if s.Error != "" || s.Failed() > 0 {
+			outcome = errorStyle.Render(sessionOutcome(s))
+		}
+
+		fmt.Fprintf(stdout, "  %s %s %s · %s\n",
+			numberStyle.Render(fmt.Sprintf("%*s", width, "#"+strconv.Itoa(s.Number))),
+			detailStyle.Render(s.StartedAt.Local().Format("2006-01-02 15:04")),
+			commandStyle.Render(commandLine(s)),
+			outcome)
+	}
+
+	printSummaryBox(fmt.Sprintf("🧾 %d session(s)", len(matching)),
+		"   Show one in full with: bonsai history show <number>")
+
+	return nil
+}
+
+func runHistoryShow(cmd *cobra.Command, args []string) error {
+	number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return fmt.Errorf("invalid session number: %s", args[0])
+	}
+
+	sessions, err := loadHistory()
+	if err != nil {
+		return err
+	}
+
+	var s *history.Session
+	for _, candidate := range sessions {
+		if candidate.Number == number {
+			s = candidate
+		}
+	}
+	if s == nil {
+		return fmt.Errorf("no session #%d (see bonsai history)", number)
+	}
+
+	if historyJSON {
+		return writeJSON(s)
+	}
+
+	printSession(s)
+	return nil
+}
+
+// writeJSON writes v to stdout as indented JSON
+func writeJSON(v any) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
+
+// parseHistoryTime parses a --since or --until value: a date, or a duration
+// meaning that long ago. A date given as an upper bound includes that day.
+func parseHistoryTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			date = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return date, nil
+	}
+
+	ago, err := config.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a date (YYYY-MM-DD) or a duration (e.g., 2w): %s", value)
+	}
+	return time.Now().Add(-ago), nil
+}
+
+// commandLine renders the command a session ran, with its flags
+func commandLine(s *history.Session) string {
+	parts := []string{s.Command}
+
+	names := make([]string, 0, len(s.Flags))
+	for name := range s.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value := s.Flags[name]; value == "true" {
+			parts = append(parts, "--"+name)
+		} else {
+			parts = append(parts, fmt.Sprintf("--%s %s", name, value))
+		}
+	}
+
+	return strings.Join(append(parts, s.Args...), " ")
+}
+
+// sessionOutcome summarizes what a session did in a few words
+func sessionOutcome(s *history.Session) string {
+	counts := map[string]int{}
+	var actions []string
+	for _, result := range s.Results {
+		if result.Error != "" {
+			continue
+		}
+		if counts[result.Action] == 0 {
+			actions = append(actions, result.Action)
+		}
+		counts[result.Action]++
+	}
+
+	var parts []string
+	for _, action := range actions {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[action], action))
+	}
+	if failed := s.Failed(); failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", failed))
+	}
+
+	switch {
+	case len(parts) > 0:
+	case s.Error != "" && s.ExitCode != exitCandidates:
+		parts = append(parts, "error: "+s.Error)
+	case len(s.Candidates) > 0:
+		parts = append(parts, fmt.Sprintf("%d candidate(s), nothing pruned", len(s.Candidates)))
+	default:
+		parts = append(parts, "nothing to prune")
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// printSession renders everything logged about a session
+func printSession(s *history.Session) {
+	headingStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Leaf).
+		Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(theme.Colors().Muted)
+	valueStyle := lipgloss.NewStyle().Foreground(theme.Colors().Text)
+	nameStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Highlight).
+		Bold(true)
+	detailStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Muted).
+		Italic(true)
+	successStyle := lipgloss.NewStyle().Foreground(theme.Colors().Success)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Colors().Warning)
+
+	field := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(stdout, "  %s %s\n", labelStyle.Render(fmt.Sprintf("%-12s", label)), valueStyle.Render(value))
+		}
+	}
+
+	protected := "defaults"
+	if len(s.Config.Protected) > 0 {
+		protected = strings.Join(s.Config.Protected, ", ")
+	}
+
+	printSummaryBox(fmt.Sprintf("🧾 Session #%d: %s", s.Number, commandLine(s)),
+		"   "+sessionOutcome(s))
+
+	field("Started", fmt.Sprintf("%s (%s)", s.StartedAt.Local().Format("2006-01-02 15:04:05 MST"), ui.FormatAge(time.Since(s.StartedAt))))
+	field("Duration", s.Duration)
+	field("User", s.User)
+	field("Exit code", strconv.Itoa(s.ExitCode))
+	field("Error", s.Error)
+
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, headingStyle.Render("⚙️  Configuration"))
+	field("Age", s.Config.Age)
+	field("Age basis", s.Config.AgeBasis)
+	field("Remote", s.Config.Remote)
+	field("Protected", protected)
+	field("Forge", s.Config.Forge)
+
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, headingStyle.Render(fmt.Sprintf("🔎 Candidates (%d)", len(s.Candidates))))
+	for _, name := range s.Candidates {
+		fmt.Fprintf(stdout, "  • %s\n", nameStyle.Render(name))
+	}
+
+	if len(s.Results) > 0 {
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, headingStyle.Render(fmt.Sprintf("🪓 Results (%d)", len(s.Results))))
+		for _, result := range s.Results {
+			if result.Error != "" {
+				fmt.Fprintf(stdout, "  %s %s\n", errorStyle.Render("✗ "+result.Branch), detailStyle.Render(strings.ReplaceAll(result.Error, "\n", " ")))
+				continue
+			}
+			line := successStyle.Render(fmt.Sprintf("✓ %s %s", result.Branch, result.Action))
+			if result.Detail != "" {
+				line += " " + detailStyle.Render("("+result.Detail+")")
+			}
+			fmt.Fprintf(stdout, "  %s\n", line)
+		}
+	}
+
+	fmt.Fprintln(stdout)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var holdRemote string
+
+var pinCmd = &cobra.Command{
+	Use:   "pin <branch>",
+	Short: "📌 Keep a branch out of pruning until it is unpinned",
+	Long: `📌 Keep a branch out of pruning until it is unpinned
+
+Pin a branch you are coming back to. Pins on local branches are kept in the
+repository's git config; pins on remote branches, given as <remote>/<branch>,
+are shared with everyone through the remote's refs/bonsai/meta ref.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPin,
+}
+
+var unpinCmd = &cobra.Command{
+	Use:   "unpin <branch>",
+	Short: "Let a pinned branch be pruned again",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUnpin,
+}
+
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze <branch> <duration>",
+	Short: "💤 Keep a branch out of pruning for a while",
+	Long: `💤 Keep a branch out of pruning for a while
+
+Snooze a branch for a duration such as 3w or 2M. Once the snooze is over the
+branch is offered for pruning again. Like pins, snoozes on remote branches
+are shared through the remote.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSnooze,
+}
+
+var unsnoozeCmd = &cobra.Command{
+	Use:   "unsnooze <branch>",
+	Short: "End a branch's snooze early",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUnsnooze,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{pinCmd, unpinCmd, snoozeCmd, unsnoozeCmd} {
+		rootCmd.AddCommand(cmd)
+		cmd.Flags().StringVar(&holdRemote, "remote", "", "Remote of remote branches (defaults to the configured remote)")
+	}
+}
+
+func runPin(cmd *cobra.Command, args []string) error {
+	return holdBranch(args[0], func(repo *git.Repository, branch *git.Branch) (string, error) {
+		return fmt.Sprintf("📌 Pinned %s", branch.FullName()), repo.PinBranch(branch)
+	})
+}
+
+func runUnpin(cmd *cobra.Command, args []string) error {
+	return holdBranch(args[0], func(repo *git.Repository, branch *git.Branch) (string, error) {
+		if !branch.IsPinned {
+			return "", fmt.Errorf("%s is not pinned", branch.FullName())
+		}
+		return fmt.Sprintf("Unpinned %s", branch.FullName()), repo.UnpinBranch(branch)
+	})
+}
+
+func runSnooze(cmd *cobra.Command, args []string) error {
+	duration, err := config.ParseDuration(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid snooze duration: %w", err)
+	}
+	until := time.Now().Add(duration)
+
+	return holdBranch(args[0], func(repo *git.Repository, branch *git.Branch) (string, error) {
+		return fmt.Sprintf("💤 Snoozed %s until %s", branch.FullName(), until.Format("Jan 2, 2006")),
+			repo.SnoozeBranch(branch, until)
+	})
+}
+
+func runUnsnooze(cmd *cobra.Command, args []string) error {
+	return holdBranch(args[0], func(repo *git.Repository, branch *git.Branch) (string, error) {
+		if !branch.IsSnoozed() {
+			return "", fmt.Errorf("%s is not snoozed", branch.FullName())
+		}
+		return fmt.Sprintf("⏰ Woke up %s", branch.FullName()), repo.UnsnoozeBranch(branch)
+	})
+}
+
+// holdBranch finds the branch named name and changes how it is held
+func holdBranch(name string, change func(*git.Repository, *git.Branch) (string, error)) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	remote := holdRemote
+	if remote == "" {
+		cfg, err := loadConfig(repoPath)
+		if err != nil {
+			return err
+		}
+		remote = cfg.RemoteName
+	}
+
+	branch, err := findBranch(repo, name, remote)
+	if err != nil {
+		return err
+	}
+
+	done, err := change(repo, branch)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{done}
+	if branch.IsRemote {
+		lines = append(lines, fmt.Sprintf("   Shared with everyone on %s", branch.RemoteName))
+	}
+	printSummaryBox(lines...)
+	return nil
+}
+
+// findBranch looks a branch up among the local branches first, then among
+// the branches of remote, where it may be named with or without the remote
+func findBranch(repo *git.Repository, name, remote string) (*git.Branch, error) {
+	local, err := repo.ListLocalBranches()
+	if err != nil {
+		return nil, err
+	}
+	for _, branch := range local {
+		if branch.Name == name {
+			return branch, nil
+		}
+	}
+
+	syncHolds(repo, remote)
+	branches, err := repo.ListRemoteBranches(remote)
+	if err != nil {
+		return nil, err
+	}
+	for _, branch := range branches {
+		if branch.Name == strings.TrimPrefix(name, remote+"/") {
+			return branch, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no branch named %s locally or on %s", name, remote)
+}
+
+// syncHolds fetches the holds shared on remote so that pins and snoozes from
+// others are honored. Without access to the remote the last fetched ones are
+// used.
+func syncHolds(repo *git.Repository, remote string) {
+	if err := repo.FetchHolds(remote); err != nil {
+		fmt.Fprintf(stderr, "⚠️  %v; using the holds fetched last\n", err)
+	}
+}
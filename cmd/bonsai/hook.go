@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/history"
+	"github.com/kriscoleman/bonsai/internal/hook"
+	"github.com/kriscoleman/bonsai/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+var hookPolicy string
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "🪝 Prune merged branches from git hooks",
+	Long: `🪝 Prune merged branches from git hooks
+
+Install post-merge and post-checkout hooks that notice local branches which
+just became merged into the base branch, or whose upstream is gone, after you
+pull. Depending on the policy bonsai suggests pruning them or prunes merged
+ones right away. Existing hooks keep running: bonsai chains them instead of
+overwriting them.`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the bonsai git hooks",
+	Args:  cobra.NoArgs,
+	RunE:  runHookInstall,
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the bonsai git hooks and restore chained ones",
+	Args:  cobra.NoArgs,
+	RunE:  runHookUninstall,
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:          "run <hook> [-- hook arguments]",
+	Short:        "Entry point called by the installed hooks",
+	Hidden:       true,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runHookRun,
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+	hookCmd.AddCommand(hookRunCmd)
+
+	hookInstallCmd.Flags().StringVar(&hookPolicy, "policy", string(hook.PolicySuggest), "What to do with branches that just became merged or gone (suggest, prune)")
+	hookRunCmd.Flags().StringVar(&hookPolicy, "policy", string(hook.PolicySuggest), "What to do with branches that just became merged or gone (suggest, prune)")
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	policy, err := hook.ParsePolicy(hookPolicy)
+	if err != nil {
+		return err
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	hooksDir, err := repo.HooksDir()
+	if err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the bonsai executable: %w", err)
+	}
+
+	lines := []string{fmt.Sprintf("🪝 Hooks installed in %s (policy: %s)", hooksDir, policy)}
+	for _, name := range hook.Names {
+		chained, err := hook.Install(hooksDir, name, executable, policy)
+		if err != nil {
+			return err
+		}
+		if chained {
+			lines = append(lines, fmt.Sprintf("   %s: existing hook kept as %s%s", name, name, hook.ChainedSuffix))
+		} else {
+			lines = append(lines, fmt.Sprintf("   %s", name))
+		}
+	}
+
+	// Record what is merged or gone today so only later changes are reported
+	if _, err := detectHookChanges(repo); err != nil {
+		return err
+	}
+
+	printSummaryBox(lines...)
+	return nil
+}
+
+func runHookUninstall(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	hooksDir, err := repo.HooksDir()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, name := range hook.Names {
+		ok, err := hook.Uninstall(hooksDir, name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			removed++
+		}
+	}
+
+	if removed == 0 {
+		printSummaryBox("🪝 No bonsai hooks installed in " + hooksDir)
+		return nil
+	}
+
+	printSummaryBox(fmt.Sprintf("🪝 Removed %d bonsai hook(s) from %s", removed, hooksDir),
+		"   Chained hooks were put back in place.")
+	return nil
+}
+
+// hookChanges lists the branches that became merged or gone since the last hook run
+type hookChanges struct {
+	cfg    *config.Config
+	merged map[string]bool // Every branch currently merged into the base branch
+	fresh  []string        // Branches that just became merged or gone
+}
+
+// detectHookChanges compares the merged and gone branches with the ones
+// recorded by the previous run and saves the new state
+func detectHookChanges(repo *git.Repository) (*hookChanges, error) {
+	cfg, err := loadConfig(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	repo.Protection = git.NewProtectionMatcher(cfg.ProtectedBranches...)
+
+	base, err := resolveBaseBranch(repo, cfg, cfg.RemoteName)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := repo.ListLocalBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	// Current, protected, pinned, snoozed and base branches are never reported
+	eligible := map[string]bool{}
+	for _, branch := range branches {
+		if !branch.IsCurrent && !branch.IsProtected && !branch.IsHeld() && branch.Name != base {
+			eligible[branch.Name] = true
+		}
+	}
+
+	merged, err := repo.MergedBranches(base)
+	if err != nil {
+		return nil, err
+	}
+	gone, err := repo.GoneBranches()
+	if err != nil {
+		return nil, err
+	}
+	merged = onlyEligible(merged, eligible)
+	gone = onlyEligible(gone, eligible)
+
+	stateDir, err := repo.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	state, err := hook.LoadState(filepath.Join(stateDir, "hook-state.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	newlyMerged, newlyGone := state.Update(merged, gone)
+	if err := state.Save(); err != nil {
+		return nil, err
+	}
+
+	changes := &hookChanges{cfg: cfg, merged: map[string]bool{}}
+	for _, name := range merged {
+		changes.merged[name] = true
+	}
+
+	seen := map[string]bool{}
+	for _, name := range append(newlyMerged, newlyGone...) {
+		if !seen[name] {
+			seen[name] = true
+			changes.fresh = append(changes.fresh, name)
+		}
+	}
+
+	return changes, nil
+}
+
+// onlyEligible keeps the names present in eligible
+func onlyEligible(names []string, eligible map[string]bool) []string {
+	var result []string
+	for _, name := range names {
+		if eligible[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func runHookRun(cmd *cobra.Command, args []string) error {
+	policy, err := hook.ParsePolicy(hookPolicy)
+	if err != nil {
+		return err
+	}
+
+	// post-checkout receives <previous HEAD> <new HEAD> <flag>; a flag of 0 means a file checkout
+	hookName, hookArgs := args[0], args[1:]
+	if hookName == "post-checkout" && len(hookArgs) >= 3 && hookArgs[2] != "1" {
+		return nil
+	}
+
+	repo := git.NewRepository("")
+
+	// Checkouts happen constantly during a rebase; wait until it is over
+	if op, err := repo.InProgressOperation(); err != nil || op != nil {
+		return nil
+	}
+
+	changes, err := detectHookChanges(repo)
+	if err != nil || len(changes.fresh) == 0 {
+		return err
+	}
+
+	noticeStyle := lipgloss.NewStyle().Foreground(theme.Colors().Leaf)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Colors().Warning)
+
+	// Only merged branches are pruned automatically; gone ones may hold unmerged work
+	var toPrune, suggest []string
+	for _, name := range changes.fresh {
+		if policy == hook.PolicyPrune && changes.merged[name] {
+			toPrune = append(toPrune, name)
+		} else {
+			suggest = append(suggest, name)
+		}
+	}
+
+	// Only runs that prune something are logged
+	if len(toPrune) > 0 {
+		startSession(cmd, args, repo, changes.cfg, nil, changes.cfg.RemoteName, 0, "")
+		session.SetCandidates(toPrune)
+	}
+
+	for _, name := range toPrune {
+		if err := repo.DeleteLocalBranch(name, false); err != nil {
+			session.Record(history.Result{Branch: name, Action: history.ActionDeleted, Error: err.Error()})
+			fmt.Fprintln(stdout, errorStyle.Render(fmt.Sprintf("🌳 bonsai: could not prune %s: %v", name, err)))
+			continue
+		}
+		session.Record(history.Result{Branch: name, Action: history.ActionDeleted, Detail: "merged"})
+		fmt.Fprintln(stdout, noticeStyle.Render(fmt.Sprintf("🌳 bonsai: pruned %s (merged)", name)))
+	}
+
+	if len(suggest) > 0 {
+		flag := "-d"
+		for _, name := range suggest {
+			if !changes.merged[name] {
+				flag = "-D"
+			}
+		}
+		fmt.Fprintln(stdout, noticeStyle.Render(fmt.Sprintf("🌿 bonsai: %d branch(es) just merged or gone upstream - prune with: git branch %s %s",
+			len(suggest), flag, strings.Join(suggest, " "))))
+	}
+
+	return nil
+}
@@ -2,22 +2,31 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kriscoleman/bonsai/internal/config"
 	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+	"github.com/kriscoleman/bonsai/internal/theme"
 	"github.com/kriscoleman/bonsai/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	localBulk    bool
-	localAge     string
-	localDryRun  bool
-	localVerbose bool
-	localForce   bool
+	localBulk     bool
+	localAge      string
+	localDryRun   bool
+	localVerbose  bool
+	localForce    bool
+	localForge    string
+	localArchive  bool
+	localBundle   string
+	localSalvage  string
+	localBasis    string
+	localUnpushed bool
 )
 
 var localCmd = &cobra.Command{
@@ -39,6 +48,12 @@ func init() {
 	localCmd.Flags().BoolVar(&localDryRun, "dry-run", false, "Show what would be deleted without actually deleting")
 	localCmd.Flags().BoolVarP(&localVerbose, "verbose", "v", false, "Show detailed error messages")
 	localCmd.Flags().BoolVarP(&localForce, "force", "f", false, "Force delete branches (git branch -D) even if not fully merged")
+	localCmd.Flags().BoolVar(&localArchive, "archive", false, "Keep each branch as an archive/<branch> tag before deleting it")
+	localCmd.Flags().StringVar(&localBundle, "bundle-dir", "", "Write a git bundle of each branch's unique commits to this directory before deleting it")
+	localCmd.Flags().StringVar(&localSalvage, "salvage-dir", "", "Save each unmerged branch's commits as format-patch files under this directory before deleting it")
+	localCmd.Flags().StringVar(&localBasis, "age-basis", "", "What branch ages count from: committer, author, last-checkout or created (defaults to committer)")
+	localCmd.Flags().BoolVar(&localUnpushed, "include-unpushed", false, "With --bulk --force, also delete branches whose commits exist nowhere else")
+	localCmd.Flags().StringVar(&localForge, "forge", "", "Look up pull/merge requests on a forge to decide what to prune (github, gitlab, auto, none)")
 }
 
 func runLocalCleanup(cmd *cobra.Command, args []string) error {
@@ -49,31 +64,73 @@ func runLocalCleanup(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize repository
-	repo := git.NewRepository("")
+	repo := git.NewRepository(repoPath)
 
 	// Check if we're in a git repository
 	if err := repo.IsGitRepository(); err != nil {
 		return fmt.Errorf("not a git repository (or any of the parent directories)")
 	}
 
+	// Refuse to prune while a rebase, merge, cherry-pick or bisect is unfinished
+	op, err := checkInProgressOperation(repo, localDryRun)
+	if err != nil {
+		return err
+	}
+
+	// Protect branches listed in the config file and on the forge
+	cfg, err := loadConfig(repoPath)
+	if err != nil {
+		return err
+	}
+	basis, err := ageBasis(cfg, localBasis)
+	if err != nil {
+		return err
+	}
+	provider, err := newForgeProvider(repo, cfg, cfg.RemoteName, localForge)
+	if err != nil {
+		return err
+	}
+	if err := configureProtection(repo, cfg, provider, cfg.RemoteName); err != nil {
+		return err
+	}
+
+	startSession(cmd, args, repo, cfg, provider, cfg.RemoteName, ageThreshold, basis)
+
 	// Get all local branches
 	branches, err := repo.ListLocalBranches()
 	if err != nil {
 		return err
 	}
+	if err := repo.ApplyAgeBasis(branches, basis); err != nil {
+		return err
+	}
+
+	// Branches involved in an unfinished operation must never be pruned
+	protectInvolvedBranches(op, branches)
+
+	// Open pull requests protect branches, merged or closed ones qualify them
+	if err := attachPullRequests(provider, branches); err != nil {
+		return err
+	}
 
 	// Filter stale branches
-	staleBranches := filterStaleBranches(branches, ageThreshold)
+	staleBranches := prune.Candidates(branches, ageThreshold)
+
+	// Commits on no remote and no branch that stays would be lost with them
+	if err := repo.ApplyUnpushed(branches, staleBranches); err != nil {
+		return err
+	}
+	session.SetCandidates(fullNames(staleBranches))
 
 	if len(staleBranches) == 0 {
 		// Bonsai-themed success message
 		successStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7FB069")).
+			Foreground(theme.Colors().Leaf).
 			Bold(true)
 
 		successBox := lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#89DDFF")).
+			BorderForeground(theme.Colors().Highlight).
 			Padding(0, 1).
 			MarginTop(1).
 			MarginBottom(1)
@@ -82,53 +139,58 @@ func runLocalCleanup(cmd *cobra.Command, args []string) error {
 			"🌳 Your repository is perfectly maintained!",
 			"   No stale branches found - a true work of art.")
 
-		fmt.Println(successBox.Render(successStyle.Render(content)))
+		fmt.Fprintln(stdout, successBox.Render(successStyle.Render(content)))
 		return nil
 	}
 
+	// Without a terminal only dry runs and unattended runs can go on
+	if err := requireTerminal(localDryRun, localBulk); err != nil {
+		return err
+	}
+
 	// Show summary
-	printBranchSummary(staleBranches, "local", ageThreshold, localDryRun)
+	printBranchSummary(staleBranches, "local", ageThreshold, basis, localDryRun)
 
 	if localDryRun {
-		return nil
+		return errCandidates
 	}
 
-	if localBulk {
-		return runBulkDeletion(repo, staleBranches, false, localVerbose, localForce)
+	pruneOpts := prune.Options{
+		Force:      localForce,
+		Archive:    localArchive,
+		BundleDir:  localBundle,
+		SalvageDir: localSalvage,
+		Record:     recordPruned,
 	}
-
-	return ui.RunInteractiveSelection(repo, staleBranches, false, localVerbose, localForce)
-}
-
-func filterStaleBranches(branches []*git.Branch, threshold time.Duration) []*git.Branch {
-	var stale []*git.Branch
-
-	for _, branch := range branches {
-		// Skip current branch
-		if branch.IsCurrent {
-			continue
+	if pruneOpts.BundleDir != "" || pruneOpts.SalvageDir != "" {
+		if pruneOpts.Base, err = resolveBaseBranch(repo, cfg, cfg.RemoteName); err != nil {
+			return err
 		}
+	}
 
-		// Skip protected branches
-		if branch.IsProtected {
-			continue
+	if localBulk {
+		if localForce && !localUnpushed {
+			if staleBranches = withoutAtRisk(staleBranches); len(staleBranches) == 0 {
+				return nil
+			}
 		}
+		return runBulkDeletion(repo, staleBranches, false, localVerbose, pruneOpts)
+	}
 
-		// Check if stale
-		if branch.IsStale(threshold) {
-			stale = append(stale, branch)
-		}
+	// The detail pane compares branches against the base branch when one can be found
+	if pruneOpts.Base == "" {
+		pruneOpts.Base, _ = resolveBaseBranch(repo, cfg, cfg.RemoteName)
 	}
 
-	return stale
+	return ui.RunInteractiveSelection(repo, branches, ageThreshold, false, localVerbose, pruneOpts)
 }
 
-func printBranchSummary(branches []*git.Branch, branchType string, threshold time.Duration, dryRun bool) {
+func printBranchSummary(branches []*git.Branch, branchType string, threshold time.Duration, basis git.AgeBasis, dryRun bool) {
 	// Bonsai-themed colors
-	leafGreen := lipgloss.Color("#7FB069")
-	softCyan := lipgloss.Color("#89DDFF")
-	mutedGray := lipgloss.Color("#8F8F8F")
-	warningYellow := lipgloss.Color("#FFD43B")
+	leafGreen := theme.Colors().Leaf
+	softCyan := theme.Colors().Highlight
+	mutedGray := theme.Colors().Muted
+	warningYellow := theme.Colors().Caution
 
 	warningStyle := lipgloss.NewStyle().
 		Foreground(warningYellow).
@@ -149,7 +211,7 @@ func printBranchSummary(branches []*git.Branch, branchType string, threshold tim
 		title += " [PREVIEW MODE]"
 	}
 
-	info := fmt.Sprintf("Pruning threshold: %v", threshold)
+	info := fmt.Sprintf("Pruning threshold: %v since %s", threshold, basis.Describe())
 
 	// Style each line
 	titleStyle := lipgloss.NewStyle().
@@ -172,72 +234,148 @@ func printBranchSummary(branches []*git.Branch, branchType string, threshold tim
 		MarginTop(1).
 		MarginBottom(1)
 
-	fmt.Println(headerBox.Render(headerContent))
+	fmt.Fprintln(stdout, headerBox.Render(headerContent))
 
 	if !dryRun {
 		warning := "⚠️  These branches are ready for careful pruning"
-		fmt.Println(warningStyle.Render(warning))
-		fmt.Println()
+		fmt.Fprintln(stdout, warningStyle.Render(warning))
+		fmt.Fprintln(stdout)
 	} else {
+		// List what would be pruned and why
+		for _, branch := range branches {
+			fmt.Fprintln(stdout, describeBranch(branch))
+		}
+		fmt.Fprintln(stdout)
+
 		preview := "Preview mode: no changes will be made to your repository"
-		fmt.Println(infoStyle.Render(preview))
-		fmt.Println()
+		fmt.Fprintln(stdout, infoStyle.Render(preview))
+		fmt.Fprintln(stdout)
 	}
 }
 
-func runBulkDeletion(repo *git.Repository, branches []*git.Branch, isRemote bool, verbose bool, force bool) error {
+// describeBranch renders a one-line summary of a pruning candidate
+func describeBranch(branch *git.Branch) string {
+	nameStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Highlight).
+		Bold(true)
+
+	detailStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Muted).
+		Italic(true)
+
+	details := fmt.Sprintf("(%s) · %s", ui.FormatAge(branch.Age()), branch.LastAuthor)
+	if branch.PullRequest != nil {
+		details += " · " + branch.PullRequest.String()
+	}
+
+	line := fmt.Sprintf("  • %s %s", nameStyle.Render(branch.FullName()), detailStyle.Render(details))
+	if branch.IsAtRisk() {
+		line += " " + lipgloss.NewStyle().
+			Foreground(theme.Colors().Warning).
+			Bold(true).
+			Render(fmt.Sprintf("⚠️  %d unpushed", branch.Unpushed))
+	}
+	return line
+}
+
+// withoutAtRisk leaves branches with unpushed commits out of a forced bulk
+// deletion, telling the user which ones were kept
+func withoutAtRisk(branches []*git.Branch) []*git.Branch {
+	var result, kept []*git.Branch
+	for _, branch := range branches {
+		if branch.IsAtRisk() {
+			kept = append(kept, branch)
+		} else {
+			result = append(result, branch)
+		}
+	}
+	if len(kept) == 0 {
+		return result
+	}
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Caution).
+		Bold(true)
+	fmt.Fprintln(stdout, warningStyle.Render(fmt.Sprintf("⚠️  Keeping %d branch(es) with commits that exist nowhere else:", len(kept))))
+	for _, branch := range kept {
+		fmt.Fprintln(stdout, describeBranch(branch))
+	}
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Muted).
+		Italic(true)
+	fmt.Fprintln(stdout, hintStyle.Render("   Push them first, or pass --include-unpushed to delete them anyway"))
+	fmt.Fprintln(stdout)
+
+	return result
+}
+
+func runBulkDeletion(repo *git.Repository, branches []*git.Branch, isRemote bool, verbose bool, opts prune.Options) error {
 	// Confirm bulk deletion
-	if !confirmBulkDeletion(len(branches)) {
+	confirmed, err := confirmBulkDeletion(len(branches))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
 		cancelStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#8F8F8F")).
+			Foreground(theme.Colors().Muted).
 			Italic(true)
-		fmt.Println(cancelStyle.Render("🍃 Pruning cancelled. Your repository remains untouched."))
+		fmt.Fprintln(stdout, cancelStyle.Render("🍃 Pruning cancelled. Your repository remains untouched."))
 		return nil
 	}
 
-	successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#51CF66"))
-	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF6B6B"))
+	successStyle := lipgloss.NewStyle().Foreground(theme.Colors().Success)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Colors().Warning)
 
 	// Progress header
 	progressStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#7FB069")).
+		Foreground(theme.Colors().Leaf).
 		Bold(true)
-	fmt.Println(progressStyle.Render("🌀 Pruning in progress...\n"))
+	fmt.Fprintln(stdout, progressStyle.Render("🌀 Pruning in progress...\n"))
 
 	successCount := 0
 	errorCount := 0
 	var errorDetails []string
 
-	for _, branch := range branches {
-		var err error
-		if isRemote {
-			err = repo.DeleteRemoteBranch(branch.RemoteName, branch.Name)
-		} else {
-			err = repo.DeleteLocalBranch(branch.Name, force)
-		}
+	pruner := prune.New(repo, opts)
 
+	for _, branch := range branches {
+		result, err := pruner.Prune(branch)
 		if err != nil {
 			errorMsg := fmt.Sprintf("  ✗ Failed to prune %s", branch.FullName())
 			if verbose {
 				errorMsg = fmt.Sprintf("  ✗ Failed to prune %s: %v", branch.FullName(), err)
 			}
-			fmt.Println(errorStyle.Render(errorMsg))
+			fmt.Fprintln(stdout, errorStyle.Render(errorMsg))
 			errorDetails = append(errorDetails, fmt.Sprintf("%s: %v", branch.FullName(), err))
 			errorCount++
 		} else {
-			fmt.Println(successStyle.Render(fmt.Sprintf("  ✓ Pruned %s", branch.FullName())))
+			pruned := fmt.Sprintf("  ✓ Pruned %s", branch.FullName())
+			if result.ArchiveTag != "" {
+				pruned = fmt.Sprintf("  ✓ Archived %s as %s and pruned it", branch.FullName(), result.ArchiveTag)
+			}
+			if branch.PullRequest != nil {
+				pruned += fmt.Sprintf(" (%s)", branch.PullRequest)
+			}
+			if result.Bundle != "" {
+				pruned += fmt.Sprintf(" [bundle: %s]", filepath.Base(result.Bundle))
+			}
+			if result.Salvage != nil {
+				pruned += fmt.Sprintf(" [%d patch(es) salvaged to %s]", len(result.Salvage.Patches), result.Salvage.Dir)
+			}
+			fmt.Fprintln(stdout, successStyle.Render(pruned))
 			successCount++
 		}
 	}
 
 	// Summary box
 	summaryStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#7FB069")).
+		Foreground(theme.Colors().Leaf).
 		Bold(true)
 
 	summaryBox := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#89DDFF")).
+		BorderForeground(theme.Colors().Highlight).
 		Padding(0, 1).
 		MarginTop(1).
 		MarginBottom(1)
@@ -246,38 +384,38 @@ func runBulkDeletion(repo *git.Repository, branches []*git.Branch, isRemote bool
 		"🌳 Pruning complete!",
 		fmt.Sprintf("   %d branches removed, %d failed", successCount, errorCount))
 
-	fmt.Println(summaryBox.Render(summaryStyle.Render(content)))
+	fmt.Fprintln(stdout, summaryBox.Render(summaryStyle.Render(content)))
 
 	// Show detailed error summary if verbose and there were errors
 	if verbose && errorCount > 0 {
-		fmt.Println()
+		fmt.Fprintln(stdout)
 		debugStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF6B6B")).
+			Foreground(theme.Colors().Warning).
 			Bold(true)
-		fmt.Println(debugStyle.Render("Detailed Error Report:"))
-		fmt.Println()
+		fmt.Fprintln(stdout, debugStyle.Render("Detailed Error Report:"))
+		fmt.Fprintln(stdout)
 
 		detailStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#8F8F8F"))
+			Foreground(theme.Colors().Muted)
 
 		unmergedCount := 0
 		for i, detail := range errorDetails {
-			fmt.Println(detailStyle.Render(fmt.Sprintf("  %d. %s", i+1, detail)))
+			fmt.Fprintln(stdout, detailStyle.Render(fmt.Sprintf("  %d. %s", i+1, detail)))
 			if strings.Contains(detail, "not fully merged") {
 				unmergedCount++
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(stdout)
 
 		// Suggest using --force if branches aren't merged
-		if !force && unmergedCount > 0 {
+		if !opts.Force && unmergedCount > 0 {
 			hintStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFD43B")).
+				Foreground(theme.Colors().Caution).
 				Italic(true)
 
 			hintBox := lipgloss.NewStyle().
 				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("#89DDFF")).
+				BorderForeground(theme.Colors().Highlight).
 				Padding(0, 1).
 				MarginBottom(1)
 
@@ -286,39 +424,53 @@ func runBulkDeletion(repo *git.Repository, branches []*git.Branch, isRemote bool
 				"   To force delete unmerged branches, use the --force flag:",
 				"   bonsai local --bulk --force")
 
-			fmt.Println(hintBox.Render(hintStyle.Render(hint)))
+			fmt.Fprintln(stdout, hintBox.Render(hintStyle.Render(hint)))
 		}
 	}
 
-	return nil
+	return pruneOutcome(successCount, errorCount)
+}
+
+func confirmBulkDeletion(count int) (bool, error) {
+	return confirmAction(
+		fmt.Sprintf("⚠️  Ready to prune %d branch(es)", count),
+		"   This action cannot be undone.",
+		"Proceed with pruning? (y/N) ")
 }
 
-func confirmBulkDeletion(count int) bool {
+// confirmAction shows a warning box and asks the user for a yes/no answer.
+// With --yes the answer is yes; without a terminal to ask on it is an error.
+func confirmAction(title, detail, prompt string) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+	if !isTerminal() {
+		return false, fmt.Errorf("confirmation needs a terminal; pass --yes to proceed without it")
+	}
+
 	// Beautiful confirmation prompt with bonsai metaphor
 	warningStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFD43B")).
+		Foreground(theme.Colors().Caution).
 		Bold(true)
 
 	warningBox := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#FFD43B")).
+		BorderForeground(theme.Colors().Caution).
 		Padding(0, 1).
 		MarginTop(1).
 		MarginBottom(1)
 
 	promptStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#89DDFF")).
+		Foreground(theme.Colors().Highlight).
 		Italic(true)
 
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		fmt.Sprintf("⚠️  Ready to prune %d branch(es)", count),
-		"   This action cannot be undone.")
+	content := lipgloss.JoinVertical(lipgloss.Left, title, detail)
 
-	fmt.Println(warningBox.Render(warningStyle.Render(content)))
-	fmt.Print(promptStyle.Render("Proceed with pruning? (y/N) "))
+	fmt.Fprintln(stdout, warningBox.Render(warningStyle.Render(content)))
+	fmt.Fprint(stdout, promptStyle.Render(prompt))
 
 	var response string
 	_, _ = fmt.Scanln(&response) // Ignore error - empty input is valid (defaults to No)
 
-	return response == "y" || response == "Y" || response == "yes"
+	return response == "y" || response == "Y" || response == "yes", nil
 }
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/theme"
 )
 
 func main() {
@@ -13,32 +15,39 @@ func main() {
 		(len(os.Args) == 2 && (os.Args[1] == "--help" || os.Args[1] == "-h" || os.Args[1] == "help"))
 
 	if showBanner {
+		// Flags aren't parsed yet, but the config file's display settings apply
+		_ = setupTheme()
 		printBanner()
 	}
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	finishSession(err)
+
+	// Outcomes such as a dry run finding branches only set the exit code
+	var status *exitStatus
+	if err != nil && !(errors.As(err, &status) && status.err == nil) {
 		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF6B6B")).
+			Foreground(theme.Colors().Warning).
 			Bold(true)
-		fmt.Fprintf(os.Stderr, "\n%s\n", errorStyle.Render("✗ Error: "+err.Error()))
-		os.Exit(1)
+		fmt.Fprintf(stderr, "\n%s\n", errorStyle.Render("✗ Error: "+err.Error()))
 	}
+	os.Exit(exitCode(err))
 }
 
 func printBanner() {
 	bannerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#7FB069")).
+		Foreground(theme.Colors().Leaf).
 		Bold(true).
 		Align(lipgloss.Center).
 		MarginTop(1)
 
 	subtitleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#89DDFF")).
+		Foreground(theme.Colors().Highlight).
 		Italic(true).
 		Align(lipgloss.Center)
 
 	versionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#8F8F8F")).
+		Foreground(theme.Colors().Muted).
 		Align(lipgloss.Center).
 		MarginBottom(1)
 
@@ -53,7 +62,7 @@ func printBanner() {
 	subtitle := "The Art of Branch Pruning"
 	version := "v0.1.0 • Built with Charm 💜"
 
-	fmt.Println(bannerStyle.Render(banner))
-	fmt.Println(subtitleStyle.Render(subtitle))
-	fmt.Println(versionStyle.Render(version))
+	fmt.Fprintln(stdout, bannerStyle.Render(banner))
+	fmt.Fprintln(stdout, subtitleStyle.Render(subtitle))
+	fmt.Fprintln(stdout, versionStyle.Render(version))
 }
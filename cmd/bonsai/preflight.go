@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/theme"
+)
+
+// checkInProgressOperation refuses to prune while a rebase, merge, cherry-pick
+// or bisect is unfinished, since deleting branches could destroy the branch
+// the operation is based on. Dry runs are still allowed so users can preview.
+func checkInProgressOperation(repo *git.Repository, dryRun bool) (*git.Operation, error) {
+	op, err := repo.InProgressOperation()
+	if err != nil {
+		return nil, err
+	}
+
+	if op != nil && !dryRun {
+		involved := ""
+		if len(op.Branches) > 0 {
+			involved = fmt.Sprintf(" (involving %s)", strings.Join(op.Branches, ", "))
+		}
+		return nil, fmt.Errorf("a %s is in progress%s; finish or abort it before pruning, or use --dry-run to preview", op.Name, involved)
+	}
+
+	return op, nil
+}
+
+// protectInvolvedBranches marks branches taking part in an in-progress
+// operation as protected and tells the user about it
+func protectInvolvedBranches(op *git.Operation, branches []*git.Branch) {
+	if op == nil {
+		return
+	}
+
+	var protected []string
+	for _, branch := range branches {
+		if op.Involves(branch) {
+			branch.IsProtected = true
+			protected = append(protected, branch.FullName())
+		}
+	}
+
+	noticeStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Caution).
+		Bold(true)
+
+	noticeBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Colors().Caution).
+		Padding(0, 1).
+		MarginTop(1)
+
+	lines := []string{fmt.Sprintf("⚠️  A %s is in progress - pruning is disabled until it finishes", op.Name)}
+	for _, name := range protected {
+		lines = append(lines, fmt.Sprintf("   🛡️  %s (protected)", name))
+	}
+
+	fmt.Fprintln(stdout, noticeBox.Render(noticeStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))))
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var protectCmd = &cobra.Command{
+	Use:   "protect <branch|pattern>",
+	Short: "🛡️  Keep a branch from ever being pruned",
+	Long: `🛡️  Keep a branch from ever being pruned
+
+Add a branch name or a glob pattern such as release/* to protected_branches in
+the repository's .bonsai.yaml, creating the file if there is none.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProtect,
+}
+
+func init() {
+	rootCmd.AddCommand(protectCmd)
+}
+
+func runProtect(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	path, err := config.AddProtectedBranch(repo.Path, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to protect %s: %w", args[0], err)
+	}
+
+	printSummaryBox(fmt.Sprintf("🛡️  Protected %s", args[0]),
+		fmt.Sprintf("   Added to protected_branches in %s", path))
+	return nil
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/history"
+	"github.com/kriscoleman/bonsai/internal/quarantine"
+	"github.com/kriscoleman/bonsai/internal/theme"
+	"github.com/kriscoleman/bonsai/internal/ui"
+)
+
+// loadQuarantine opens the quarantine records kept in the repository's state directory
+func loadQuarantine(repo *git.Repository) (*quarantine.Store, error) {
+	stateDir, err := repo.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	return quarantine.Load(filepath.Join(stateDir, "quarantine.json"))
+}
+
+// withoutQuarantined drops branches living in the quarantine namespace, which
+// are only ever removed by --reap once their grace period has passed
+func withoutQuarantined(branches []*git.Branch) []*git.Branch {
+	var result []*git.Branch
+	for _, branch := range branches {
+		if !quarantine.IsQuarantined(branch.Name) {
+			result = append(result, branch)
+		}
+	}
+	return result
+}
+
+// withoutRescued leaves out the branches rescued from quarantine less than
+// grace ago
+func withoutRescued(branches []*git.Branch, store *quarantine.Store, remote string, grace time.Duration) []*git.Branch {
+	var result []*git.Branch
+	for _, branch := range branches {
+		if !store.RecentlyRescued(remote, branch.Name, grace) {
+			result = append(result, branch)
+		}
+	}
+	return result
+}
+
+// releaseRescued marks the branches whose owners pushed them back under their
+// original name as rescued, which keeps them out of quarantine for a grace
+// period. The quarantined copy is removed as well when the rescued branch
+// still contains its commits.
+func releaseRescued(repo *git.Repository, store *quarantine.Store, remote string, grace time.Duration, verbose bool) error {
+	rescuedStyle := lipgloss.NewStyle().Foreground(theme.Colors().Success)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Colors().Warning)
+
+	store.ExpireRescues(grace)
+
+	for _, entry := range store.ForRemote(remote) {
+		original := fmt.Sprintf("refs/remotes/%s/%s", remote, entry.Branch)
+		if _, err := repo.ResolveCommit(original); err != nil {
+			// Still in quarantine
+			continue
+		}
+
+		store.MarkRescued(remote, entry.Branch, time.Now())
+		fmt.Fprintln(stdout, rescuedStyle.Render(fmt.Sprintf("  🌱 %s/%s was rescued by its owner", remote, entry.Branch)))
+
+		quarantined := fmt.Sprintf("refs/remotes/%s/%s", remote, entry.QuarantinedName())
+		if _, err := repo.ResolveCommit(quarantined); err != nil || !repo.IsAncestor(entry.Commit, original) {
+			continue
+		}
+
+		if err := repo.DeleteRemoteBranchAt(remote, entry.QuarantinedName(), entry.Commit); err != nil {
+			errorMsg := fmt.Sprintf("  ✗ Failed to remove %s/%s", remote, entry.QuarantinedName())
+			if verbose {
+				errorMsg += ": " + err.Error()
+			}
+			fmt.Fprintln(stdout, errorStyle.Render(errorMsg))
+		}
+	}
+
+	return store.Save()
+}
+
+// runQuarantine renames stale remote branches into the quarantine namespace
+// and records when that happened
+func runQuarantine(repo *git.Repository, branches []*git.Branch, remote string, verbose bool) error {
+	confirmed, err := confirmAction(
+		fmt.Sprintf("⚠️  Ready to quarantine %d branch(es)", len(branches)),
+		fmt.Sprintf("   They move to %s<name>; owners can rescue them by pushing them back.", quarantine.Namespace),
+		"Proceed with quarantine? (y/N) ")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		cancelStyle := lipgloss.NewStyle().
+			Foreground(theme.Colors().Muted).
+			Italic(true)
+		fmt.Fprintln(stdout, cancelStyle.Render("🍃 Quarantine cancelled. Your repository remains untouched."))
+		return nil
+	}
+
+	store, err := loadQuarantine(repo)
+	if err != nil {
+		return err
+	}
+
+	successStyle := lipgloss.NewStyle().Foreground(theme.Colors().Success)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Colors().Warning)
+
+	successCount := 0
+	errorCount := 0
+
+	for _, branch := range branches {
+		newName := quarantine.Namespace + branch.Name
+
+		commit, err := repo.ResolveCommit(fmt.Sprintf("refs/remotes/%s/%s", remote, branch.Name))
+		if err == nil {
+			err = repo.RenameRemoteBranch(remote, branch.Name, newName)
+		}
+
+		session.Record(history.Result{
+			Branch: branch.FullName(),
+			Action: history.ActionQuarantined,
+			Detail: remote + "/" + newName,
+			Error:  errorText(err),
+		})
+
+		if err != nil {
+			errorMsg := fmt.Sprintf("  ✗ Failed to quarantine %s", branch.FullName())
+			if verbose {
+				errorMsg += ": " + err.Error()
+			}
+			fmt.Fprintln(stdout, errorStyle.Render(errorMsg))
+			errorCount++
+			continue
+		}
+
+		store.Add(quarantine.Entry{
+			Remote:        remote,
+			Branch:        branch.Name,
+			Commit:        commit,
+			QuarantinedAt: time.Now(),
+		})
+		fmt.Fprintln(stdout, successStyle.Render(fmt.Sprintf("  ✓ Quarantined %s → %s/%s", branch.FullName(), remote, newName)))
+		successCount++
+	}
+
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	printSummaryBox("🌳 Quarantine complete!",
+		fmt.Sprintf("   %d branches quarantined, %d failed", successCount, errorCount),
+		"   Reap them later with: bonsai remote --reap")
+
+	return pruneOutcome(successCount, errorCount)
+}
+
+// runReap deletes quarantined branches whose grace period has passed
+func runReap(repo *git.Repository, remote string, grace time.Duration, dryRun bool, verbose bool) error {
+	store, err := loadQuarantine(repo)
+	if err != nil {
+		return err
+	}
+
+	// Fresh remote-tracking branches are needed to notice rescued branches
+	if !dryRun {
+		if err := repo.Fetch(remote); err != nil {
+			return err
+		}
+		if err := releaseRescued(repo, store, remote, grace, verbose); err != nil {
+			return err
+		}
+	}
+
+	var due []quarantine.Entry
+	for _, entry := range store.ForRemote(remote) {
+		quarantined := fmt.Sprintf("refs/remotes/%s/%s", remote, entry.QuarantinedName())
+		if _, err := repo.ResolveCommit(quarantined); err != nil {
+			// Someone already removed the quarantined copy
+			if !dryRun {
+				store.Remove(remote, entry.Branch)
+			}
+			continue
+		}
+
+		if entry.Age() > grace {
+			due = append(due, entry)
+		}
+	}
+
+	var dueNames []string
+	for _, entry := range due {
+		dueNames = append(dueNames, remote+"/"+entry.QuarantinedName())
+	}
+	session.SetCandidates(dueNames)
+
+	if len(due) == 0 {
+		if !dryRun {
+			if err := store.Save(); err != nil {
+				return err
+			}
+		}
+		printSummaryBox(
+			fmt.Sprintf("🌳 Nothing to reap on %s", remote),
+			fmt.Sprintf("   No quarantined branch has outlived the %v grace period.", grace))
+		return nil
+	}
+
+	nameStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Highlight).
+		Bold(true)
+	detailStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Muted).
+		Italic(true)
+
+	fmt.Fprintln(stdout)
+	for _, entry := range due {
+		fmt.Fprintf(stdout, "  • %s %s\n",
+			nameStyle.Render(remote+"/"+entry.QuarantinedName()),
+			detailStyle.Render("(quarantined "+ui.FormatAge(entry.Age())+")"))
+	}
+
+	if dryRun {
+		infoStyle := lipgloss.NewStyle().
+			Foreground(theme.Colors().Muted).
+			Italic(true)
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, infoStyle.Render("Preview mode: no changes will be made to your repository"))
+		return errCandidates
+	}
+
+	confirmed, err := confirmBulkDeletion(len(due))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		cancelStyle := lipgloss.NewStyle().
+			Foreground(theme.Colors().Muted).
+			Italic(true)
+		fmt.Fprintln(stdout, cancelStyle.Render("🍃 Reaping cancelled. Your repository remains untouched."))
+		return store.Save()
+	}
+
+	successStyle := lipgloss.NewStyle().Foreground(theme.Colors().Success)
+	errorStyle := lipgloss.NewStyle().Foreground(theme.Colors().Warning)
+
+	successCount := 0
+	errorCount := 0
+
+	for _, entry := range due {
+		fullName := remote + "/" + entry.QuarantinedName()
+		err := repo.DeleteRemoteBranchAt(remote, entry.QuarantinedName(), entry.Commit)
+		session.Record(history.Result{Branch: fullName, Action: history.ActionReaped, Error: errorText(err)})
+		if err != nil {
+			errorMsg := fmt.Sprintf("  ✗ Failed to reap %s", fullName)
+			if verbose {
+				errorMsg += ": " + err.Error()
+			}
+			fmt.Fprintln(stdout, errorStyle.Render(errorMsg))
+			errorCount++
+			continue
+		}
+
+		store.Remove(remote, entry.Branch)
+		fmt.Fprintln(stdout, successStyle.Render(fmt.Sprintf("  ✓ Reaped %s", fullName)))
+		successCount++
+	}
+
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	printSummaryBox("🌳 Reaping complete!",
+		fmt.Sprintf("   %d branches removed, %d failed", successCount, errorCount))
+
+	return pruneOutcome(successCount, errorCount)
+}
+
+// printSummaryBox renders the bordered summary shown at the end of a session
+func printSummaryBox(lines ...string) {
+	summaryStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Leaf).
+		Bold(true)
+
+	summaryBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Colors().Highlight).
+		Padding(0, 1).
+		MarginTop(1).
+		MarginBottom(1)
+
+	fmt.Fprintln(stdout, summaryBox.Render(summaryStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))))
+}
@@ -6,17 +6,28 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kriscoleman/bonsai/internal/config"
 	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+	"github.com/kriscoleman/bonsai/internal/quarantine"
+	"github.com/kriscoleman/bonsai/internal/theme"
 	"github.com/kriscoleman/bonsai/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	remoteBulk    bool
-	remoteAge     string
-	remoteDryRun  bool
-	remoteName    string
-	remoteVerbose bool
-	remoteForce   bool
+	remoteBulk       bool
+	remoteAge        string
+	remoteDryRun     bool
+	remoteName       string
+	remoteVerbose    bool
+	remoteForce      bool
+	remoteForge      string
+	remoteQuarantine bool
+	remoteReap       bool
+	remoteGrace      string
+	remoteArchive    bool
+	remoteBundle     string
+	remoteSalvage    string
+	remoteBasis      string
 )
 
 var remoteCmd = &cobra.Command{
@@ -39,9 +50,24 @@ func init() {
 	remoteCmd.Flags().StringVar(&remoteName, "remote", "origin", "Remote name to clean up")
 	remoteCmd.Flags().BoolVarP(&remoteVerbose, "verbose", "v", false, "Show detailed error messages")
 	remoteCmd.Flags().BoolVarP(&remoteForce, "force", "f", false, "Force delete branches even if not fully merged")
+	remoteCmd.Flags().StringVar(&remoteBasis, "age-basis", "", "What branch ages count from: committer, author, last-checkout or created (defaults to committer)")
+	remoteCmd.Flags().StringVar(&remoteForge, "forge", "", "Look up pull/merge requests on a forge to decide what to prune (github, gitlab, auto, none)")
+	remoteCmd.Flags().BoolVar(&remoteArchive, "archive", false, "Keep each branch as an archive/<branch> tag (pushed to the remote) before deleting it")
+	remoteCmd.Flags().StringVar(&remoteBundle, "bundle-dir", "", "Write a git bundle of each branch's unique commits to this directory before deleting it")
+	remoteCmd.Flags().StringVar(&remoteSalvage, "salvage-dir", "", "Save each unmerged branch's commits as format-patch files under this directory before deleting it")
+	remoteCmd.Flags().BoolVar(&remoteQuarantine, "quarantine", false, "Rename stale branches to stale/<name> instead of deleting them")
+	remoteCmd.Flags().BoolVar(&remoteReap, "reap", false, "Delete quarantined branches whose grace period has passed")
+	remoteCmd.Flags().StringVar(&remoteGrace, "grace", "2w", "How long quarantined branches are kept before --reap deletes them, and rescued ones are spared by --quarantine")
 }
 
 func runRemoteCleanup(cmd *cobra.Command, args []string) error {
+	if remoteQuarantine && remoteReap {
+		return fmt.Errorf("--quarantine and --reap cannot be combined")
+	}
+	if remoteArchive && (remoteQuarantine || remoteReap) {
+		return fmt.Errorf("--archive cannot be combined with --quarantine or --reap")
+	}
+
 	// Parse age threshold
 	ageThreshold, err := config.ParseDuration(remoteAge)
 	if err != nil {
@@ -49,31 +75,104 @@ func runRemoteCleanup(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize repository
-	repo := git.NewRepository("")
+	repo := git.NewRepository(repoPath)
 
 	// Check if we're in a git repository
 	if err := repo.IsGitRepository(); err != nil {
 		return fmt.Errorf("not a git repository (or any of the parent directories)")
 	}
 
-	// Get all remote branches
+	// Refuse to prune while a rebase, merge, cherry-pick or bisect is unfinished
+	op, err := checkInProgressOperation(repo, remoteDryRun)
+	if err != nil {
+		return err
+	}
+
+	// Protect branches listed in the config file and on the forge
+	cfg, err := loadConfig(repoPath)
+	if err != nil {
+		return err
+	}
+	basis, err := ageBasis(cfg, remoteBasis)
+	if err != nil {
+		return err
+	}
+	provider, err := newForgeProvider(repo, cfg, remoteName, remoteForge)
+	if err != nil {
+		return err
+	}
+	if err := configureProtection(repo, cfg, provider, remoteName); err != nil {
+		return err
+	}
+
+	startSession(cmd, args, repo, cfg, provider, remoteName, ageThreshold, basis)
+
+	grace, err := config.ParseDuration(remoteGrace)
+	if err != nil {
+		return fmt.Errorf("invalid grace period: %w", err)
+	}
+
+	// Reaping only looks at previously quarantined branches
+	if remoteReap {
+		return runReap(repo, remoteName, grace, remoteDryRun, remoteVerbose)
+	}
+
+	var store *quarantine.Store
+	if remoteQuarantine {
+		if store, err = loadQuarantine(repo); err != nil {
+			return err
+		}
+	}
+
+	// Quarantine needs fresh remote-tracking branches to notice rescued branches
+	if remoteQuarantine && !remoteDryRun {
+		if err := repo.Fetch(remoteName); err != nil {
+			return err
+		}
+		if err := releaseRescued(repo, store, remoteName, grace, remoteVerbose); err != nil {
+			return err
+		}
+	}
+
+	// Pins and snoozes shared by others keep their branches out of pruning
+	syncHolds(repo, remoteName)
+
+	// Get all remote branches, leaving quarantined ones to --reap
 	branches, err := repo.ListRemoteBranches(remoteName)
 	if err != nil {
 		return err
 	}
+	branches = withoutQuarantined(branches)
+	if err := repo.ApplyAgeBasis(branches, basis); err != nil {
+		return err
+	}
+
+	// Branches involved in an unfinished operation must never be pruned
+	protectInvolvedBranches(op, branches)
+
+	// Open pull requests protect branches, merged or closed ones qualify them
+	if err := attachPullRequests(provider, branches); err != nil {
+		return err
+	}
 
 	// Filter stale branches
-	staleBranches := filterStaleBranches(branches, ageThreshold)
+	staleBranches := prune.Candidates(branches, ageThreshold)
+
+	// Rescued branches keep their old tips; give their owners a grace period
+	if remoteQuarantine {
+		staleBranches = withoutRescued(staleBranches, store, remoteName, grace)
+	}
+	session.SetCandidates(fullNames(staleBranches))
 
 	if len(staleBranches) == 0 {
 		// Bonsai-themed success message
 		successStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7FB069")).
+			Foreground(theme.Colors().Leaf).
 			Bold(true)
 
 		successBox := lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#89DDFF")).
+			BorderForeground(theme.Colors().Highlight).
 			Padding(0, 1).
 			MarginTop(1).
 			MarginBottom(1)
@@ -82,20 +181,47 @@ func runRemoteCleanup(cmd *cobra.Command, args []string) error {
 			fmt.Sprintf("🌳 Your %s remote is perfectly maintained!", remoteName),
 			"   No stale branches found - a true work of art.")
 
-		fmt.Println(successBox.Render(successStyle.Render(content)))
+		fmt.Fprintln(stdout, successBox.Render(successStyle.Render(content)))
 		return nil
 	}
 
+	// Without a terminal only dry runs and unattended runs can go on
+	if err := requireTerminal(remoteDryRun, remoteBulk || remoteQuarantine); err != nil {
+		return err
+	}
+
 	// Show summary
-	printBranchSummary(staleBranches, "remote", ageThreshold, remoteDryRun)
+	printBranchSummary(staleBranches, "remote", ageThreshold, basis, remoteDryRun)
 
 	if remoteDryRun {
-		return nil
+		return errCandidates
+	}
+
+	if remoteQuarantine {
+		return runQuarantine(repo, staleBranches, remoteName, remoteVerbose)
+	}
+
+	pruneOpts := prune.Options{
+		Force:      remoteForce,
+		Archive:    remoteArchive,
+		BundleDir:  remoteBundle,
+		SalvageDir: remoteSalvage,
+		Record:     recordPruned,
+	}
+	if pruneOpts.BundleDir != "" || pruneOpts.SalvageDir != "" {
+		if pruneOpts.Base, err = resolveBaseBranch(repo, cfg, remoteName); err != nil {
+			return err
+		}
 	}
 
 	if remoteBulk {
-		return runBulkDeletion(repo, staleBranches, true, remoteVerbose, remoteForce)
+		return runBulkDeletion(repo, staleBranches, true, remoteVerbose, pruneOpts)
+	}
+
+	// The detail pane compares branches against the base branch when one can be found
+	if pruneOpts.Base == "" {
+		pruneOpts.Base, _ = resolveBaseBranch(repo, cfg, remoteName)
 	}
 
-	return ui.RunInteractiveSelection(repo, staleBranches, true, remoteVerbose, remoteForce)
+	return ui.RunInteractiveSelection(repo, branches, ageThreshold, true, remoteVerbose, pruneOpts)
 }
@@ -2,18 +2,16 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/theme"
 	"github.com/spf13/cobra"
 )
 
 var (
-	// Color palette inspired by bonsai aesthetics
-	leafGreen    = lipgloss.Color("#7FB069") // Fresh leaf green
-	accentPurple = lipgloss.Color("#C792EA") // Charm purple accent
-	softCyan     = lipgloss.Color("#89DDFF") // Soft cyan highlight
-	mutedGray    = lipgloss.Color("#8F8F8F") // Elegant gray
-
 	// Bonsai ASCII art
 	bonsaiArt = `
            ,.,
@@ -32,42 +30,131 @@ MMMMMM"___ "_._   MMM"_."" _ """"""
 `
 )
 
+// repoPath is the repository every command operates on; empty means the current directory
+var repoPath string
+
+// assumeYes answers every confirmation with yes, for unattended runs
+var assumeYes bool
+
+// Display flags; when they are not given the config file decides
+var (
+	themeName string
+	asciiMode bool
+)
+
+// stdout and stderr are where commands write, swapping emoji for plain
+// glyphs in ASCII mode
+var (
+	stdout = theme.Writer(os.Stdout)
+	stderr = theme.Writer(os.Stderr)
+)
+
 var rootCmd = &cobra.Command{
 	Use:     "bonsai",
 	Short:   "🌳 The Art of Branch Pruning",
 	Long:    renderLongDescription(),
 	Version: "0.1.0",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// The flags were fine, so errors from here on don't call for the usage
+		cmd.SilenceUsage = true
+		return setupTheme()
+	},
+	// main reports errors itself
+	SilenceErrors: true,
 }
 
 func init() {
 	// Set custom templates for help and usage
 	cobra.AddTemplateFunc("StyleHeading", styleHeading)
 	rootCmd.SetUsageTemplate(getUsageTemplate())
+
+	rootCmd.PersistentFlags().StringVar(&repoPath, "repo", "", "Path of the repository to work on (defaults to the current directory)")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "", "Color theme: dark, light or high-contrast (defaults to the config's theme)")
+	rootCmd.PersistentFlags().BoolVar(&asciiMode, "ascii", false, "Use plain glyphs instead of emoji")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to confirmations, e.g. to prune with --bulk unattended")
+
+	rootCmd.SetOut(stdout)
+	rootCmd.SetErr(stderr)
+
+	// Help doesn't run the pre-run hooks, so it sets the theme up itself and
+	// renders the description again in it
+	help := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if err := setupTheme(); err != nil {
+			fmt.Fprintf(stderr, "⚠️  %v\n", err)
+		}
+		rootCmd.Long = renderLongDescription()
+		help(cmd, args)
+	})
+}
+
+// setupTheme applies the display flags, falling back to the config file.
+// A config file that can't be loaded only costs its display settings here;
+// the command itself reports it.
+func setupTheme() error {
+	cfg, err := loadConfig(repoPath)
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	opts := theme.Options{Palette: cfg.Theme, ASCII: cfg.ASCII || asciiMode}
+	if themeName != "" {
+		opts.Palette = themeName
+	}
+	return theme.Setup(opts)
+}
+
+// openRepository opens the repository selected with --repo
+func openRepository() (*git.Repository, error) {
+	repo := git.NewRepository(repoPath)
+	if err := repo.IsGitRepository(); err != nil {
+		return nil, fmt.Errorf("not a git repository (or any of the parent directories)")
+	}
+	return repo, nil
+}
+
+// ageBasis returns what branch ages count from: the --age-basis flag's
+// choice, falling back to the config file
+func ageBasis(cfg *config.Config, flag string) (git.AgeBasis, error) {
+	return git.ParseAgeBasis(firstNonEmpty(flag, cfg.AgeBasis))
+}
+
+// loadConfig layers the config files that apply to the repository in dir.
+// A file that can't be loaded is an error rather than a silent fallback to
+// the defaults, which would drop its protected branches.
+func loadConfig(dir string) (*config.Config, error) {
+	cfg, err := config.LoadConfigFrom(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
 }
 
 func renderLongDescription() string {
+	palette := theme.Colors()
+
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(leafGreen).
+		Foreground(palette.Leaf).
 		MarginBottom(1)
 
 	subtitleStyle := lipgloss.NewStyle().
 		Italic(true).
-		Foreground(mutedGray)
+		Foreground(palette.Muted)
 
 	descStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252"))
+		Foreground(palette.Text)
 
 	quoteStyle := lipgloss.NewStyle().
 		Italic(true).
-		Foreground(accentPurple).
+		Foreground(palette.Accent).
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(softCyan).
+		BorderForeground(palette.Highlight).
 		Padding(0, 1).
 		MarginTop(1)
 
 	artStyle := lipgloss.NewStyle().
-		Foreground(leafGreen).
+		Foreground(palette.Leaf).
 		Align(lipgloss.Center)
 
 	return fmt.Sprintf(`%s
@@ -90,7 +177,7 @@ func renderLongDescription() string {
 func styleHeading(s string) string {
 	return lipgloss.NewStyle().
 		Bold(true).
-		Foreground(leafGreen).
+		Foreground(theme.Colors().Leaf).
 		Render(s)
 }
 
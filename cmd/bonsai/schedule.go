@@ -0,0 +1,401 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/forge"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+	"github.com/kriscoleman/bonsai/internal/schedule"
+	"github.com/kriscoleman/bonsai/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleEvery     string
+	scheduleBackend   string
+	schedulePrintOnly bool
+	scheduleReportDir string
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "⏰ Prune on a schedule with systemd timers or cron",
+	Long: `⏰ Prune on a schedule with systemd timers or cron
+
+Install a systemd user timer (or a crontab line) that runs "bonsai schedule run"
+over the repositories listed under schedule.repositories in your config, or
+the one given with --repo. Scheduled runs never ask questions: they follow the
+schedule policy in your config and write a report of what they found.
+
+Nothing is deleted unless the policy sets schedule.unattended to true; until
+then scheduled runs only report the branches they would prune.`,
+}
+
+var scheduleInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the systemd timer or crontab entry",
+	Args:  cobra.NoArgs,
+	RunE:  runScheduleInstall,
+}
+
+var scheduleUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the systemd timer and crontab entry",
+	Args:  cobra.NoArgs,
+	RunE:  runScheduleUninstall,
+}
+
+var scheduleRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the scheduled pruning policy now",
+	Args:  cobra.NoArgs,
+	RunE:  runScheduleRun,
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleInstallCmd)
+	scheduleCmd.AddCommand(scheduleUninstallCmd)
+	scheduleCmd.AddCommand(scheduleRunCmd)
+
+	scheduleInstallCmd.Flags().StringVar(&scheduleEvery, "every", "1w", "How often to run (e.g., 1d, 1w, 1M)")
+	scheduleInstallCmd.Flags().StringVar(&scheduleBackend, "backend", "auto", "Scheduler to use (auto, systemd, cron)")
+	scheduleInstallCmd.Flags().BoolVar(&schedulePrintOnly, "print", false, "Print the generated units or crontab line instead of installing them")
+	scheduleUninstallCmd.Flags().StringVar(&scheduleBackend, "backend", "auto", "Scheduler to remove from (auto, systemd, cron)")
+	scheduleRunCmd.Flags().StringVar(&scheduleReportDir, "report-dir", "", "Directory to write the report to (default: schedule.report_dir or ~/.local/state/bonsai/reports)")
+}
+
+// scheduleBackendName resolves "auto" to the scheduler available on this machine
+func scheduleBackendName() (string, error) {
+	switch scheduleBackend {
+	case "systemd", "cron":
+		return scheduleBackend, nil
+	case "auto":
+		if schedule.SystemdAvailable() {
+			return "systemd", nil
+		}
+		return "cron", nil
+	default:
+		return "", fmt.Errorf("unsupported scheduler: %s (use auto, systemd or cron)", scheduleBackend)
+	}
+}
+
+func runScheduleInstall(cmd *cobra.Command, args []string) error {
+	every, err := config.ParseDuration(scheduleEvery)
+	if err != nil {
+		return fmt.Errorf("invalid interval: %w", err)
+	}
+	if every < time.Minute {
+		return fmt.Errorf("interval must be at least a minute")
+	}
+
+	backend, err := scheduleBackendName()
+	if err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the bonsai executable: %w", err)
+	}
+
+	// Scheduled runs start in the home directory, so pin the repository down
+	command := []string{executable}
+	cfg, err := loadConfig(repoPath)
+	if err != nil {
+		return err
+	}
+	if repoPath != "" {
+		abs, err := filepath.Abs(repoPath)
+		if err != nil {
+			return err
+		}
+		command = append(command, "--repo", abs)
+	} else if len(cfg.Schedule.Repositories) == 0 {
+		return fmt.Errorf("no repositories to prune: pass --repo or list them under schedule.repositories in your config")
+	}
+	command = append(command, "schedule", "run")
+
+	spec := schedule.Spec{Every: every, Command: command}
+
+	if schedulePrintOnly {
+		if backend == "cron" {
+			line, err := schedule.CronLine(spec)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(stdout, line)
+			return nil
+		}
+		fmt.Fprintf(stdout, "# %s.service\n%s\n# %s.timer\n%s", schedule.UnitName, schedule.SystemdService(spec), schedule.UnitName, schedule.SystemdTimer(spec))
+		return nil
+	}
+
+	var installed string
+	if backend == "cron" {
+		line, err := schedule.InstallCron(spec)
+		if err != nil {
+			return err
+		}
+		installed = "crontab: " + line
+	} else {
+		timer, err := schedule.InstallSystemd(spec)
+		if err != nil {
+			return err
+		}
+		installed = "systemd timer: " + timer
+	}
+
+	lines := []string{
+		fmt.Sprintf("⏰ bonsai will run every %s", schedule.Describe(every)),
+		"   " + installed,
+	}
+	if !cfg.Schedule.Unattended {
+		lines = append(lines,
+			"   Report only: scheduled runs won't delete anything until",
+			"   schedule.unattended is set to true in your config.")
+	}
+	printSummaryBox(lines...)
+
+	return nil
+}
+
+func runScheduleUninstall(cmd *cobra.Command, args []string) error {
+	removed := false
+
+	// With auto, clean up whichever scheduler has an entry
+	switch scheduleBackend {
+	case "auto":
+		if schedule.SystemdAvailable() {
+			ok, err := schedule.UninstallSystemd()
+			if err != nil {
+				return err
+			}
+			removed = ok
+		}
+		if ok, err := schedule.UninstallCron(); err == nil && ok {
+			removed = true
+		}
+	case "systemd":
+		ok, err := schedule.UninstallSystemd()
+		if err != nil {
+			return err
+		}
+		removed = ok
+	case "cron":
+		ok, err := schedule.UninstallCron()
+		if err != nil {
+			return err
+		}
+		removed = ok
+	default:
+		return fmt.Errorf("unsupported scheduler: %s (use auto, systemd or cron)", scheduleBackend)
+	}
+
+	if !removed {
+		printSummaryBox("⏰ No bonsai schedule was installed")
+		return nil
+	}
+	printSummaryBox("⏰ bonsai schedule removed")
+	return nil
+}
+
+func runScheduleRun(cmd *cobra.Command, args []string) error {
+	userConfig, err := loadConfig("")
+	if err != nil {
+		return err
+	}
+
+	repositories := userConfig.Schedule.Repositories
+	if repoPath != "" {
+		repositories = []string{repoPath}
+	}
+	if len(repositories) == 0 {
+		return fmt.Errorf("no repositories to prune: pass --repo or list them under schedule.repositories in your config")
+	}
+
+	report := &schedule.Report{StartedAt: time.Now()}
+	for _, path := range repositories {
+		report.Repositories = append(report.Repositories, runScheduledRepository(cmd, args, path))
+	}
+	report.Duration = time.Since(report.StartedAt)
+
+	reportDir := scheduleReportDir
+	if reportDir == "" {
+		reportDir = userConfig.Schedule.ReportDir
+	}
+	if reportDir == "" {
+		dir, err := schedule.DefaultReportDir()
+		if err != nil {
+			return err
+		}
+		reportDir = dir
+	}
+
+	if err := report.Write(stdout); err != nil {
+		return err
+	}
+	reportPath, err := report.Save(reportDir)
+	if err != nil {
+		return err
+	}
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors().Muted).
+		Italic(true)
+	fmt.Fprintln(stdout)
+	fmt.Fprintln(stdout, infoStyle.Render("Report written to "+reportPath))
+
+	if failures := report.Failures(); failures > 0 {
+		return fmt.Errorf("%d of %d repositories had errors", failures, len(report.Repositories))
+	}
+	return nil
+}
+
+// runScheduledRepository applies the schedule policy to one repository
+func runScheduledRepository(cmd *cobra.Command, args []string, path string) *schedule.RepositoryReport {
+	result := &schedule.RepositoryReport{Path: path}
+
+	repo := git.NewRepository(path)
+	if err := repo.IsGitRepository(); err != nil {
+		result.Error = "not a git repository"
+		return result
+	}
+
+	// Unattended deletion must never rely on silently ignored settings
+	cfg, err := config.LoadConfigFrom(path)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	policy := cfg.Schedule
+	result.Unattended = policy.Unattended
+
+	if op, err := repo.InProgressOperation(); err != nil {
+		result.Error = err.Error()
+		return result
+	} else if op != nil {
+		result.Skipped = fmt.Sprintf("a %s is in progress", op.Name)
+		return result
+	}
+
+	basis, err := ageBasis(cfg, "")
+	var provider forge.Provider
+	if err == nil {
+		provider, err = newForgeProvider(repo, cfg, cfg.RemoteName, "")
+	}
+	if err == nil {
+		err = configureProtection(repo, cfg, provider, cfg.RemoteName)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// Each repository's run goes into its own history
+	startSession(cmd, args, repo, cfg, provider, cfg.RemoteName, cfg.LocalAgeThreshold, basis)
+	defer func() {
+		finishSession(scheduledOutcome(result))
+		session = nil
+	}()
+
+	local, err := repo.ListLocalBranches()
+	if err == nil {
+		err = repo.ApplyAgeBasis(local, basis)
+	}
+	if err == nil {
+		err = attachPullRequests(provider, local)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	candidates := prune.Candidates(local, cfg.LocalAgeThreshold)
+
+	// Forced deletion must not take commits that exist nowhere else
+	if policy.Force {
+		if err := repo.ApplyUnpushed(local, candidates); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	if policy.Remote {
+		// Deleting remote branches based on stale remote-tracking refs could
+		// remove work pushed since the last fetch
+		var branches []*git.Branch
+		err := repo.Fetch(cfg.RemoteName)
+		if err == nil {
+			branches, err = repo.ListRemoteBranches(cfg.RemoteName)
+		}
+		if err == nil {
+			branches = withoutQuarantined(branches)
+			err = repo.ApplyAgeBasis(branches, basis)
+		}
+		if err == nil {
+			err = attachPullRequests(provider, branches)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		candidates = append(candidates, prune.Candidates(branches, cfg.RemoteAgeThreshold)...)
+	}
+
+	session.SetCandidates(fullNames(candidates))
+
+	pruner := prune.New(repo, prune.Options{Force: policy.Force, Archive: policy.Archive, Record: recordPruned})
+	for _, branch := range candidates {
+		candidate := schedule.Candidate{
+			Name:   branch.FullName(),
+			Age:    branch.Age(),
+			Reason: string(branch.Reason),
+		}
+
+		if policy.Force && !policy.IncludeUnpushed && branch.IsAtRisk() {
+			candidate.Kept = fmt.Sprintf("%d unpushed commit(s), set schedule.include_unpushed to delete it anyway", branch.Unpushed)
+			result.Candidates = append(result.Candidates, candidate)
+			continue
+		}
+
+		if policy.Unattended {
+			pruned, err := pruner.Prune(branch)
+			if err != nil {
+				candidate.Error = err.Error()
+			} else {
+				candidate.Pruned = true
+				candidate.ArchiveTag = pruned.ArchiveTag
+			}
+		}
+
+		result.Candidates = append(result.Candidates, candidate)
+	}
+
+	return result
+}
+
+// scheduledOutcome is how a repository's scheduled run ends, as its history
+// records it
+func scheduledOutcome(result *schedule.RepositoryReport) error {
+	if result.Error != "" {
+		return errors.New(result.Error)
+	}
+
+	pruned, failed := 0, 0
+	for _, c := range result.Candidates {
+		switch {
+		case c.Error != "":
+			failed++
+		case c.Pruned:
+			pruned++
+		}
+	}
+	return pruneOutcome(pruned, failed)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/stats"
+	"github.com/kriscoleman/bonsai/internal/theme"
+	"github.com/kriscoleman/bonsai/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// statsAuthorLimit is how many authors the dashboard lists
+const statsAuthorLimit = 8
+
+// statsBarWidth is the width of the longest bar of the age histogram
+const statsBarWidth = 30
+
+var (
+	statsRemote string
+	statsJSON   bool
+	statsBasis  string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "📊 Summarize the health of the repository's branches",
+	Long: `📊 Summarize the health of the repository's branches
+
+Count local and remote branches by age, list who owns the stale ones and the
+oldest branches, and find branches that were merged but never deleted.
+Nothing is changed. Use --json to track the numbers over time.`,
+	RunE: runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsRemote, "remote", "", "Remote whose branches are counted (defaults to the configured remote)")
+	statsCmd.Flags().StringVar(&statsBasis, "age-basis", "", "What branch ages count from: committer, author, last-checkout or created (defaults to committer)")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Write the stats as JSON")
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(repoPath)
+	if err != nil {
+		return err
+	}
+	remote := statsRemote
+	if remote == "" {
+		remote = cfg.RemoteName
+	}
+
+	basis, err := ageBasis(cfg, statsBasis)
+	if err != nil {
+		return err
+	}
+	if err := configureProtection(repo, cfg, nil, remote); err != nil {
+		return err
+	}
+
+	local, err := repo.ListLocalBranches()
+	if err != nil {
+		return err
+	}
+	syncHolds(repo, remote)
+	remoteBranches, err := repo.ListRemoteBranches(remote)
+	if err != nil {
+		return err
+	}
+	remoteBranches = withoutQuarantined(remoteBranches)
+
+	for _, branches := range [][]*git.Branch{local, remoteBranches} {
+		if err := repo.ApplyAgeBasis(branches, basis); err != nil {
+			return err
+		}
+	}
+
+	// Without a base branch there is nothing to be merged into
+	base, _ := resolveBaseBranch(repo, cfg, remote)
+	merged, err := mergedInto(repo, remote, base)
+	if err != nil {
+		return err
+	}
+
+	s := stats.Compute(stats.Input{
+		Local:           local,
+		Remote:          remoteBranches,
+		LocalThreshold:  cfg.LocalAgeThreshold,
+		RemoteThreshold: cfg.RemoteAgeThreshold,
+		Merged:          merged,
+	})
+	s.Remote = remote
+	s.BaseBranch = base
+	s.AgeBasis = string(basis)
+
+	if statsJSON {
+		return writeJSON(s)
+	}
+
+	printStats(s)
+	return nil
+}
+
+// mergedInto returns the full names of the local and remote branches merged
+// into base, leaving base itself out
+func mergedInto(repo *git.Repository, remote, base string) (map[string]bool, error) {
+	merged := map[string]bool{}
+	if base == "" {
+		return merged, nil
+	}
+
+	local, err := repo.MergedBranches(base)
+	if err != nil {
+		return nil, err
+	}
+	onRemote, err := repo.MergedRemoteBranches(remote, base)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range append(local, onRemote...) {
+		if name != base {
+			merged[name] = true
+		}
+	}
+	return merged, nil
+}
+
+// printStats renders the stats as a dashboard
+func printStats(s *stats.Stats) {
+	palette := theme.Colors()
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(palette.Highlight).
+		Padding(0, 1).
+		MarginTop(1)
+	headingStyle := lipgloss.NewStyle().
+		Foreground(palette.Leaf).
+		Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(palette.Text)
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(palette.Muted).
+		Italic(true)
+	localStyle := lipgloss.NewStyle().Foreground(palette.Leaf)
+	remoteStyle := lipgloss.NewStyle().Foreground(palette.Accent)
+
+	box := func(heading string, lines ...string) string {
+		return boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, append([]string{headingStyle.Render(heading)}, lines...)...))
+	}
+
+	// Totals, local next to remote
+	row := func(label string, local, remote int) string {
+		return fmt.Sprintf("%s %s %s",
+			labelStyle.Render(fmt.Sprintf("%-22s", label)),
+			localStyle.Render(fmt.Sprintf("%6d", local)),
+			remoteStyle.Render(fmt.Sprintf("%8d", remote)))
+	}
+	totals := box("🌳 Branches",
+		mutedStyle.Render(fmt.Sprintf("%-22s %6s %8s", "", "local", s.Remote)),
+		row("Total", s.Local.Total, s.RemoteSide.Total),
+		row("Stale", s.Local.Stale, s.RemoteSide.Stale),
+		row("Merged, not deleted", s.Local.Merged, s.RemoteSide.Merged),
+		row("Protected", s.Local.Protected, s.RemoteSide.Protected),
+		row("Pinned or snoozed", s.Local.Held, s.RemoteSide.Held))
+
+	// Age histogram, with a bar per side
+	largest := 0
+	for _, bucket := range s.Ages {
+		largest = max(largest, bucket.Total())
+	}
+	bar := func(count int) string {
+		if count == 0 {
+			return ""
+		}
+		return strings.Repeat("█", max(1, count*statsBarWidth/max(1, largest)))
+	}
+	var ages []string
+	for _, bucket := range s.Ages {
+		ages = append(ages, fmt.Sprintf("%s %s %s%s",
+			labelStyle.Render(fmt.Sprintf("%-12s", bucket.Label)),
+			mutedStyle.Render(fmt.Sprintf("%4d", bucket.Total())),
+			localStyle.Render(bar(bucket.Local)),
+			remoteStyle.Render(bar(bucket.Remote))))
+	}
+	ages = append(ages, "", localStyle.Render("█")+mutedStyle.Render(" local  ")+remoteStyle.Render("█")+mutedStyle.Render(" "+s.Remote))
+	histogram := box("⏳ Age since "+git.AgeBasis(s.AgeBasis).Describe(), ages...)
+
+	// Who the stale branches belong to
+	authors := []string{mutedStyle.Render("No stale branches")}
+	if len(s.StaleByAuthor) > 0 {
+		authors = nil
+		for _, author := range s.StaleByAuthor[:min(len(s.StaleByAuthor), statsAuthorLimit)] {
+			authors = append(authors, fmt.Sprintf("%s %s",
+				labelStyle.Render(fmt.Sprintf("%-24s", author.Author)),
+				localStyle.Render(fmt.Sprintf("%4d", author.Stale))))
+		}
+		if rest := len(s.StaleByAuthor) - statsAuthorLimit; rest > 0 {
+			authors = append(authors, mutedStyle.Render(fmt.Sprintf("... and %d more", rest)))
+		}
+	}
+	owners := box("👤 Stale branches by author", authors...)
+
+	// The oldest branches that could be pruned
+	oldest := []string{mutedStyle.Render("No branches")}
+	if len(s.Oldest) > 0 {
+		oldest = nil
+		for _, branch := range s.Oldest {
+			oldest = append(oldest, fmt.Sprintf("%s %s",
+				labelStyle.Render(branch.Name),
+				mutedStyle.Render(fmt.Sprintf("(%s) · %s", ui.FormatAge(time.Duration(branch.AgeDays)*24*time.Hour), branch.Author))))
+		}
+	}
+	ancients := box("🪵 Oldest branches", oldest...)
+
+	fmt.Fprintln(stdout, lipgloss.JoinVertical(lipgloss.Left, totals, histogram, owners, ancients))
+	if s.BaseBranch != "" {
+		fmt.Fprintln(stdout, mutedStyle.Render(fmt.Sprintf("Merged means merged into %s.", s.BaseBranch)))
+	}
+	fmt.Fprintln(stdout)
+}
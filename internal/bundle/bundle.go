@@ -0,0 +1,121 @@
+// Package bundle keeps offline git bundle copies of deleted branches
+package bundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestFile is the name of the manifest kept alongside the bundles
+const ManifestFile = "manifest.json"
+
+// Entry describes one branch exported before deletion
+type Entry struct {
+	Branch        string    `json:"branch"`
+	Remote        string    `json:"remote,omitempty"` // Empty for local branches
+	Ref           string    `json:"ref"`              // Ref name stored inside the bundle
+	Commit        string    `json:"commit"`           // Tip of the branch when it was exported
+	Author        string    `json:"author,omitempty"`
+	Base          string    `json:"base"`
+	UniqueCommits int       `json:"unique_commits"`
+	Bundle        string    `json:"bundle,omitempty"` // File name relative to the manifest; empty when the base already had every commit
+	ExportedAt    time.Time `json:"exported_at"`
+}
+
+// FullName returns the original branch name (with remote prefix if applicable)
+func (e Entry) FullName() string {
+	if e.Remote != "" {
+		return e.Remote + "/" + e.Branch
+	}
+	return e.Branch
+}
+
+// Manifest lists the bundles written to a directory
+type Manifest struct {
+	dir     string
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads the manifest in dir. A missing manifest yields an empty one.
+func Load(dir string) (*Manifest, error) {
+	// Bundle paths are handed to git, which may run in another directory
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// Dir returns the directory the manifest and its bundles live in
+func (m *Manifest) Dir() string {
+	return m.dir
+}
+
+// Save writes the manifest back to disk, creating the directory if needed
+func (m *Manifest) Save() error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(m.dir, ManifestFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write bundle manifest: %w", err)
+	}
+	return nil
+}
+
+// Add records an exported branch
+func (m *Manifest) Add(entry Entry) {
+	m.Entries = append(m.Entries, entry)
+}
+
+// Path returns the absolute location of an entry's bundle file
+func (m *Manifest) Path(entry Entry) string {
+	return filepath.Join(m.dir, entry.Bundle)
+}
+
+// Latest returns the most recent export of a branch, matched by its name
+// with or without the remote prefix
+func (m *Manifest) Latest(name string) (Entry, bool) {
+	var found Entry
+	ok := false
+	for _, e := range m.Entries {
+		if e.Branch != name && e.FullName() != name {
+			continue
+		}
+		if !ok || e.ExportedAt.After(found.ExportedAt) {
+			found = e
+			ok = true
+		}
+	}
+	return found, ok
+}
+
+// FileName returns a unique, filesystem-safe bundle name for a branch
+func FileName(fullName string, at time.Time) string {
+	safe := strings.NewReplacer("/", "-", "\\", "-", ":", "-").Replace(fullName)
+	return fmt.Sprintf("%s-%s.bundle", safe, at.UTC().Format("20060102T150405Z"))
+}
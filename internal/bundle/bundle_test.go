@@ -0,0 +1,78 @@
+package bundle
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManifest_RoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "bundles")
+
+	manifest, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() on a missing manifest error = %v", err)
+	}
+	if len(manifest.Entries) != 0 {
+		t.Fatalf("Load() on a missing manifest returned %d entries, want 0", len(manifest.Entries))
+	}
+
+	older := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	newer := time.Now().Truncate(time.Second)
+	manifest.Add(Entry{Branch: "feature/old", Ref: "refs/heads/feature/old", Commit: "abc123", Bundle: "a.bundle", ExportedAt: older})
+	manifest.Add(Entry{Branch: "feature/old", Ref: "refs/heads/feature/old", Commit: "def456", Bundle: "b.bundle", ExportedAt: newer})
+	manifest.Add(Entry{Branch: "feature/shared", Remote: "origin", Ref: "refs/remotes/origin/feature/shared", Commit: "fff999", ExportedAt: older})
+
+	// Save creates the directory on first use
+	if err := manifest.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Entries) != 3 {
+		t.Fatalf("Load() returned %d entries, want 3", len(loaded.Entries))
+	}
+
+	latest, ok := loaded.Latest("feature/old")
+	if !ok {
+		t.Fatal("Latest(feature/old) found nothing")
+	}
+	if latest.Commit != "def456" {
+		t.Errorf("Latest(feature/old).Commit = %s, want def456", latest.Commit)
+	}
+	if got := loaded.Path(latest); got != filepath.Join(dir, "b.bundle") {
+		t.Errorf("Path() = %s, want %s", got, filepath.Join(dir, "b.bundle"))
+	}
+
+	if _, ok := loaded.Latest("origin/feature/shared"); !ok {
+		t.Error("Latest() should match a remote branch by its full name")
+	}
+	if _, ok := loaded.Latest("feature/missing"); ok {
+		t.Error("Latest() should not match an unknown branch")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		fullName string
+		want     string
+	}{
+		{"local branch", "feature-x", "feature-x-20240301T103000Z.bundle"},
+		{"nested branch", "users/jane/fix", "users-jane-fix-20240301T103000Z.bundle"},
+		{"remote branch", "origin/feature/x", "origin-feature-x-20240301T103000Z.bundle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FileName(tt.fullName, at); got != tt.want {
+				t.Errorf("FileName(%s) = %s, want %s", tt.fullName, got, tt.want)
+			}
+		})
+	}
+}
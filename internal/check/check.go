@@ -0,0 +1,165 @@
+// Package check holds a repository's branches to limits, so that CI can
+// enforce the same policy bonsai prunes by
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+)
+
+// Rule names
+const (
+	RuleMaxStale      = "max-stale"
+	RuleMaxAge        = "max-age"
+	RuleMaxNoUpstream = "max-no-upstream"
+)
+
+// Policy is the set of limits a repository's branches are held to
+type Policy struct {
+	Threshold     time.Duration // Age at which a branch is stale
+	MaxStale      int           // Most stale branches allowed; negative for no limit
+	MaxAge        time.Duration // Longest a branch may go without commits; zero for no limit
+	MaxNoUpstream int           // Most local branches without an upstream; negative for no limit
+}
+
+// Empty reports whether the policy sets no limit at all
+func (p Policy) Empty() bool {
+	return p.MaxStale < 0 && p.MaxAge <= 0 && p.MaxNoUpstream < 0
+}
+
+// Rule is one limit of a policy and how the branches fared against it
+type Rule struct {
+	Name     string // e.g. "max-stale"
+	Limit    string // The limit, e.g. "20" or "180 days"
+	Failures []Failure
+}
+
+// Failure is a branch, or the repository as a whole, breaking a rule
+type Failure struct {
+	Branch  string // Empty when the rule concerns the repository as a whole
+	Message string
+}
+
+// Passed reports whether nothing broke the rule
+func (r Rule) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Report is the outcome of checking a repository's branches
+type Report struct {
+	Branches int // How many branches were checked
+	Rules    []Rule
+}
+
+// Passed reports whether every rule passed
+func (r *Report) Passed() bool {
+	return r.Failed() == 0
+}
+
+// Failed returns the number of rules that failed
+func (r *Report) Failed() int {
+	count := 0
+	for _, rule := range r.Rules {
+		if !rule.Passed() {
+			count++
+		}
+	}
+	return count
+}
+
+// Run checks branches against the limits the policy sets. upstreams holds
+// the upstream of each local branch that has one; it is only consulted for
+// MaxNoUpstream.
+func Run(branches []*git.Branch, upstreams map[string]git.Upstream, policy Policy) *Report {
+	report := &Report{Branches: len(branches)}
+
+	if policy.MaxStale >= 0 {
+		report.Rules = append(report.Rules, maxStale(branches, policy.Threshold, policy.MaxStale))
+	}
+	if policy.MaxAge > 0 {
+		report.Rules = append(report.Rules, maxAge(branches, policy.MaxAge))
+	}
+	if policy.MaxNoUpstream >= 0 {
+		report.Rules = append(report.Rules, maxNoUpstream(branches, upstreams, policy.MaxNoUpstream))
+	}
+
+	return report
+}
+
+// maxStale fails when more branches qualify for pruning than allowed
+func maxStale(branches []*git.Branch, threshold time.Duration, limit int) Rule {
+	rule := Rule{Name: RuleMaxStale, Limit: fmt.Sprint(limit)}
+
+	stale := prune.Candidates(branches, threshold)
+	if len(stale) > limit {
+		rule.Failures = append(rule.Failures, Failure{
+			Message: fmt.Sprintf("%d stale branch(es), more than the %d allowed: %s", len(stale), limit, names(stale)),
+		})
+	}
+	return rule
+}
+
+// maxAge fails for every branch that has been inactive for longer than
+// allowed, its age counted as the branches' age basis says. Branches bonsai would never prune are left out.
+func maxAge(branches []*git.Branch, limit time.Duration) Rule {
+	rule := Rule{Name: RuleMaxAge, Limit: days(limit)}
+
+	for _, branch := range branches {
+		if exempt(branch) || branch.Age() <= limit {
+			continue
+		}
+		rule.Failures = append(rule.Failures, Failure{
+			Branch:  branch.FullName(),
+			Message: fmt.Sprintf("%s has been inactive for %s, longer than the %s allowed", branch.FullName(), days(branch.Age()), days(limit)),
+		})
+	}
+	return rule
+}
+
+// maxNoUpstream fails when more local branches than allowed have no upstream,
+// or one that is gone
+func maxNoUpstream(branches []*git.Branch, upstreams map[string]git.Upstream, limit int) Rule {
+	rule := Rule{Name: RuleMaxNoUpstream, Limit: fmt.Sprint(limit)}
+
+	var missing []*git.Branch
+	for _, branch := range branches {
+		if branch.IsRemote || branch.IsProtected {
+			continue
+		}
+		if upstream, ok := upstreams[branch.Name]; !ok || upstream.Track == "gone" {
+			missing = append(missing, branch)
+		}
+	}
+
+	if len(missing) > limit {
+		rule.Failures = append(rule.Failures, Failure{
+			Message: fmt.Sprintf("%d branch(es) without an upstream, more than the %d allowed: %s", len(missing), limit, names(missing)),
+		})
+	}
+	return rule
+}
+
+// exempt reports whether a branch is one bonsai never prunes
+func exempt(branch *git.Branch) bool {
+	return branch.IsCurrent || branch.IsProtected || branch.IsHeld()
+}
+
+// names lists the branches' full names in order
+func names(branches []*git.Branch) string {
+	list := make([]string, len(branches))
+	for i, branch := range branches {
+		list[i] = branch.FullName()
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
+
+// days renders a duration in whole days
+func days(d time.Duration) string {
+	return fmt.Sprintf("%d days", int(d.Hours()/24))
+}
@@ -0,0 +1,116 @@
+package check
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+const day = 24 * time.Hour
+
+func branch(name string, age time.Duration) *git.Branch {
+	return &git.Branch{Name: name, LastCommitAt: time.Now().Add(-age)}
+}
+
+func TestRun(t *testing.T) {
+	main := branch("main", 400*day)
+	main.IsProtected = true
+	pinned := branch("spike/cache", 300*day)
+	pinned.IsPinned = true
+
+	branches := []*git.Branch{
+		main,
+		pinned,
+		branch("feature/old", 200*day),
+		branch("feature/stale", 40*day),
+		branch("feature/fresh", 2*day),
+	}
+	upstreams := map[string]git.Upstream{
+		"feature/fresh": {Name: "origin/feature/fresh"},
+		"feature/stale": {Name: "origin/feature/stale", Track: "gone"},
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		want   map[string]int // Failures per rule
+	}{
+		{
+			name:   "stale within limit",
+			policy: Policy{Threshold: 30 * day, MaxStale: 2, MaxNoUpstream: -1},
+			want:   map[string]int{RuleMaxStale: 0},
+		},
+		{
+			name:   "too many stale",
+			policy: Policy{Threshold: 30 * day, MaxStale: 1, MaxNoUpstream: -1},
+			want:   map[string]int{RuleMaxStale: 1},
+		},
+		{
+			name:   "too old, leaving protected and held branches alone",
+			policy: Policy{MaxStale: -1, MaxAge: 180 * day, MaxNoUpstream: -1},
+			want:   map[string]int{RuleMaxAge: 1},
+		},
+		{
+			name:   "without upstream, counting gone ones",
+			policy: Policy{MaxStale: -1, MaxNoUpstream: 2},
+			want:   map[string]int{RuleMaxNoUpstream: 1},
+		},
+		{
+			name:   "every rule",
+			policy: Policy{Threshold: 30 * day, MaxStale: 5, MaxAge: 365 * day, MaxNoUpstream: 3},
+			want:   map[string]int{RuleMaxStale: 0, RuleMaxAge: 0, RuleMaxNoUpstream: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Run(branches, upstreams, tt.policy)
+
+			if len(report.Rules) != len(tt.want) {
+				t.Fatalf("got %d rules, want %d", len(report.Rules), len(tt.want))
+			}
+			failed := 0
+			for _, rule := range report.Rules {
+				want, ok := tt.want[rule.Name]
+				if !ok {
+					t.Errorf("unexpected rule %s", rule.Name)
+					continue
+				}
+				if len(rule.Failures) != want {
+					t.Errorf("%s: %d failures, want %d: %v", rule.Name, len(rule.Failures), want, rule.Failures)
+				}
+				if want > 0 {
+					failed++
+				}
+			}
+			if report.Failed() != failed {
+				t.Errorf("Failed() = %d, want %d", report.Failed(), failed)
+			}
+		})
+	}
+}
+
+func TestRun_Messages(t *testing.T) {
+	branches := []*git.Branch{branch("feature/b", 40*day), branch("feature/a", 200*day)}
+
+	report := Run(branches, nil, Policy{Threshold: 30 * day, MaxStale: 1, MaxAge: 180 * day, MaxNoUpstream: -1})
+
+	if got, want := report.Rules[0].Failures[0].Message, "2 stale branch(es), more than the 1 allowed: feature/a, feature/b"; got != want {
+		t.Errorf("max-stale message = %q, want %q", got, want)
+	}
+	failure := report.Rules[1].Failures[0]
+	if failure.Branch != "feature/a" || !strings.Contains(failure.Message, "inactive for 200 days, longer than the 180 days allowed") {
+		t.Errorf("max-age failure = %+v", failure)
+	}
+}
+
+func TestPolicy_Empty(t *testing.T) {
+	if !(Policy{MaxStale: -1, MaxNoUpstream: -1}).Empty() {
+		t.Error("policy without limits should be empty")
+	}
+	if (Policy{MaxStale: 0, MaxNoUpstream: -1}).Empty() {
+		t.Error("a limit of zero is a limit")
+	}
+}
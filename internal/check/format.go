@@ -0,0 +1,113 @@
+package check
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatText   = "text"
+	FormatGitHub = "github"
+	FormatJUnit  = "junit"
+)
+
+// Formats lists the output formats of a check. Write renders all but text,
+// which callers style themselves.
+var Formats = []string{FormatText, FormatGitHub, FormatJUnit}
+
+// Write renders the report in a format CI systems understand
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatGitHub:
+		return r.writeGitHub(w)
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// writeGitHub renders every failure as a GitHub Actions error annotation
+func (r *Report) writeGitHub(w io.Writer) error {
+	var b strings.Builder
+	for _, rule := range r.Rules {
+		for _, failure := range rule.Failures {
+			fmt.Fprintf(&b, "::error title=%s::%s\n", escapeProperty("bonsai "+rule.Name), escapeData(failure.Message))
+		}
+	}
+	if r.Passed() {
+		fmt.Fprintf(&b, "::notice title=bonsai check::%s\n", escapeData(fmt.Sprintf("%d branch(es) within every limit", r.Branches)))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeData escapes a workflow command's message
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command's property value
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// JUnit XML, with one test case per rule
+type (
+	junitSuites struct {
+		XMLName xml.Name     `xml:"testsuites"`
+		Suites  []junitSuite `xml:"testsuite"`
+	}
+
+	junitSuite struct {
+		Name     string      `xml:"name,attr"`
+		Tests    int         `xml:"tests,attr"`
+		Failures int         `xml:"failures,attr"`
+		Cases    []junitCase `xml:"testcase"`
+	}
+
+	junitCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// writeJUnit renders the report as JUnit XML
+func (r *Report) writeJUnit(w io.Writer) error {
+	suite := junitSuite{Name: "bonsai check", Tests: len(r.Rules), Failures: r.Failed()}
+	for _, rule := range r.Rules {
+		testCase := junitCase{
+			Name:      fmt.Sprintf("%s (%s)", rule.Name, rule.Limit),
+			ClassName: "bonsai",
+		}
+		if !rule.Passed() {
+			messages := make([]string, len(rule.Failures))
+			for i, failure := range rule.Failures {
+				messages[i] = failure.Message
+			}
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d failure(s) of %s", len(rule.Failures), rule.Name),
+				Text:    strings.Join(messages, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	data, err := xml.MarshalIndent(junitSuites{Suites: []junitSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, xml.Header+string(data)+"\n")
+	return err
+}
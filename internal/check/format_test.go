@@ -0,0 +1,72 @@
+package check
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func sampleReport() *Report {
+	return &Report{
+		Branches: 12,
+		Rules: []Rule{
+			{Name: RuleMaxStale, Limit: "20"},
+			{Name: RuleMaxAge, Limit: "180 days", Failures: []Failure{
+				{Branch: "origin/feature/a", Message: "origin/feature/a has had no commits for 200 days"},
+				{Branch: "origin/fix/100%", Message: "origin/fix/100% has had no commits for 190 days"},
+			}},
+		},
+	}
+}
+
+func TestWrite_GitHub(t *testing.T) {
+	var b strings.Builder
+	if err := sampleReport().Write(&b, FormatGitHub); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := "::error title=bonsai max-age::origin/feature/a has had no commits for 200 days\n" +
+		"::error title=bonsai max-age::origin/fix/100%25 has had no commits for 190 days\n"
+	if b.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	passed := &Report{Branches: 3, Rules: []Rule{{Name: RuleMaxStale, Limit: "5"}}}
+	b.Reset()
+	if err := passed.Write(&b, FormatGitHub); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if want := "::notice title=bonsai check::3 branch(es) within every limit\n"; b.String() != want {
+		t.Errorf("Write() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestWrite_JUnit(t *testing.T) {
+	var b strings.Builder
+	if err := sampleReport().Write(&b, FormatJUnit); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var suites junitSuites
+	if err := xml.Unmarshal([]byte(b.String()), &suites); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, b.String())
+	}
+
+	suite := suites.Suites[0]
+	if suite.Tests != 2 || suite.Failures != 1 {
+		t.Errorf("tests = %d, failures = %d, want 2 and 1", suite.Tests, suite.Failures)
+	}
+	if suite.Cases[0].Name != "max-stale (20)" || suite.Cases[0].Failure != nil {
+		t.Errorf("first case = %+v, want a passing max-stale (20)", suite.Cases[0])
+	}
+	failure := suite.Cases[1].Failure
+	if failure == nil || failure.Message != "2 failure(s) of max-age" || strings.Count(failure.Text, "\n") != 1 {
+		t.Errorf("second case failure = %+v", failure)
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	if err := sampleReport().Write(&strings.Builder{}, "tap"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -1,11 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -17,6 +20,55 @@ type Config struct {
 	RemoteAgeThreshold time.Duration
 	DryRun             bool
 	BulkMode           bool
+	RemoteName         string
+	AgeBasis           string // What branch ages count from: committer, author, last-checkout or created
+	BaseBranch         string // Branch others are compared against; detected when empty
+	ProtectedBranches  []string
+	Theme              string // Color palette: dark, light or high-contrast
+	ASCII              bool   // Plain glyphs instead of emoji
+	Forge              ForgeConfig
+	Schedule           ScheduleConfig
+	Check              CheckConfig
+}
+
+// CheckConfig holds the limits bonsai check enforces
+type CheckConfig struct {
+	MaxStale      int           // Most stale branches allowed; negative for no limit
+	MaxAge        time.Duration // Longest a branch may go without commits; zero for no limit
+	MaxNoUpstream int           // Most local branches without an upstream; negative for no limit
+}
+
+// ScheduleConfig holds the policy for scheduled, unattended runs
+type ScheduleConfig struct {
+	Repositories []string // Repositories visited by scheduled runs
+	ReportDir    string   // Where run reports are written
+
+	// Unattended must be enabled explicitly before a scheduled run deletes
+	// anything; otherwise it only reports what it would prune
+	Unattended bool
+	Remote     bool // Prune remote branches as well as local ones
+	Force      bool // Delete local branches even if they are not fully merged
+	Archive    bool // Keep an archive/<branch> tag before deleting
+
+	// IncludeUnpushed lets forced deletion take branches whose commits
+	// exist nowhere else; otherwise they are kept and reported
+	IncludeUnpushed bool
+}
+
+// ForgeConfig holds settings for looking up pull requests on a hosting platform
+type ForgeConfig struct {
+	Provider string // "github", "gitlab", "auto", or empty to disable
+	GitHub   ForgeServerConfig
+	GitLab   ForgeServerConfig
+
+	// ProtectionTTL is how long the forge's branch protection rules are cached
+	ProtectionTTL time.Duration
+}
+
+// ForgeServerConfig holds the connection settings for one hosting platform
+type ForgeServerConfig struct {
+	Token   string
+	BaseURL string
 }
 
 // DefaultConfig returns the default configuration
@@ -26,6 +78,14 @@ func DefaultConfig() *Config {
 		RemoteAgeThreshold: 4 * 7 * 24 * time.Hour, // 4 weeks
 		DryRun:             false,
 		BulkMode:           false,
+		RemoteName:         "origin",
+		Forge: ForgeConfig{
+			ProtectionTTL: 24 * time.Hour,
+		},
+		Check: CheckConfig{
+			MaxStale:      -1,
+			MaxNoUpstream: -1,
+		},
 	}
 }
 
@@ -91,91 +151,320 @@ type FileConfig struct {
 		AgeThreshold string `yaml:"age_threshold"`
 		RemoteName   string `yaml:"remote_name"`
 	} `yaml:"remote"`
+	AgeBasis          string   `yaml:"age_basis"`
+	BaseBranch        string   `yaml:"base_branch"`
 	ProtectedBranches []string `yaml:"protected_branches"`
+	Theme             string   `yaml:"theme"`
+	ASCII             *bool    `yaml:"ascii"`
+	Forge             struct {
+		Provider      string `yaml:"provider"`
+		ProtectionTTL string `yaml:"protection_ttl"`
+		GitHub        struct {
+			Token   string `yaml:"token"`
+			BaseURL string `yaml:"base_url"`
+		} `yaml:"github"`
+		GitLab struct {
+			Token   string `yaml:"token"`
+			BaseURL string `yaml:"base_url"`
+		} `yaml:"gitlab"`
+	} `yaml:"forge"`
+	Schedule struct {
+		Repositories    []string `yaml:"repositories"`
+		ReportDir       string   `yaml:"report_dir"`
+		Unattended      *bool    `yaml:"unattended"`
+		Remote          *bool    `yaml:"remote"`
+		Force           *bool    `yaml:"force"`
+		IncludeUnpushed *bool    `yaml:"include_unpushed"`
+		Archive         *bool    `yaml:"archive"`
+	} `yaml:"schedule"`
+	Check struct {
+		MaxStale      *int   `yaml:"max_stale"`
+		MaxAge        string `yaml:"max_age"`
+		MaxNoUpstream *int   `yaml:"max_no_upstream"`
+	} `yaml:"check"`
 }
 
 // LoadConfigFile loads configuration from a file
 func LoadConfigFile(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	if err := cfg.apply(path); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// apply overlays the settings present in a configuration file onto the config.
+// Settings the file leaves out keep their current values, and protected
+// branches are added to the ones already configured.
+func (c *Config) apply(path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	var fileConfig FileConfig
 	if err := yaml.Unmarshal(data, &fileConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	cfg := DefaultConfig()
-
 	// Parse local age threshold if specified
 	if fileConfig.Local.AgeThreshold != "" {
 		duration, err := ParseDuration(fileConfig.Local.AgeThreshold)
 		if err != nil {
-			return nil, fmt.Errorf("invalid local age threshold: %w", err)
+			return fmt.Errorf("invalid local age threshold: %w", err)
 		}
-		cfg.LocalAgeThreshold = duration
+		c.LocalAgeThreshold = duration
 	}
 
 	// Parse remote age threshold if specified
 	if fileConfig.Remote.AgeThreshold != "" {
 		duration, err := ParseDuration(fileConfig.Remote.AgeThreshold)
 		if err != nil {
-			return nil, fmt.Errorf("invalid remote age threshold: %w", err)
+			return fmt.Errorf("invalid remote age threshold: %w", err)
 		}
-		cfg.RemoteAgeThreshold = duration
+		c.RemoteAgeThreshold = duration
 	}
 
-	return cfg, nil
-}
+	if fileConfig.Remote.RemoteName != "" {
+		c.RemoteName = fileConfig.Remote.RemoteName
+	}
 
-// FindConfigFile looks for a configuration file in standard locations
-// Returns the path to the first config file found, or empty string if none found
-func FindConfigFile() string {
-	// Check current directory first
-	if _, err := os.Stat(".bonsai.yaml"); err == nil {
-		return ".bonsai.yaml"
+	switch fileConfig.AgeBasis {
+	case "":
+	case "committer", "author", "last-checkout", "created":
+		c.AgeBasis = fileConfig.AgeBasis
+	default:
+		return fmt.Errorf("unsupported age basis: %s", fileConfig.AgeBasis)
+	}
+
+	if fileConfig.BaseBranch != "" {
+		c.BaseBranch = fileConfig.BaseBranch
+	}
+	c.ProtectedBranches = append(c.ProtectedBranches, fileConfig.ProtectedBranches...)
+
+	// Display settings
+	overlay(&c.Theme, fileConfig.Theme)
+	overlayBool(&c.ASCII, fileConfig.ASCII)
+
+	// Forge settings
+	switch fileConfig.Forge.Provider {
+	case "":
+	case "github", "gitlab", "auto":
+		c.Forge.Provider = fileConfig.Forge.Provider
+	default:
+		return fmt.Errorf("unsupported forge provider: %s", fileConfig.Forge.Provider)
+	}
+	overlay(&c.Forge.GitHub.Token, fileConfig.Forge.GitHub.Token)
+	overlay(&c.Forge.GitHub.BaseURL, fileConfig.Forge.GitHub.BaseURL)
+	overlay(&c.Forge.GitLab.Token, fileConfig.Forge.GitLab.Token)
+	overlay(&c.Forge.GitLab.BaseURL, fileConfig.Forge.GitLab.BaseURL)
+
+	if fileConfig.Forge.ProtectionTTL != "" {
+		duration, err := ParseDuration(fileConfig.Forge.ProtectionTTL)
+		if err != nil {
+			return fmt.Errorf("invalid protection rule cache TTL: %w", err)
+		}
+		c.Forge.ProtectionTTL = duration
 	}
-	if _, err := os.Stat(".bonsai.yml"); err == nil {
-		return ".bonsai.yml"
+
+	// Schedule settings
+	for _, repo := range fileConfig.Schedule.Repositories {
+		c.Schedule.Repositories = append(c.Schedule.Repositories, expandHome(repo))
+	}
+	if fileConfig.Schedule.ReportDir != "" {
+		c.Schedule.ReportDir = expandHome(fileConfig.Schedule.ReportDir)
 	}
+	overlayBool(&c.Schedule.Unattended, fileConfig.Schedule.Unattended)
+	overlayBool(&c.Schedule.Remote, fileConfig.Schedule.Remote)
+	overlayBool(&c.Schedule.Force, fileConfig.Schedule.Force)
+	overlayBool(&c.Schedule.IncludeUnpushed, fileConfig.Schedule.IncludeUnpushed)
+	overlayBool(&c.Schedule.Archive, fileConfig.Schedule.Archive)
 
-	// Check home directory
+	// Check limits
+	if fileConfig.Check.MaxStale != nil {
+		c.Check.MaxStale = *fileConfig.Check.MaxStale
+	}
+	if fileConfig.Check.MaxAge != "" {
+		duration, err := ParseDuration(fileConfig.Check.MaxAge)
+		if err != nil {
+			return fmt.Errorf("invalid check max age: %w", err)
+		}
+		c.Check.MaxAge = duration
+	}
+	if fileConfig.Check.MaxNoUpstream != nil {
+		c.Check.MaxNoUpstream = *fileConfig.Check.MaxNoUpstream
+	}
+
+	return nil
+}
+
+// overlayBool replaces *dst with *value if the file set it
+func overlayBool(dst *bool, value *bool) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
 	home, err := os.UserHomeDir()
-	if err == nil {
-		homeConfig := filepath.Join(home, ".bonsai.yaml")
-		if _, err := os.Stat(homeConfig); err == nil {
-			return homeConfig
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// overlay replaces *dst with value unless value is empty
+func overlay(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+// ConfigFiles returns the configuration files that apply to the repository in
+// dir, from lowest to highest precedence: the XDG config, the one in the home
+// directory, then the one in dir itself. An empty dir means the current directory.
+func ConfigFiles(dir string) []string {
+	candidates := append(userConfigFiles(), firstExisting(
+		filepath.Join(dir, ".bonsai.yaml"),
+		filepath.Join(dir, ".bonsai.yml")))
+
+	// The repository may live in the home directory itself
+	var files []string
+	seen := map[string]bool{}
+	for _, path := range candidates {
+		if path == "" {
+			continue
 		}
-		homeConfigYml := filepath.Join(home, ".bonsai.yml")
-		if _, err := os.Stat(homeConfigYml); err == nil {
-			return homeConfigYml
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			abs = path
+		}
+		if !seen[abs] {
+			seen[abs] = true
+			files = append(files, path)
 		}
 	}
 
-	// Check XDG config directory
+	return files
+}
+
+// userConfigFiles returns the user's own configuration files, the XDG config
+// and the one in the home directory; paths that don't exist are empty
+func userConfigFiles() []string {
+	var files []string
 	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
-		xdgConfigFile := filepath.Join(xdgConfig, "bonsai", "config.yaml")
-		if _, err := os.Stat(xdgConfigFile); err == nil {
-			return xdgConfigFile
-		}
+		files = append(files, firstExisting(filepath.Join(xdgConfig, "bonsai", "config.yaml")))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, firstExisting(
+			filepath.Join(home, ".bonsai.yaml"),
+			filepath.Join(home, ".bonsai.yml")))
 	}
+	return files
+}
 
+// firstExisting returns the first of the paths that exists, or an empty string
+func firstExisting(paths ...string) string {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
 	return ""
 }
 
-// LoadConfig loads configuration from file if it exists, otherwise returns default config
-func LoadConfig() *Config {
-	configPath := FindConfigFile()
-	if configPath == "" {
-		return DefaultConfig()
+// LoadConfigFrom layers every configuration file that applies to the
+// repository in dir over the defaults. A repository's own file can rule out
+// unattended, remote and forced scheduled deletions, but only the user's
+// config can allow them, so a cloned repository can't turn them on. Likewise
+// which forge is asked about pull requests, where and with what token is only
+// taken from the user's config, so a repository can't send tokens elsewhere.
+func LoadConfigFrom(dir string) (*Config, error) {
+	cfg := DefaultConfig()
+	user := userConfigFiles()
+	for _, path := range ConfigFiles(dir) {
+		policy, forge := cfg.Schedule, cfg.Forge
+		if err := cfg.apply(path); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if !slices.Contains(user, path) {
+			cfg.Schedule.Unattended = cfg.Schedule.Unattended && policy.Unattended
+			cfg.Schedule.Remote = cfg.Schedule.Remote && policy.Remote
+			cfg.Schedule.Force = cfg.Schedule.Force && policy.Force
+			cfg.Schedule.IncludeUnpushed = cfg.Schedule.IncludeUnpushed && policy.IncludeUnpushed
+			cfg.Forge.Provider, cfg.Forge.GitHub, cfg.Forge.GitLab = forge.Provider, forge.GitHub, forge.GitLab
+		}
+	}
+	return cfg, nil
+}
+
+// AddProtectedBranch adds a pattern to the protected branches of the
+// configuration file in dir, creating .bonsai.yaml if there is none. The rest
+// of the file, comments included, is left as it is. Returns the file's path.
+func AddProtectedBranch(dir, pattern string) (string, error) {
+	path := firstExisting(
+		filepath.Join(dir, ".bonsai.yaml"),
+		filepath.Join(dir, ".bonsai.yml"))
+	if path == "" {
+		path = filepath.Join(dir, ".bonsai.yaml")
 	}
 
-	cfg, err := LoadConfigFile(configPath)
-	if err != nil {
-		// If config file exists but can't be loaded, fall back to defaults
-		return DefaultConfig()
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("%s: expected a mapping at the top level", path)
 	}
 
-	return cfg
+	var patterns *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "protected_branches" {
+			patterns = root.Content[i+1]
+			break
+		}
+	}
+	if patterns == nil {
+		patterns = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "protected_branches"}, patterns)
+	}
+	if patterns.Kind != yaml.SequenceNode {
+		// An empty "protected_branches:" parses as a null scalar
+		if patterns.Tag != "!!null" {
+			return "", fmt.Errorf("%s: protected_branches must be a list", path)
+		}
+		*patterns = yaml.Node{Kind: yaml.SequenceNode}
+	}
+
+	for _, existing := range patterns.Content {
+		if existing.Value == pattern {
+			return path, nil
+		}
+	}
+	patterns.Content = append(patterns.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: pattern})
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
 }
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -249,9 +252,12 @@ local:
 remote:
   age_threshold: "3w"
   remote_name: "upstream"
+base_branch: "trunk"
 protected_branches:
   - "production"
   - "staging"
+theme: "light"
+ascii: true
 `
 
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
@@ -272,6 +278,140 @@ protected_branches:
 	if cfg.RemoteAgeThreshold != expectedRemote {
 		t.Errorf("RemoteAgeThreshold = %v, want %v", cfg.RemoteAgeThreshold, expectedRemote)
 	}
+
+	if cfg.RemoteName != "upstream" {
+		t.Errorf("RemoteName = %s, want upstream", cfg.RemoteName)
+	}
+
+	if cfg.BaseBranch != "trunk" {
+		t.Errorf("BaseBranch = %s, want trunk", cfg.BaseBranch)
+	}
+
+	if len(cfg.ProtectedBranches) != 2 || cfg.ProtectedBranches[0] != "production" || cfg.ProtectedBranches[1] != "staging" {
+		t.Errorf("ProtectedBranches = %v, want [production staging]", cfg.ProtectedBranches)
+	}
+
+	if cfg.Theme != "light" || !cfg.ASCII {
+		t.Errorf("Theme = %q, ASCII = %v, want light and true", cfg.Theme, cfg.ASCII)
+	}
+}
+
+func TestLoadConfigFile_Forge(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := tmpDir + "/forge.yaml"
+
+	configContent := `
+forge:
+  provider: github
+  protection_ttl: "1h"
+  github:
+    token: "ghp_example"
+    base_url: "https://github.example.com/api/v3"
+  gitlab:
+    token: "glpat-example"
+    base_url: "https://code.example.org/api/v4"
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cfg, err := LoadConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	if cfg.Forge.Provider != "github" {
+		t.Errorf("Forge.Provider = %s, want github", cfg.Forge.Provider)
+	}
+	if cfg.Forge.GitHub.Token != "ghp_example" {
+		t.Errorf("Forge.GitHub.Token = %s, want ghp_example", cfg.Forge.GitHub.Token)
+	}
+	if cfg.Forge.GitHub.BaseURL != "https://github.example.com/api/v3" {
+		t.Errorf("Forge.GitHub.BaseURL = %s, want https://github.example.com/api/v3", cfg.Forge.GitHub.BaseURL)
+	}
+	if cfg.Forge.ProtectionTTL != time.Hour {
+		t.Errorf("Forge.ProtectionTTL = %v, want 1h", cfg.Forge.ProtectionTTL)
+	}
+	if cfg.Forge.GitLab.Token != "glpat-example" {
+		t.Errorf("Forge.GitLab.Token = %s, want glpat-example", cfg.Forge.GitLab.Token)
+	}
+	if cfg.Forge.GitLab.BaseURL != "https://code.example.org/api/v4" {
+		t.Errorf("Forge.GitLab.BaseURL = %s, want https://code.example.org/api/v4", cfg.Forge.GitLab.BaseURL)
+	}
+}
+
+func TestLoadConfigFile_Check(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := tmpDir + "/check.yaml"
+
+	configContent := `
+check:
+  max_stale: 0
+  max_age: "6M"
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cfg, err := LoadConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	if cfg.Check.MaxStale != 0 {
+		t.Errorf("Check.MaxStale = %d, want 0", cfg.Check.MaxStale)
+	}
+	if cfg.Check.MaxAge != 180*24*time.Hour {
+		t.Errorf("Check.MaxAge = %v, want 180 days", cfg.Check.MaxAge)
+	}
+	if cfg.Check.MaxNoUpstream != -1 {
+		t.Errorf("Check.MaxNoUpstream = %d, want -1 when not set", cfg.Check.MaxNoUpstream)
+	}
+}
+
+func TestLoadConfigFile_UnknownForge(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := tmpDir + "/forge.yaml"
+
+	if err := os.WriteFile(configPath, []byte("forge:\n  provider: sourceforge\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	if _, err := LoadConfigFile(configPath); err == nil {
+		t.Error("LoadConfigFile() should return error for an unsupported forge provider")
+	}
+}
+
+func TestLoadConfigFile_AgeBasis(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"age_basis: last-checkout\n", "last-checkout", false},
+		{"local:\n  age_threshold: \"1w\"\n", "", false},
+		{"age_basis: reflog\n", "", true},
+	}
+
+	for i, tt := range tests {
+		configPath := filepath.Join(tmpDir, "basis"+strconv.Itoa(i)+".yaml")
+		if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+
+		cfg, err := LoadConfigFile(configPath)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LoadConfigFile(%q) error = %v, wantErr %v", tt.content, err, tt.wantErr)
+			continue
+		}
+		if err == nil && cfg.AgeBasis != tt.want {
+			t.Errorf("AgeBasis = %q, want %q", cfg.AgeBasis, tt.want)
+		}
+	}
 }
 
 func TestLoadConfigFile_InvalidYAML(t *testing.T) {
@@ -320,30 +460,242 @@ func TestLoadConfigFile_NonExistent(t *testing.T) {
 	}
 }
 
-func TestLoadConfig(t *testing.T) {
-	// This test just verifies that LoadConfig returns a config
-	// without errors (it will use defaults if no file is found)
-	cfg := LoadConfig()
-	if cfg == nil {
-		t.Fatal("LoadConfig() returned nil")
-		return
+func TestLoadConfigFrom_Layering(t *testing.T) {
+	home := t.TempDir()
+	xdg := t.TempDir()
+	repoDir := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	files := map[string]string{
+		xdg + "/bonsai/config.yaml": `
+local:
+  age_threshold: "1w"
+protected_branches:
+  - "production"
+`,
+		home + "/.bonsai.yaml": `
+remote:
+  remote_name: "upstream"
+local:
+  age_threshold: "3d"
+schedule:
+  unattended: true
+  repositories:
+    - "~/src/app"
+`,
+		repoDir + "/.bonsai.yml": `
+protected_branches:
+  - "release/*"
+schedule:
+  unattended: false
+  report_dir: "~/reports"
+`,
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create config directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create config file: %v", err)
+		}
+	}
+
+	if got := ConfigFiles(repoDir); len(got) != 3 {
+		t.Fatalf("ConfigFiles() = %v, want 3 files", got)
+	}
+
+	cfg, err := LoadConfigFrom(repoDir)
+	if err != nil {
+		t.Fatalf("LoadConfigFrom() error = %v", err)
+	}
+
+	// The home config overrides the XDG one, and unset values keep their defaults
+	if cfg.LocalAgeThreshold != 3*24*time.Hour {
+		t.Errorf("LocalAgeThreshold = %v, want 72h", cfg.LocalAgeThreshold)
+	}
+	if cfg.RemoteAgeThreshold != 4*7*24*time.Hour {
+		t.Errorf("RemoteAgeThreshold = %v, want the default", cfg.RemoteAgeThreshold)
+	}
+	if cfg.RemoteName != "upstream" {
+		t.Errorf("RemoteName = %s, want upstream", cfg.RemoteName)
+	}
+
+	// Protected branches from every layer are combined
+	if len(cfg.ProtectedBranches) != 2 || cfg.ProtectedBranches[0] != "production" || cfg.ProtectedBranches[1] != "release/*" {
+		t.Errorf("ProtectedBranches = %v, want [production release/*]", cfg.ProtectedBranches)
+	}
+
+	// An explicit false in the repository config wins over the home config
+	if cfg.Schedule.Unattended {
+		t.Error("Schedule.Unattended should be disabled by the repository config")
+	}
+	if len(cfg.Schedule.Repositories) != 1 || cfg.Schedule.Repositories[0] != filepath.Join(home, "src/app") {
+		t.Errorf("Schedule.Repositories = %v, want [%s]", cfg.Schedule.Repositories, filepath.Join(home, "src/app"))
+	}
+	if cfg.Schedule.ReportDir != filepath.Join(home, "reports") {
+		t.Errorf("Schedule.ReportDir = %s, want %s", cfg.Schedule.ReportDir, filepath.Join(home, "reports"))
+	}
+}
+
+func TestLoadConfigFrom_RepositorySchedule(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	repoDir := t.TempDir()
+
+	schedule := "schedule:\n  unattended: true\n  remote: true\n  force: true\n  include_unpushed: true\n  archive: true\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ".bonsai.yaml"), []byte(schedule), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	// A cloned repository can't turn on destructive scheduled runs
+	cfg, err := LoadConfigFrom(repoDir)
+	if err != nil {
+		t.Fatalf("LoadConfigFrom() error = %v", err)
+	}
+	if cfg.Schedule.Unattended || cfg.Schedule.Remote || cfg.Schedule.Force || cfg.Schedule.IncludeUnpushed {
+		t.Errorf("Schedule = %+v, want the repository to leave deletions off", cfg.Schedule)
+	}
+	if !cfg.Schedule.Archive {
+		t.Error("Schedule.Archive should be taken from the repository config")
 	}
 
-	// Should have default values
-	if cfg.LocalAgeThreshold == 0 {
-		t.Error("LoadConfig() returned config with zero LocalAgeThreshold")
+	// The user's own config can
+	if err := os.WriteFile(filepath.Join(home, ".bonsai.yaml"), []byte(schedule), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+	if cfg, err = LoadConfigFrom(repoDir); err != nil {
+		t.Fatalf("LoadConfigFrom() error = %v", err)
+	}
+	if !cfg.Schedule.Unattended || !cfg.Schedule.Remote || !cfg.Schedule.Force || !cfg.Schedule.IncludeUnpushed {
+		t.Errorf("Schedule = %+v, want the user config to enable deletions", cfg.Schedule)
 	}
 
-	if cfg.RemoteAgeThreshold == 0 {
-		t.Error("LoadConfig() returned config with zero RemoteAgeThreshold")
+	// Including when the repository is the home directory
+	if cfg, err = LoadConfigFrom(home); err != nil || !cfg.Schedule.Unattended {
+		t.Errorf("LoadConfigFrom(home) = %+v, %v, want unattended", cfg, err)
 	}
 }
 
-func TestFindConfigFile(t *testing.T) {
-	// This test verifies the function works without errors
-	// The actual path returned depends on the environment
-	path := FindConfigFile()
+func TestLoadConfigFrom_RepositoryForge(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	repoDir := t.TempDir()
+
+	forge := "forge:\n  provider: github\n  protection_ttl: 1h\n" +
+		"  github:\n    token: repo-token\n    base_url: https://collector.example.com\n" +
+		"  gitlab:\n    token: repo-token\n    base_url: https://collector.example.com\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ".bonsai.yaml"), []byte(forge), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	// A cloned repository can't pick where tokens are sent
+	cfg, err := LoadConfigFrom(repoDir)
+	if err != nil {
+		t.Fatalf("LoadConfigFrom() error = %v", err)
+	}
+	if cfg.Forge.Provider != "" || cfg.Forge.GitHub != (ForgeServerConfig{}) || cfg.Forge.GitLab != (ForgeServerConfig{}) {
+		t.Errorf("Forge = %+v, want the repository's provider, token and base_url ignored", cfg.Forge)
+	}
+	if cfg.Forge.ProtectionTTL != time.Hour {
+		t.Errorf("Forge.ProtectionTTL = %v, want it taken from the repository config", cfg.Forge.ProtectionTTL)
+	}
+
+	// The user's own config can, and the repository can't override it
+	user := "forge:\n  provider: auto\n" +
+		"  github:\n    base_url: https://github.example.com/api/v3\n" +
+		"  gitlab:\n    base_url: https://code.example.org/api/v4\n"
+	if err := os.WriteFile(filepath.Join(home, ".bonsai.yaml"), []byte(user), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+	if cfg, err = LoadConfigFrom(repoDir); err != nil {
+		t.Fatalf("LoadConfigFrom() error = %v", err)
+	}
+	if cfg.Forge.Provider != "auto" || cfg.Forge.GitHub.BaseURL != "https://github.example.com/api/v3" || cfg.Forge.GitHub.Token != "" {
+		t.Errorf("Forge = %+v, want the user's provider and base_url only", cfg.Forge)
+	}
+	if cfg.Forge.GitLab.BaseURL != "https://code.example.org/api/v4" || cfg.Forge.GitLab.Token != "" {
+		t.Errorf("Forge.GitLab = %+v, want the user's base_url only", cfg.Forge.GitLab)
+	}
+}
+
+func TestLoadConfigFrom_InvalidFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	repoDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(repoDir, ".bonsai.yaml"), []byte("local:\n  age_threshold: \"soon\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	if _, err := LoadConfigFrom(repoDir); err == nil {
+		t.Error("LoadConfigFrom() should return error for an invalid file")
+	}
+}
+
+func TestAddProtectedBranch(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // Empty means there is no config file yet
+		want     []string
+	}{
+		{"no config file", "", []string{"feature/keep"}},
+		{"no protected branches", "# Team settings\nlocal:\n  age_threshold: \"1w\"\n", []string{"feature/keep"}},
+		{"empty list", "protected_branches:\n", []string{"feature/keep"}},
+		{"existing list", "protected_branches:\n  - \"release/*\" # shipped\n", []string{"release/*", "feature/keep"}},
+		{"already protected", "protected_branches:\n  - feature/keep\n", []string{"feature/keep"}},
+	}
 
-	// Path should be either empty (no config found) or a string
-	_ = path
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if tt.existing != "" {
+			if err := os.WriteFile(filepath.Join(dir, ".bonsai.yaml"), []byte(tt.existing), 0644); err != nil {
+				t.Fatalf("Failed to create config file: %v", err)
+			}
+		}
+
+		path, err := AddProtectedBranch(dir, "feature/keep")
+		if err != nil {
+			t.Fatalf("%s: AddProtectedBranch() error = %v", tt.name, err)
+		}
+
+		cfg, err := LoadConfigFile(path)
+		if err != nil {
+			t.Fatalf("%s: LoadConfigFile() error = %v", tt.name, err)
+		}
+		if strings.Join(cfg.ProtectedBranches, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: ProtectedBranches = %v, want %v", tt.name, cfg.ProtectedBranches, tt.want)
+		}
+
+		// Settings and comments already in the file are kept
+		data, _ := os.ReadFile(path)
+		if strings.Contains(tt.existing, "# Team settings") && cfg.LocalAgeThreshold != 7*24*time.Hour {
+			t.Errorf("%s: LocalAgeThreshold = %v, want 1w", tt.name, cfg.LocalAgeThreshold)
+		}
+		for _, comment := range []string{"# Team settings", "# shipped"} {
+			if strings.Contains(tt.existing, comment) && !strings.Contains(string(data), comment) {
+				t.Errorf("%s: comment %q was dropped:\n%s", tt.name, comment, data)
+			}
+		}
+	}
+}
+
+func TestAddProtectedBranch_PrefersYml(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".bonsai.yml"), []byte("base_branch: main\n"), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	path, err := AddProtectedBranch(dir, "keep")
+	if err != nil {
+		t.Fatalf("AddProtectedBranch() error = %v", err)
+	}
+	if filepath.Base(path) != ".bonsai.yml" {
+		t.Errorf("AddProtectedBranch() wrote %s, want the existing .bonsai.yml", path)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".bonsai.yaml")); err == nil {
+		t.Error("AddProtectedBranch() should not create .bonsai.yaml next to .bonsai.yml")
+	}
 }
@@ -0,0 +1,164 @@
+// Package forge looks up branch metadata on code hosting platforms such as GitHub
+package forge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+// Provider looks up pull request state on a hosting platform
+type Provider interface {
+	// Name returns the human readable platform name, e.g. "GitHub"
+	Name() string
+
+	// PullRequests returns the most relevant pull request for each of the
+	// given branch names. Branches without a pull request are left out.
+	PullRequests(branches []string) (map[string]*git.PullRequest, error)
+
+	// ProtectionRules returns the branch patterns the server refuses to
+	// delete. Patterns may use "*" as a wildcard.
+	ProtectionRules() ([]string, error)
+}
+
+// Options configures a provider
+type Options struct {
+	Token   string       // API token, optional for public projects
+	BaseURL string       // API endpoint; derived from the remote URL when empty
+	Client  *http.Client // HTTP client to use; defaults to one with a timeout
+}
+
+// httpClient returns the configured client or a sensible default
+func (o Options) httpClient() *http.Client {
+	if o.Client != nil {
+		return o.Client
+	}
+	return &http.Client{Timeout: 15 * time.Second}
+}
+
+// RemoteInfo holds the parts of a remote URL needed to reach the forge API
+type RemoteInfo struct {
+	Scheme string // "https" unless the remote itself uses plain http
+	Host   string // Host name, including the port for http(s) remotes
+	Path   string // Project path, e.g. "owner/repo" or "group/subgroup/project"
+}
+
+// ParseRemoteURL extracts the host and project path from a Git remote URL.
+// Supported forms are https://host/path, ssh://git@host/path and the
+// scp-like git@host:path syntax.
+func ParseRemoteURL(raw string) (*RemoteInfo, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("empty remote URL")
+	}
+
+	info := &RemoteInfo{Scheme: "https"}
+
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote URL %q: %w", raw, err)
+		}
+
+		switch u.Scheme {
+		case "http", "https":
+			info.Scheme = u.Scheme
+			info.Host = u.Host
+		default:
+			// ssh:// and git:// ports are not the web port, so drop them
+			info.Host = u.Hostname()
+		}
+		info.Path = u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		at := strings.LastIndex(raw, "@")
+		colon := strings.Index(raw, ":")
+		if colon == -1 || colon < at {
+			return nil, fmt.Errorf("unsupported remote URL %q", raw)
+		}
+		info.Host = raw[at+1 : colon]
+		info.Path = raw[colon+1:]
+	}
+
+	info.Path = strings.TrimSuffix(strings.Trim(info.Path, "/"), ".git")
+	if info.Host == "" || info.Path == "" {
+		return nil, fmt.Errorf("unsupported remote URL %q", raw)
+	}
+
+	return info, nil
+}
+
+// Detect guesses which platform hosts a remote from its host name.
+// It returns "github", "gitlab" or an empty string when unsure.
+func Detect(remoteURL string) string {
+	info, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.ToLower(info.Host)
+	switch {
+	case strings.Contains(host, "github"):
+		return "github"
+	case strings.Contains(host, "gitlab"):
+		return "gitlab"
+	default:
+		return ""
+	}
+}
+
+// getJSON performs a GET request and decodes the JSON response into out
+func getJSON(client *http.Client, endpoint string, headers map[string]string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &apiError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// apiError is returned when a forge API answers with a non-success status
+type apiError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Status, e.Body)
+}
+
+// isNotFound reports whether err is a 404 answer from the API
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
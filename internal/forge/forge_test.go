@@ -0,0 +1,104 @@
+package forge
+
+import "testing"
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantScheme string
+		wantHost   string
+		wantPath   string
+		wantErr    bool
+	}{
+		{
+			name:       "https with .git suffix",
+			url:        "https://github.com/kriscoleman/bonsai.git",
+			wantScheme: "https",
+			wantHost:   "github.com",
+			wantPath:   "kriscoleman/bonsai",
+		},
+		{
+			name:       "https without suffix",
+			url:        "https://github.com/kriscoleman/bonsai",
+			wantScheme: "https",
+			wantHost:   "github.com",
+			wantPath:   "kriscoleman/bonsai",
+		},
+		{
+			name:       "http with port",
+			url:        "http://git.internal:8080/team/app.git",
+			wantScheme: "http",
+			wantHost:   "git.internal:8080",
+			wantPath:   "team/app",
+		},
+		{
+			name:       "scp-like ssh",
+			url:        "git@github.com:kriscoleman/bonsai.git",
+			wantScheme: "https",
+			wantHost:   "github.com",
+			wantPath:   "kriscoleman/bonsai",
+		},
+		{
+			name:       "ssh url with port",
+			url:        "ssh://git@gitlab.example.com:2222/group/sub/project.git",
+			wantScheme: "https",
+			wantHost:   "gitlab.example.com",
+			wantPath:   "group/sub/project",
+		},
+		{
+			name:    "local path",
+			url:     "/srv/git/project.git",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			url:     "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseRemoteURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRemoteURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if info.Scheme != tt.wantScheme {
+				t.Errorf("Scheme = %s, want %s", info.Scheme, tt.wantScheme)
+			}
+			if info.Host != tt.wantHost {
+				t.Errorf("Host = %s, want %s", info.Host, tt.wantHost)
+			}
+			if info.Path != tt.wantPath {
+				t.Errorf("Path = %s, want %s", info.Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "git@github.com:kriscoleman/bonsai.git", want: "github"},
+		{url: "https://github.example.com/team/app.git", want: "github"},
+		{url: "https://gitlab.com/group/project.git", want: "gitlab"},
+		{url: "ssh://git@gitlab.internal:2222/group/project.git", want: "gitlab"},
+		{url: "https://code.example.org/group/project.git", want: ""},
+		{url: "/srv/git/project.git", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := Detect(tt.url); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
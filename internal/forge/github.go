@@ -0,0 +1,231 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+const defaultGitHubAPI = "https://api.github.com"
+
+// GitHub looks up pull requests on github.com or a GitHub Enterprise server
+type GitHub struct {
+	owner   string
+	repo    string
+	baseURL string
+	opts    Options
+}
+
+// NewGitHub creates a GitHub provider for the repository behind remoteURL.
+// When no base URL is configured, github.com remotes use the public API and
+// any other host is treated as GitHub Enterprise (https://host/api/v3).
+func NewGitHub(remoteURL string, opts Options) (*GitHub, error) {
+	info, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(info.Path, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("remote %q is not a GitHub repository URL", remoteURL)
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		if info.Host == "github.com" {
+			baseURL = defaultGitHubAPI
+		} else {
+			baseURL = fmt.Sprintf("%s://%s/api/v3", info.Scheme, info.Host)
+		}
+	}
+
+	return &GitHub{
+		owner:   parts[0],
+		repo:    parts[1],
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		opts:    opts,
+	}, nil
+}
+
+// Name returns the platform name
+func (g *GitHub) Name() string {
+	return "GitHub"
+}
+
+type githubPullRequest struct {
+	Number   int     `json:"number"`
+	State    string  `json:"state"`
+	MergedAt *string `json:"merged_at"`
+	HTMLURL  string  `json:"html_url"`
+}
+
+// PullRequests looks up the pull requests opened from each branch
+func (g *GitHub) PullRequests(branches []string) (map[string]*git.PullRequest, error) {
+	client := g.opts.httpClient()
+	result := make(map[string]*git.PullRequest)
+
+	for _, branch := range branches {
+		query := url.Values{}
+		query.Set("state", "all")
+		query.Set("head", g.owner+":"+branch)
+		query.Set("per_page", "100")
+		endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls?%s",
+			g.baseURL, url.PathEscape(g.owner), url.PathEscape(g.repo), query.Encode())
+
+		var pulls []githubPullRequest
+		if err := getJSON(client, endpoint, g.headers(), &pulls); err != nil {
+			return nil, fmt.Errorf("failed to look up pull requests for %s: %w", branch, err)
+		}
+
+		if pr := pickGitHubPullRequest(pulls); pr != nil {
+			result[branch] = pr
+		}
+	}
+
+	return result, nil
+}
+
+func (g *GitHub) headers() map[string]string {
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	if g.opts.Token != "" {
+		headers["Authorization"] = "Bearer " + g.opts.Token
+	}
+	return headers
+}
+
+// pickGitHubPullRequest chooses the pull request that decides a branch's fate:
+// an open one always wins, otherwise the most recent (GitHub lists newest first)
+func pickGitHubPullRequest(pulls []githubPullRequest) *git.PullRequest {
+	var chosen *githubPullRequest
+	for i := range pulls {
+		if pulls[i].State == "open" {
+			chosen = &pulls[i]
+			break
+		}
+		if chosen == nil {
+			chosen = &pulls[i]
+		}
+	}
+
+	if chosen == nil {
+		return nil
+	}
+
+	state := git.PullRequestClosed
+	switch {
+	case chosen.State == "open":
+		state = git.PullRequestOpen
+	case chosen.MergedAt != nil:
+		state = git.PullRequestMerged
+	}
+
+	return &git.PullRequest{
+		Number: chosen.Number,
+		State:  state,
+		URL:    chosen.HTMLURL,
+		Label:  fmt.Sprintf("PR #%d", chosen.Number),
+	}
+}
+
+type githubBranch struct {
+	Name string `json:"name"`
+}
+
+type githubRuleset struct {
+	ID          int    `json:"id"`
+	Target      string `json:"target"`
+	Enforcement string `json:"enforcement"`
+	Conditions  struct {
+		RefName struct {
+			Include []string `json:"include"`
+		} `json:"ref_name"`
+	} `json:"conditions"`
+	Rules []struct {
+		Type string `json:"type"`
+	} `json:"rules"`
+}
+
+// ProtectionRules combines classic branch protection with repository
+// rulesets that restrict deletion. GitHub expands classic wildcard rules to
+// the matching branch names, while ruleset patterns are returned as-is.
+func (g *GitHub) ProtectionRules() ([]string, error) {
+	client := g.opts.httpClient()
+	repoURL := fmt.Sprintf("%s/repos/%s/%s", g.baseURL, url.PathEscape(g.owner), url.PathEscape(g.repo))
+
+	var patterns []string
+
+	// Classic branch protection
+	for page := 1; ; page++ {
+		var branches []githubBranch
+		endpoint := fmt.Sprintf("%s/branches?protected=true&per_page=100&page=%d", repoURL, page)
+		if err := getJSON(client, endpoint, g.headers(), &branches); err != nil {
+			return nil, fmt.Errorf("failed to list protected branches: %w", err)
+		}
+
+		for _, branch := range branches {
+			patterns = append(patterns, branch.Name)
+		}
+		if len(branches) < 100 {
+			break
+		}
+	}
+
+	// Repository rulesets (not available on older GitHub Enterprise servers)
+	var rulesets []githubRuleset
+	if err := getJSON(client, repoURL+"/rulesets?includes_parents=true&per_page=100", g.headers(), &rulesets); err != nil {
+		if isNotFound(err) {
+			return patterns, nil
+		}
+		return nil, fmt.Errorf("failed to list rulesets: %w", err)
+	}
+
+	defaultBranch := ""
+	for _, summary := range rulesets {
+		if (summary.Target != "" && summary.Target != "branch") || summary.Enforcement != "active" {
+			continue
+		}
+
+		// The list endpoint omits conditions and rules, so fetch the details
+		var ruleset githubRuleset
+		if err := getJSON(client, fmt.Sprintf("%s/rulesets/%d", repoURL, summary.ID), g.headers(), &ruleset); err != nil {
+			return nil, fmt.Errorf("failed to fetch ruleset %d: %w", summary.ID, err)
+		}
+
+		restrictsDeletion := false
+		for _, rule := range ruleset.Rules {
+			if rule.Type == "deletion" {
+				restrictsDeletion = true
+			}
+		}
+		if !restrictsDeletion {
+			continue
+		}
+
+		for _, include := range ruleset.Conditions.RefName.Include {
+			switch include {
+			case "~ALL":
+				patterns = append(patterns, "*")
+			case "~DEFAULT_BRANCH":
+				if defaultBranch == "" {
+					var repo struct {
+						DefaultBranch string `json:"default_branch"`
+					}
+					if err := getJSON(client, repoURL, g.headers(), &repo); err != nil {
+						return nil, fmt.Errorf("failed to look up default branch: %w", err)
+					}
+					defaultBranch = repo.DefaultBranch
+				}
+				patterns = append(patterns, defaultBranch)
+			default:
+				patterns = append(patterns, strings.TrimPrefix(include, "refs/heads/"))
+			}
+		}
+	}
+
+	return patterns, nil
+}
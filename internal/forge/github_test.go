@@ -0,0 +1,256 @@
+package forge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+// newGitHubServer starts a stand-in for the GitHub pulls API that serves
+// the given pull requests keyed by "owner:branch"
+func newGitHubServer(t *testing.T, pulls map[string][]map[string]any) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/kriscoleman/bonsai/pulls" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization header = %q, want %q", got, "Bearer secret")
+		}
+		if got := r.URL.Query().Get("state"); got != "all" {
+			t.Errorf("state = %q, want all", got)
+		}
+
+		result := pulls[r.URL.Query().Get("head")]
+		if result == nil {
+			result = []map[string]any{}
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewGitHub_BaseURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		remoteURL string
+		baseURL   string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "github.com uses the public API",
+			remoteURL: "git@github.com:kriscoleman/bonsai.git",
+			want:      "https://api.github.com",
+		},
+		{
+			name:      "enterprise host is derived from the remote",
+			remoteURL: "https://github.example.com/team/app.git",
+			want:      "https://github.example.com/api/v3",
+		},
+		{
+			name:      "configured base URL wins",
+			remoteURL: "https://github.example.com/team/app.git",
+			baseURL:   "https://ghe.example.com/api/v3/",
+			want:      "https://ghe.example.com/api/v3",
+		},
+		{
+			name:      "nested paths are not GitHub repositories",
+			remoteURL: "https://gitlab.com/group/sub/project.git",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh, err := NewGitHub(tt.remoteURL, Options{BaseURL: tt.baseURL})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewGitHub() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if gh.baseURL != tt.want {
+				t.Errorf("baseURL = %s, want %s", gh.baseURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHub_PullRequests(t *testing.T) {
+	mergedAt := "2024-01-15T10:30:00Z"
+	server := newGitHubServer(t, map[string][]map[string]any{
+		"kriscoleman:feature/merged": {
+			{"number": 12, "state": "closed", "merged_at": mergedAt, "html_url": "https://github.com/kriscoleman/bonsai/pull/12"},
+		},
+		"kriscoleman:feature/closed": {
+			{"number": 13, "state": "closed", "merged_at": nil},
+		},
+		"kriscoleman:feature/reopened": {
+			{"number": 15, "state": "closed", "merged_at": nil},
+			{"number": 14, "state": "open", "merged_at": nil},
+		},
+	})
+
+	gh, err := NewGitHub("https://github.com/kriscoleman/bonsai.git", Options{
+		Token:   "secret",
+		BaseURL: server.URL,
+		Client:  server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewGitHub() error = %v", err)
+	}
+
+	prs, err := gh.PullRequests([]string{"feature/merged", "feature/closed", "feature/reopened", "feature/none"})
+	if err != nil {
+		t.Fatalf("PullRequests() error = %v", err)
+	}
+
+	tests := []struct {
+		branch     string
+		wantNumber int
+		wantState  git.PullRequestState
+	}{
+		{branch: "feature/merged", wantNumber: 12, wantState: git.PullRequestMerged},
+		{branch: "feature/closed", wantNumber: 13, wantState: git.PullRequestClosed},
+		{branch: "feature/reopened", wantNumber: 14, wantState: git.PullRequestOpen},
+	}
+
+	for _, tt := range tests {
+		pr := prs[tt.branch]
+		if pr == nil {
+			t.Errorf("No pull request found for %s", tt.branch)
+			continue
+		}
+		if pr.Number != tt.wantNumber || pr.State != tt.wantState {
+			t.Errorf("%s: got %s, want #%d %s", tt.branch, pr, tt.wantNumber, tt.wantState)
+		}
+	}
+
+	if pr, ok := prs["feature/none"]; ok {
+		t.Errorf("feature/none should have no pull request, got %s", pr)
+	}
+
+	if got := prs["feature/merged"].String(); got != "PR #12 merged" {
+		t.Errorf("String() = %q, want %q", got, "PR #12 merged")
+	}
+}
+
+func TestGitHub_PullRequests_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	gh, err := NewGitHub("https://github.com/kriscoleman/bonsai.git", Options{
+		BaseURL: server.URL,
+		Client:  server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewGitHub() error = %v", err)
+	}
+
+	if _, err := gh.PullRequests([]string{"feature/test"}); err == nil {
+		t.Error("PullRequests() should fail when the API rejects the request")
+	}
+}
+
+func TestGitHub_ProtectionRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result any
+		switch r.URL.Path {
+		case "/repos/kriscoleman/bonsai":
+			result = map[string]any{"default_branch": "trunk"}
+		case "/repos/kriscoleman/bonsai/branches":
+			if r.URL.Query().Get("protected") != "true" {
+				t.Errorf("protected = %q, want true", r.URL.Query().Get("protected"))
+			}
+			result = []map[string]any{{"name": "main"}, {"name": "release/1.0"}}
+		case "/repos/kriscoleman/bonsai/rulesets":
+			result = []map[string]any{
+				{"id": 1, "target": "branch", "enforcement": "active"},
+				{"id": 2, "target": "branch", "enforcement": "disabled"},
+				{"id": 3, "target": "tag", "enforcement": "active"},
+				{"id": 4, "target": "branch", "enforcement": "active"},
+			}
+		case "/repos/kriscoleman/bonsai/rulesets/1":
+			result = map[string]any{
+				"id": 1, "target": "branch", "enforcement": "active",
+				"conditions": map[string]any{"ref_name": map[string]any{
+					"include": []string{"refs/heads/hotfix/*", "~DEFAULT_BRANCH"},
+				}},
+				"rules": []map[string]any{{"type": "deletion"}, {"type": "non_fast_forward"}},
+			}
+		case "/repos/kriscoleman/bonsai/rulesets/4":
+			// Only blocks force pushes, so deletion is still allowed
+			result = map[string]any{
+				"id": 4, "target": "branch", "enforcement": "active",
+				"conditions": map[string]any{"ref_name": map[string]any{
+					"include": []string{"refs/heads/experiment/*"},
+				}},
+				"rules": []map[string]any{{"type": "non_fast_forward"}},
+			}
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	gh, err := NewGitHub("git@github.com:kriscoleman/bonsai.git", Options{
+		BaseURL: server.URL,
+		Client:  server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewGitHub() error = %v", err)
+	}
+
+	patterns, err := gh.ProtectionRules()
+	if err != nil {
+		t.Fatalf("ProtectionRules() error = %v", err)
+	}
+
+	want := []string{"main", "release/1.0", "hotfix/*", "trunk"}
+	if len(patterns) != len(want) {
+		t.Fatalf("ProtectionRules() = %v, want %v", patterns, want)
+	}
+	for i := range want {
+		if patterns[i] != want[i] {
+			t.Errorf("ProtectionRules()[%d] = %s, want %s", i, patterns[i], want[i])
+		}
+	}
+}
+
+func TestGitHub_ProtectionRules_NoRulesets(t *testing.T) {
+	// Older GitHub Enterprise servers have no rulesets endpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/team/app/branches" {
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"name": "main"}})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	gh, err := NewGitHub("https://github.example.com/team/app.git", Options{
+		BaseURL: server.URL,
+		Client:  server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewGitHub() error = %v", err)
+	}
+
+	patterns, err := gh.ProtectionRules()
+	if err != nil {
+		t.Fatalf("ProtectionRules() error = %v", err)
+	}
+	if len(patterns) != 1 || patterns[0] != "main" {
+		t.Errorf("ProtectionRules() = %v, want [main]", patterns)
+	}
+}
@@ -0,0 +1,148 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+// GitLab looks up merge requests on gitlab.com or a self-hosted GitLab
+type GitLab struct {
+	project string
+	baseURL string
+	opts    Options
+}
+
+// NewGitLab creates a GitLab provider for the project behind remoteURL.
+// When no base URL is configured it is derived from the remote's host, so
+// self-hosted instances work without extra configuration.
+func NewGitLab(remoteURL string, opts Options) (*GitLab, error) {
+	info, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.Contains(info.Path, "/") {
+		return nil, fmt.Errorf("remote %q is not a GitLab project URL", remoteURL)
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("%s://%s/api/v4", info.Scheme, info.Host)
+	}
+
+	return &GitLab{
+		project: info.Path,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		opts:    opts,
+	}, nil
+}
+
+// Name returns the platform name
+func (g *GitLab) Name() string {
+	return "GitLab"
+}
+
+type gitlabMergeRequest struct {
+	IID    int    `json:"iid"`
+	State  string `json:"state"`
+	WebURL string `json:"web_url"`
+}
+
+// PullRequests looks up the merge requests whose source is each branch
+func (g *GitLab) PullRequests(branches []string) (map[string]*git.PullRequest, error) {
+	client := g.opts.httpClient()
+	result := make(map[string]*git.PullRequest)
+
+	for _, branch := range branches {
+		query := url.Values{}
+		query.Set("source_branch", branch)
+		query.Set("state", "all")
+		query.Set("per_page", "100")
+		endpoint := fmt.Sprintf("%s/projects/%s/merge_requests?%s",
+			g.baseURL, url.PathEscape(g.project), query.Encode())
+
+		var mergeRequests []gitlabMergeRequest
+		if err := getJSON(client, endpoint, g.headers(), &mergeRequests); err != nil {
+			return nil, fmt.Errorf("failed to look up merge requests for %s: %w", branch, err)
+		}
+
+		if mr := pickGitLabMergeRequest(mergeRequests); mr != nil {
+			result[branch] = mr
+		}
+	}
+
+	return result, nil
+}
+
+func (g *GitLab) headers() map[string]string {
+	headers := map[string]string{
+		"Accept": "application/json",
+	}
+	if g.opts.Token != "" {
+		headers["PRIVATE-TOKEN"] = g.opts.Token
+	}
+	return headers
+}
+
+// pickGitLabMergeRequest chooses the merge request that decides a branch's
+// fate: an open one always wins, otherwise the most recent (listed first)
+func pickGitLabMergeRequest(mergeRequests []gitlabMergeRequest) *git.PullRequest {
+	var chosen *gitlabMergeRequest
+	for i := range mergeRequests {
+		if mergeRequests[i].State == "opened" || mergeRequests[i].State == "locked" {
+			chosen = &mergeRequests[i]
+			break
+		}
+		if chosen == nil {
+			chosen = &mergeRequests[i]
+		}
+	}
+
+	if chosen == nil {
+		return nil
+	}
+
+	state := git.PullRequestClosed
+	switch chosen.State {
+	case "opened", "locked":
+		state = git.PullRequestOpen
+	case "merged":
+		state = git.PullRequestMerged
+	}
+
+	return &git.PullRequest{
+		Number: chosen.IID,
+		State:  state,
+		URL:    chosen.WebURL,
+		Label:  fmt.Sprintf("MR !%d", chosen.IID),
+	}
+}
+
+// ProtectionRules returns the project's protected branch names and wildcards
+func (g *GitLab) ProtectionRules() ([]string, error) {
+	client := g.opts.httpClient()
+
+	var patterns []string
+	for page := 1; ; page++ {
+		var protected []struct {
+			Name string `json:"name"`
+		}
+		endpoint := fmt.Sprintf("%s/projects/%s/protected_branches?per_page=100&page=%d",
+			g.baseURL, url.PathEscape(g.project), page)
+		if err := getJSON(client, endpoint, g.headers(), &protected); err != nil {
+			return nil, fmt.Errorf("failed to list protected branches: %w", err)
+		}
+
+		for _, branch := range protected {
+			patterns = append(patterns, branch.Name)
+		}
+		if len(protected) < 100 {
+			break
+		}
+	}
+
+	return patterns, nil
+}
@@ -0,0 +1,168 @@
+package forge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+func TestNewGitLab_BaseURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		remoteURL string
+		baseURL   string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "gitlab.com",
+			remoteURL: "git@gitlab.com:group/project.git",
+			want:      "https://gitlab.com/api/v4",
+		},
+		{
+			name:      "self-hosted with custom hostname",
+			remoteURL: "ssh://git@code.example.org:2222/platform/tools/bonsai.git",
+			want:      "https://code.example.org/api/v4",
+		},
+		{
+			name:      "plain http instance keeps its port",
+			remoteURL: "http://gitlab.internal:8080/team/app.git",
+			want:      "http://gitlab.internal:8080/api/v4",
+		},
+		{
+			name:      "configured base URL wins",
+			remoteURL: "git@code.example.org:team/app.git",
+			baseURL:   "https://gitlab-api.example.org/api/v4/",
+			want:      "https://gitlab-api.example.org/api/v4",
+		},
+		{
+			name:      "project path is required",
+			remoteURL: "https://gitlab.com/project",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gl, err := NewGitLab(tt.remoteURL, Options{BaseURL: tt.baseURL})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewGitLab() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if gl.baseURL != tt.want {
+				t.Errorf("baseURL = %s, want %s", gl.baseURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitLab_PullRequests(t *testing.T) {
+	mergeRequests := map[string][]map[string]any{
+		"feature/merged": {
+			{"iid": 7, "state": "merged", "web_url": "https://code.example.org/platform/tools/bonsai/-/merge_requests/7"},
+		},
+		"feature/closed": {
+			{"iid": 8, "state": "closed"},
+		},
+		"feature/reopened": {
+			{"iid": 10, "state": "closed"},
+			{"iid": 9, "state": "opened"},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The project path must arrive URL-encoded as a single segment
+		if r.URL.EscapedPath() != "/api/v4/projects/platform%2Ftools%2Fbonsai/merge_requests" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret" {
+			t.Errorf("PRIVATE-TOKEN header = %q, want %q", got, "secret")
+		}
+
+		result := mergeRequests[r.URL.Query().Get("source_branch")]
+		if result == nil {
+			result = []map[string]any{}
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	gl, err := NewGitLab("git@code.example.org:platform/tools/bonsai.git", Options{
+		Token:   "secret",
+		BaseURL: server.URL + "/api/v4",
+		Client:  server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewGitLab() error = %v", err)
+	}
+
+	prs, err := gl.PullRequests([]string{"feature/merged", "feature/closed", "feature/reopened", "feature/none"})
+	if err != nil {
+		t.Fatalf("PullRequests() error = %v", err)
+	}
+
+	tests := []struct {
+		branch     string
+		wantNumber int
+		wantState  git.PullRequestState
+	}{
+		{branch: "feature/merged", wantNumber: 7, wantState: git.PullRequestMerged},
+		{branch: "feature/closed", wantNumber: 8, wantState: git.PullRequestClosed},
+		{branch: "feature/reopened", wantNumber: 9, wantState: git.PullRequestOpen},
+	}
+
+	for _, tt := range tests {
+		mr := prs[tt.branch]
+		if mr == nil {
+			t.Errorf("No merge request found for %s", tt.branch)
+			continue
+		}
+		if mr.Number != tt.wantNumber || mr.State != tt.wantState {
+			t.Errorf("%s: got %s, want !%d %s", tt.branch, mr, tt.wantNumber, tt.wantState)
+		}
+	}
+
+	if mr, ok := prs["feature/none"]; ok {
+		t.Errorf("feature/none should have no merge request, got %s", mr)
+	}
+
+	if got := prs["feature/merged"].String(); got != "MR !7 merged" {
+		t.Errorf("String() = %q, want %q", got, "MR !7 merged")
+	}
+}
+
+func TestGitLab_ProtectionRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/protected_branches" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"name": "main"},
+			{"name": "release/*"},
+		})
+	}))
+	defer server.Close()
+
+	gl, err := NewGitLab("https://gitlab.internal/group/project.git", Options{
+		BaseURL: server.URL + "/api/v4",
+		Client:  server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewGitLab() error = %v", err)
+	}
+
+	patterns, err := gl.ProtectionRules()
+	if err != nil {
+		t.Fatalf("ProtectionRules() error = %v", err)
+	}
+	if len(patterns) != 2 || patterns[0] != "main" || patterns[1] != "release/*" {
+		t.Errorf("ProtectionRules() = %v, want [main release/*]", patterns)
+	}
+}
@@ -0,0 +1,51 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// protectionCache is the on-disk copy of a forge's protection rules
+type protectionCache struct {
+	Provider  string    `json:"provider"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Patterns  []string  `json:"patterns"`
+}
+
+// CachedProtectionRules returns the provider's protection rules, reusing the
+// copy stored at path while it is younger than ttl. Fresh rules are written
+// back to path. A ttl of zero always fetches.
+func CachedProtectionRules(provider Provider, path string, ttl time.Duration) ([]string, error) {
+	if ttl > 0 {
+		if data, err := os.ReadFile(path); err == nil {
+			var cache protectionCache
+			if err := json.Unmarshal(data, &cache); err == nil &&
+				cache.Provider == provider.Name() &&
+				time.Since(cache.FetchedAt) < ttl {
+				return cache.Patterns, nil
+			}
+		}
+	}
+
+	patterns, err := provider.ProtectionRules()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(protectionCache{
+		Provider:  provider.Name(),
+		FetchedAt: time.Now(),
+		Patterns:  patterns,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to cache protection rules: %w", err)
+	}
+
+	return patterns, nil
+}
@@ -0,0 +1,59 @@
+package forge
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+// fakeProvider counts protection rule lookups
+type fakeProvider struct {
+	patterns []string
+	calls    int
+}
+
+func (f *fakeProvider) Name() string { return "Fake" }
+
+func (f *fakeProvider) PullRequests(branches []string) (map[string]*git.PullRequest, error) {
+	return nil, nil
+}
+
+func (f *fakeProvider) ProtectionRules() ([]string, error) {
+	f.calls++
+	return f.patterns, nil
+}
+
+func TestCachedProtectionRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "protection-origin.json")
+	provider := &fakeProvider{patterns: []string{"main", "release/*"}}
+
+	// First lookup hits the provider and fills the cache
+	patterns, err := CachedProtectionRules(provider, path, time.Hour)
+	if err != nil {
+		t.Fatalf("CachedProtectionRules() error = %v", err)
+	}
+	if len(patterns) != 2 || provider.calls != 1 {
+		t.Fatalf("got %v after %d calls, want 2 patterns after 1 call", patterns, provider.calls)
+	}
+
+	// Second lookup is served from the cache
+	provider.patterns = []string{"changed"}
+	patterns, err = CachedProtectionRules(provider, path, time.Hour)
+	if err != nil {
+		t.Fatalf("CachedProtectionRules() error = %v", err)
+	}
+	if provider.calls != 1 || len(patterns) != 2 {
+		t.Errorf("cache was not used: %d calls, patterns %v", provider.calls, patterns)
+	}
+
+	// A zero TTL bypasses the cache
+	patterns, err = CachedProtectionRules(provider, path, 0)
+	if err != nil {
+		t.Fatalf("CachedProtectionRules() error = %v", err)
+	}
+	if provider.calls != 2 || len(patterns) != 1 || patterns[0] != "changed" {
+		t.Errorf("expected a fresh lookup, got %d calls and %v", provider.calls, patterns)
+	}
+}
@@ -0,0 +1,217 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AgeBasis is what a branch's age is counted from
+type AgeBasis string
+
+const (
+	AgeByCommitter    AgeBasis = "committer"     // Committer date of the tip, the default
+	AgeByAuthor       AgeBasis = "author"        // Author date of the tip, unchanged by rebases
+	AgeByLastCheckout AgeBasis = "last-checkout" // Last checkout of the branch, from the HEAD reflog
+	AgeByCreation     AgeBasis = "created"       // Creation of the branch, from its reflog
+)
+
+// AgeBases lists every age basis
+var AgeBases = []AgeBasis{AgeByCommitter, AgeByAuthor, AgeByLastCheckout, AgeByCreation}
+
+// ParseAgeBasis parses an age basis; an empty string is the default
+func ParseAgeBasis(s string) (AgeBasis, error) {
+	if s == "" {
+		return AgeByCommitter, nil
+	}
+	for _, basis := range AgeBases {
+		if string(basis) == s {
+			return basis, nil
+		}
+	}
+	return "", fmt.Errorf("unknown age basis: %s (expected committer, author, last-checkout or created)", s)
+}
+
+// Describe returns what the age is counted from, e.g. "last checkout"
+func (b AgeBasis) Describe() string {
+	switch b {
+	case AgeByAuthor:
+		return "last authored commit"
+	case AgeByLastCheckout:
+		return "last checkout"
+	case AgeByCreation:
+		return "creation"
+	}
+	return "last commit"
+}
+
+// reflogEntry is one line of a reflog
+type reflogEntry struct {
+	oldCommit string
+	at        time.Time
+	message   string
+}
+
+// created reports whether the entry is the one that created the ref
+func (e reflogEntry) created() bool {
+	return strings.Trim(e.oldCommit, "0") == ""
+}
+
+// ApplyAgeBasis sets what the age of each branch is counted from. Reflogs
+// expire, and remote branches are never checked out, so when the reflog
+// can't tell, the age falls back to the tip's committer date.
+func (r *Repository) ApplyAgeBasis(branches []*Branch, basis AgeBasis) error {
+	var checkouts map[string]time.Time
+	var refLogs string
+	switch basis {
+	case AgeByLastCheckout:
+		path, err := r.gitPath("logs/HEAD")
+		if err != nil {
+			return err
+		}
+		entries, err := readReflog(path)
+		if err != nil {
+			return err
+		}
+		checkouts = lastCheckouts(entries)
+
+	case AgeByCreation:
+		path, err := r.gitPath("logs/refs")
+		if err != nil {
+			return err
+		}
+		refLogs = path
+	}
+
+	for _, branch := range branches {
+		branch.AgeFrom = time.Time{}
+
+		switch basis {
+		case AgeByAuthor:
+			branch.AgeFrom = branch.AuthoredAt
+
+		case AgeByLastCheckout:
+			switch {
+			case branch.IsCurrent:
+				branch.AgeFrom = time.Now()
+			case !branch.IsRemote:
+				// Committing is activity too, so the last checkout never
+				// makes a branch look older than its last commit
+				if checkout := checkouts[branch.Name]; checkout.After(branch.LastCommitAt) {
+					branch.AgeFrom = checkout
+				}
+			}
+
+		case AgeByCreation:
+			entries, err := readReflog(filepath.Join(refLogs, strings.TrimPrefix(branch.Ref(), "refs/")))
+			if err != nil {
+				return err
+			}
+			branch.AgeFrom = creationTime(entries, branch.LastCommitAt)
+		}
+	}
+
+	return nil
+}
+
+// lastCheckouts returns when each branch was last checked out or left,
+// according to the HEAD reflog. Leaving a branch means it was in use until then.
+func lastCheckouts(entries []reflogEntry) map[string]time.Time {
+	checkouts := map[string]time.Time{}
+	for _, entry := range entries {
+		message, ok := strings.CutPrefix(entry.message, "checkout: moving from ")
+		if !ok {
+			continue
+		}
+		// Branch names can't contain spaces: the first word is the branch
+		// left and the last one the branch checked out
+		left := message[:strings.Index(message+" ", " ")]
+		target := message[strings.LastIndex(message, " ")+1:]
+		for _, name := range []string{left, target} {
+			if entry.at.After(checkouts[name]) {
+				checkouts[name] = entry.at
+			}
+		}
+	}
+	return checkouts
+}
+
+// creationTime returns when a branch was created according to its reflog.
+// Without the entry that created it, the branch is at least as old as both
+// the oldest entry left and its tip commit.
+func creationTime(entries []reflogEntry, lastCommitAt time.Time) time.Time {
+	if len(entries) == 0 {
+		return time.Time{}
+	}
+
+	oldest := entries[0]
+	if oldest.created() || oldest.at.Before(lastCommitAt) {
+		return oldest.at
+	}
+	return time.Time{}
+}
+
+// gitPath returns the absolute path of a file in the git directory
+func (r *Repository) gitPath(name string) (string, error) {
+	path, err := r.output("rev-parse", "--path-format=absolute", "--git-path", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate %s: %w", name, err)
+	}
+	return filepath.Clean(strings.TrimSpace(path)), nil
+}
+
+// readReflog reads the reflog at path, oldest entry first. A missing reflog
+// has no entries.
+func readReflog(path string) ([]reflogEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reflog: %w", err)
+	}
+	defer file.Close()
+
+	var entries []reflogEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if entry, ok := parseReflogEntry(scanner.Text()); ok {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reflog: %w", err)
+	}
+	return entries, nil
+}
+
+// parseReflogEntry parses a reflog line:
+// <old> <new> <name> <<email>> <timestamp> <timezone>\t<message>
+func parseReflogEntry(line string) (reflogEntry, bool) {
+	header, message, _ := strings.Cut(line, "\t")
+
+	oldCommit, _, ok := strings.Cut(header, " ")
+	if !ok {
+		return reflogEntry{}, false
+	}
+
+	// The name may contain spaces, but the email ends with the last '>'
+	end := strings.LastIndex(header, ">")
+	if end < 0 {
+		return reflogEntry{}, false
+	}
+	fields := strings.Fields(header[end+1:])
+	if len(fields) != 2 {
+		return reflogEntry{}, false
+	}
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return reflogEntry{}, false
+	}
+
+	return reflogEntry{oldCommit: oldCommit, at: time.Unix(seconds, 0), message: message}, true
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+const zeroCommit = "0000000000000000000000000000000000000000"
+
+func TestParseAgeBasis(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    AgeBasis
+		wantErr bool
+	}{
+		{"", AgeByCommitter, false},
+		{"committer", AgeByCommitter, false},
+		{"author", AgeByAuthor, false},
+		{"last-checkout", AgeByLastCheckout, false},
+		{"created", AgeByCreation, false},
+		{"reflog", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAgeBasis(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAgeBasis(%q) = %q, %v, want %q (error: %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseReflogEntry(t *testing.T) {
+	line := zeroCommit + " 1f2e3d4c5b6a79881f2e3d4c5b6a79881f2e3d4c Jane Q. Doe <jane@example.com> 1700000000 -0800\tbranch: Created from HEAD"
+
+	entry, ok := parseReflogEntry(line)
+	if !ok {
+		t.Fatal("parseReflogEntry() failed")
+	}
+	if !entry.created() || !entry.at.Equal(time.Unix(1700000000, 0)) || entry.message != "branch: Created from HEAD" {
+		t.Errorf("parseReflogEntry() = %+v", entry)
+	}
+
+	if _, ok := parseReflogEntry("garbage"); ok {
+		t.Error("parseReflogEntry() accepted a malformed line")
+	}
+}
+
+func TestLastCheckouts(t *testing.T) {
+	at := func(days int) time.Time { return time.Unix(1700000000, 0).AddDate(0, 0, days) }
+	entries := []reflogEntry{
+		{at: at(0), message: "checkout: moving from main to feature/a"},
+		{at: at(1), message: "commit: Add things"},
+		{at: at(2), message: "checkout: moving from feature/a to main"},
+		{at: at(3), message: "checkout: moving from main to feature/a"},
+		{at: at(4), message: "checkout: moving from feature/a to feature/b"},
+	}
+
+	// Leaving a branch counts as much as checking it out
+	checkouts := lastCheckouts(entries)
+	if !checkouts["feature/a"].Equal(at(4)) || !checkouts["main"].Equal(at(3)) || !checkouts["feature/b"].Equal(at(4)) || len(checkouts) != 3 {
+		t.Errorf("lastCheckouts() = %v", checkouts)
+	}
+}
+
+func TestCreationTime(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	lastCommit := created.Add(48 * time.Hour)
+
+	tests := []struct {
+		name    string
+		entries []reflogEntry
+		want    time.Time
+	}{
+		{"no reflog", nil, time.Time{}},
+		{"creation entry", []reflogEntry{{oldCommit: zeroCommit, at: created}}, created},
+		{"expired, oldest entry after the tip", []reflogEntry{{oldCommit: "1f2e3d", at: lastCommit.Add(time.Hour)}}, time.Time{}},
+		{"expired, oldest entry before the tip", []reflogEntry{{oldCommit: "1f2e3d", at: created}}, created},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := creationTime(tt.entries, lastCommit); !got.Equal(tt.want) {
+				t.Errorf("creationTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranch_ActiveAt(t *testing.T) {
+	lastCommit := time.Now().Add(-30 * 24 * time.Hour)
+	b := &Branch{LastCommitAt: lastCommit}
+	if !b.ActiveAt().Equal(lastCommit) {
+		t.Errorf("ActiveAt() = %v, want the last commit", b.ActiveAt())
+	}
+
+	b.AgeFrom = time.Now().Add(-time.Hour)
+	if b.IsStale(24 * time.Hour) {
+		t.Error("IsStale() ignored AgeFrom")
+	}
+}
+
+func TestParseBranches_AuthorDate(t *testing.T) {
+	output := []byte("feature/rebased|2024-03-01 10:00:00 +0000|2023-06-01 10:00:00 +0000|Rebase me|Jane Smith\n")
+
+	branches, err := parseBranches(output, false, "")
+	if err != nil || len(branches) != 1 {
+		t.Fatalf("parseBranches() = %v, %v", branches, err)
+	}
+	if want := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC); !branches[0].AuthoredAt.Equal(want) {
+		t.Errorf("AuthoredAt = %v, want %v", branches[0].AuthoredAt, want)
+	}
+}
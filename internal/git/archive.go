@@ -0,0 +1,182 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ArchivePrefix is the tag namespace archived branches are kept under
+const ArchivePrefix = "archive/"
+
+// Archive describes a branch preserved as an annotated tag
+type Archive struct {
+	Tag        string
+	Branch     string
+	Remote     string // Empty for local branches
+	Commit     string
+	Author     string
+	ArchivedAt time.Time
+}
+
+// FullName returns the original branch name (with remote prefix if applicable)
+func (a *Archive) FullName() string {
+	if a.Remote != "" {
+		return a.Remote + "/" + a.Branch
+	}
+	return a.Branch
+}
+
+// ArchiveBranch preserves a branch as an annotated archive/<branch> tag whose
+// message records the original name, tip commit, author and archive date.
+// It returns the name of the created tag.
+func (r *Repository) ArchiveBranch(branch *Branch) (string, error) {
+	commit, err := r.ResolveCommit(branch.Ref())
+	if err != nil {
+		return "", err
+	}
+
+	// Never overwrite an earlier archive of a branch with the same name
+	tag := ArchivePrefix + branch.Name
+	for i := 2; r.tagExists(tag); i++ {
+		tag = fmt.Sprintf("%s%s-%d", ArchivePrefix, branch.Name, i)
+	}
+
+	message := fmt.Sprintf("Archived branch %s\n\nBranch: %s\n", branch.FullName(), branch.Name)
+	if branch.IsRemote {
+		message += fmt.Sprintf("Remote: %s\n", branch.RemoteName)
+	}
+	message += fmt.Sprintf("Commit: %s\nAuthor: %s\nArchived: %s\n",
+		commit, branch.LastAuthor, time.Now().Format(time.RFC3339))
+
+	cmd := exec.Command("git", "tag", "--annotate", "--message", message, tag, commit)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return "", fmt.Errorf("%s", errorMsg)
+	}
+
+	return tag, nil
+}
+
+// PushTag publishes a tag to a remote
+func (r *Repository) PushTag(remote, tag string) error {
+	cmd := exec.Command("git", "push", remote, "refs/tags/"+tag)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// ListArchives returns all branches archived as archive/* tags
+func (r *Repository) ListArchives() ([]*Archive, error) {
+	// Records are separated by 0x1e and fields by 0x1f since tag bodies span lines
+	format := "%(refname:strip=2)%1f%(*objectname)%1f%(contents:body)%1e"
+	cmd := exec.Command("git", "for-each-ref", "--format="+format, "refs/tags/"+ArchivePrefix)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+
+	return parseArchives(output), nil
+}
+
+// parseArchives parses the output of the for-each-ref call in ListArchives
+func parseArchives(output []byte) []*Archive {
+	var archives []*Archive
+
+	for _, record := range bytes.Split(output, []byte{0x1e}) {
+		fields := strings.Split(strings.TrimSpace(string(record)), "\x1f")
+		if len(fields) != 3 || fields[0] == "" {
+			continue
+		}
+
+		archive := &Archive{
+			Tag:    fields[0],
+			Branch: strings.TrimPrefix(fields[0], ArchivePrefix),
+			Commit: fields[1],
+		}
+
+		// Lightweight tags have no peeled object
+		if archive.Commit == "" {
+			continue
+		}
+
+		scanner := bufio.NewScanner(strings.NewReader(fields[2]))
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), ": ")
+			if !ok {
+				continue
+			}
+
+			switch key {
+			case "Branch":
+				archive.Branch = value
+			case "Remote":
+				archive.Remote = value
+			case "Author":
+				archive.Author = value
+			case "Archived":
+				if t, err := time.Parse(time.RFC3339, value); err == nil {
+					archive.ArchivedAt = t
+				}
+			}
+		}
+
+		archives = append(archives, archive)
+	}
+
+	return archives
+}
+
+// RestoreArchive recreates a local branch from an archive tag
+func (r *Repository) RestoreArchive(archive *Archive, branchName string) error {
+	cmd := exec.Command("git", "branch", branchName, archive.Commit)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// tagExists checks whether a tag with the given name exists
+func (r *Repository) tagExists(tag string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/tags/"+tag)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+	return cmd.Run() == nil
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseArchives(t *testing.T) {
+	const commit = "621fd4ee4e7815d2729e61c1c565fd9668a40ff4"
+
+	output := "archive/feature/login\x1f" + commit + "\x1f" +
+		"Branch: feature/login\nRemote: origin\nCommit: " + commit +
+		"\nAuthor: Jane Doe\nArchived: 2024-03-01T10:00:00Z\n\x1e\n" +
+		"archive/lightweight\x1f\x1f\x1e\n" +
+		"archive/old-2\x1f" + commit + "\x1f" + "Branch: old\n\x1e\n"
+
+	archives := parseArchives([]byte(output))
+	if len(archives) != 2 {
+		t.Fatalf("parseArchives() returned %d archives, want 2", len(archives))
+	}
+
+	login := archives[0]
+	if login.Tag != "archive/feature/login" {
+		t.Errorf("Tag = %s, want archive/feature/login", login.Tag)
+	}
+	if login.FullName() != "origin/feature/login" {
+		t.Errorf("FullName() = %s, want origin/feature/login", login.FullName())
+	}
+	if login.Commit != commit {
+		t.Errorf("Commit = %s, want %s", login.Commit, commit)
+	}
+	if login.Author != "Jane Doe" {
+		t.Errorf("Author = %s, want Jane Doe", login.Author)
+	}
+	if want := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC); !login.ArchivedAt.Equal(want) {
+		t.Errorf("ArchivedAt = %v, want %v", login.ArchivedAt, want)
+	}
+
+	// The branch name comes from the tag message, not the deduplicated tag
+	if archives[1].Branch != "old" || archives[1].Remote != "" {
+		t.Errorf("archives[1] = %+v, want local branch old", archives[1])
+	}
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -8,17 +9,64 @@ import (
 type Branch struct {
 	Name          string
 	LastCommitAt  time.Time
+	AuthoredAt    time.Time // Author date of the tip
+	AgeFrom       time.Time // What the age is counted from; the last commit when zero
 	LastCommitMsg string
 	LastAuthor    string
 	IsRemote      bool
 	RemoteName    string // e.g., "origin"
 	IsCurrent     bool
 	IsProtected   bool
+	PullRequest   *PullRequest    // Set when a forge provider found a pull request for the branch
+	Reason        CandidateReason // Why the branch was picked for pruning
+	IsPinned      bool            // Kept until unpinned
+	SnoozedUntil  time.Time       // Pruning is held off until then
+	Unpushed      int             // Commits that would be lost with it, see ApplyUnpushed
 }
 
-// Age returns the duration since the last commit
+// PullRequestState is the review state of a pull (or merge) request
+type PullRequestState string
+
+const (
+	PullRequestOpen   PullRequestState = "open"
+	PullRequestMerged PullRequestState = "merged"
+	PullRequestClosed PullRequestState = "closed"
+)
+
+// PullRequest describes the pull request associated with a branch
+type PullRequest struct {
+	Number int
+	State  PullRequestState
+	URL    string
+	Label  string // How the forge refers to it, e.g. "PR #12"
+}
+
+// String returns a short description such as "PR #12 merged"
+func (p *PullRequest) String() string {
+	return fmt.Sprintf("%s %s", p.Label, p.State)
+}
+
+// CandidateReason explains why a branch was picked for pruning
+type CandidateReason string
+
+const (
+	ReasonStale             CandidateReason = "stale"
+	ReasonPullRequestMerged CandidateReason = "pull request merged"
+	ReasonPullRequestClosed CandidateReason = "pull request closed"
+)
+
+// Age returns the duration since the branch was last active: its last
+// commit unless an age basis said otherwise
 func (b *Branch) Age() time.Duration {
-	return time.Since(b.LastCommitAt)
+	return time.Since(b.ActiveAt())
+}
+
+// ActiveAt returns the time the branch's age is counted from
+func (b *Branch) ActiveAt() time.Time {
+	if b.AgeFrom.IsZero() {
+		return b.LastCommitAt
+	}
+	return b.AgeFrom
 }
 
 // IsStale checks if the branch is older than the given threshold
@@ -26,6 +74,22 @@ func (b *Branch) IsStale(threshold time.Duration) bool {
 	return b.Age() > threshold
 }
 
+// IsSnoozed checks if pruning the branch is currently held off
+func (b *Branch) IsSnoozed() bool {
+	return time.Now().Before(b.SnoozedUntil)
+}
+
+// IsHeld checks if the branch is pinned or snoozed
+func (b *Branch) IsHeld() bool {
+	return b.IsPinned || b.IsSnoozed()
+}
+
+// IsAtRisk checks if deleting the branch would lose commits that exist
+// nowhere else
+func (b *Branch) IsAtRisk() bool {
+	return b.Unpushed > 0
+}
+
 // FullName returns the full branch name (with remote prefix if applicable)
 func (b *Branch) FullName() string {
 	if b.IsRemote {
@@ -33,3 +97,11 @@ func (b *Branch) FullName() string {
 	}
 	return b.Name
 }
+
+// Ref returns the fully qualified ref of the branch, e.g. refs/remotes/origin/main
+func (b *Branch) Ref() string {
+	if b.IsRemote {
+		return fmt.Sprintf("refs/remotes/%s/%s", b.RemoteName, b.Name)
+	}
+	return "refs/heads/" + b.Name
+}
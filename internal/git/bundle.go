@@ -0,0 +1,163 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DefaultBranch returns the branch other branches are measured against: the
+// remote's HEAD when it is known, otherwise the first default protected branch
+// that exists locally or on the remote
+func (r *Repository) DefaultBranch(remote string) (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", fmt.Sprintf("refs/remotes/%s/HEAD", remote))
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	if output, err := cmd.Output(); err == nil {
+		if head := strings.TrimSpace(string(output)); head != "" {
+			return head, nil
+		}
+	}
+
+	for _, name := range DefaultProtectedBranches {
+		if _, err := r.ResolveCommit("refs/heads/" + name); err == nil {
+			return name, nil
+		}
+		if _, err := r.ResolveCommit(fmt.Sprintf("refs/remotes/%s/%s", remote, name)); err == nil {
+			return remote + "/" + name, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not determine the base branch; set base_branch in .bonsai.yaml")
+}
+
+// CountUniqueCommits returns the number of commits reachable from ref but not from base
+func (r *Repository) CountUniqueCommits(ref, base string) (int, error) {
+	cmd := exec.Command("git", "rev-list", "--count", ref, "^"+base)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits of %s not in %s: %w", ref, base, err)
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// CreateBundle writes a git bundle containing ref and the commits that are
+// not already reachable from base
+func (r *Repository) CreateBundle(path, ref, base string) error {
+	cmd := exec.Command("git", "bundle", "create", path, ref, "^"+base)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// FetchBundle imports ref from a bundle file as a new local branch
+func (r *Repository) FetchBundle(path, ref, branchName string) error {
+	refspec := fmt.Sprintf("%s:refs/heads/%s", ref, branchName)
+	cmd := exec.Command("git", "fetch", path, refspec)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// BundleHeads lists the refs stored in a bundle file
+func (r *Repository) BundleHeads(path string) ([]string, error) {
+	cmd := exec.Command("git", "bundle", "list-heads", path)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return nil, fmt.Errorf("%s", errorMsg)
+	}
+
+	var heads []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Each line is "<commit> <ref>"
+		if _, ref, ok := strings.Cut(line, " "); ok {
+			heads = append(heads, ref)
+		}
+	}
+
+	return heads, nil
+}
+
+// MergeBase returns the best common ancestor of two refs
+func (r *Repository) MergeBase(a, b string) (string, error) {
+	cmd := exec.Command("git", "merge-base", a, b)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find a common ancestor of %s and %s", a, b)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// FormatPatch writes the commits in since..ref as a numbered patch series into
+// dir and returns the paths of the written files
+func (r *Repository) FormatPatch(dir, since, ref string) ([]string, error) {
+	cmd := exec.Command("git", "format-patch", "--output-directory", dir, since+".."+ref)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		errorMsg := strings.TrimSpace(stderr.String())
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return nil, fmt.Errorf("%s", errorMsg)
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+
+	return files, nil
+}
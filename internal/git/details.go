@@ -0,0 +1,111 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit is a single entry of a branch's history
+type Commit struct {
+	Hash        string
+	Subject     string
+	Author      string
+	CommittedAt time.Time
+}
+
+// BranchDetails describes how a branch relates to the base branch
+type BranchDetails struct {
+	Base     string
+	Commits  []Commit // Commits not on the base branch, newest first
+	Ahead    int      // Number of commits not on the base branch
+	Behind   int      // Number of base branch commits missing from the branch
+	Merged   bool
+	DiffStat string // Changes since the merge-base, as shown by git diff --stat
+
+	Upstream      string // Upstream of a local branch, e.g. origin/feature
+	UpstreamTrack string // Tracking status such as "ahead 1, behind 2" or "gone"
+}
+
+// BranchDetails gathers the commits unique to a branch, its diffstat and its
+// merge and tracking state relative to base. At most limit commits are listed.
+func (r *Repository) BranchDetails(branch *Branch, base string, limit int) (*BranchDetails, error) {
+	ref := branch.Ref()
+	details := &BranchDetails{Base: base}
+
+	counts, err := r.output("rev-list", "--left-right", "--count", base+"..."+ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s with %s: %w", branch.FullName(), base, err)
+	}
+	if fields := strings.Fields(counts); len(fields) == 2 {
+		details.Behind, _ = strconv.Atoi(fields[0])
+		details.Ahead, _ = strconv.Atoi(fields[1])
+	}
+	details.Merged = details.Ahead == 0
+
+	log, err := r.output("log", fmt.Sprintf("--max-count=%d", limit), "--format=%h%x1f%s%x1f%an%x1f%ct", ref, "^"+base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history of %s: %w", branch.FullName(), err)
+	}
+	details.Commits = parseCommits(log)
+
+	if details.DiffStat, err = r.output("diff", "--stat=72", "--stat-count=10", base+"..."+ref); err != nil {
+		return nil, fmt.Errorf("failed to compute the diffstat of %s: %w", branch.FullName(), err)
+	}
+	details.DiffStat = strings.TrimRight(details.DiffStat, "\n")
+
+	if !branch.IsRemote {
+		tracking, err := r.output("for-each-ref", "--format=%(upstream:short)%00%(upstream:track,nobracket)", ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the upstream of %s: %w", branch.FullName(), err)
+		}
+		upstream, track, _ := strings.Cut(strings.TrimSpace(tracking), "\x00")
+		details.Upstream = upstream
+		details.UpstreamTrack = track
+	}
+
+	return details, nil
+}
+
+// AheadCount returns how many commits of branch are not on base
+func (r *Repository) AheadCount(branch *Branch, base string) (int, error) {
+	count, err := r.output("rev-list", "--count", base+".."+branch.Ref())
+	if err != nil {
+		return 0, fmt.Errorf("failed to compare %s with %s: %w", branch.FullName(), base, err)
+	}
+	return strconv.Atoi(strings.TrimSpace(count))
+}
+
+// parseCommits parses log output with 0x1f separated hash, subject, author and commit time
+func parseCommits(output string) []Commit {
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+
+		commit := Commit{Hash: fields[0], Subject: fields[1], Author: fields[2]}
+		if seconds, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			commit.CommittedAt = time.Unix(seconds, 0)
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// output runs a git command in the repository and returns its standard output
+func (r *Repository) output(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,32 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCommits(t *testing.T) {
+	output := "a1b2c3d\x1fAdd login form\x1fJane Doe\x1f1709287200\n" +
+		"malformed line\n" +
+		"e4f5a6b\x1fFix typo\x1fJohn Roe\x1fnot-a-time\n"
+
+	commits := parseCommits(output)
+	if len(commits) != 2 {
+		t.Fatalf("parseCommits() returned %d commits, want 2", len(commits))
+	}
+
+	first := commits[0]
+	if first.Hash != "a1b2c3d" || first.Subject != "Add login form" || first.Author != "Jane Doe" {
+		t.Errorf("parseCommits()[0] = %+v", first)
+	}
+	if !first.CommittedAt.Equal(time.Unix(1709287200, 0)) {
+		t.Errorf("CommittedAt = %v, want %v", first.CommittedAt, time.Unix(1709287200, 0))
+	}
+	if !commits[1].CommittedAt.IsZero() {
+		t.Errorf("CommittedAt = %v, want zero time for an unparsable timestamp", commits[1].CommittedAt)
+	}
+
+	if commits := parseCommits(""); len(commits) != 0 {
+		t.Errorf("parseCommits(\"\") = %+v, want none", commits)
+	}
+}
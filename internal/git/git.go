@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -17,6 +19,10 @@ var (
 // Repository represents a Git repository
 type Repository struct {
 	Path string
+
+	// Protection holds additional protected branch patterns, e.g. from the
+	// config file or the forge's branch protection rules
+	Protection *ProtectionMatcher
 }
 
 // NewRepository creates a new Repository instance
@@ -52,11 +58,70 @@ func (r *Repository) GetCurrentBranch() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// RemoteURL returns the fetch URL configured for a remote
+func (r *Repository) RemoteURL(remote string) (string, error) {
+	cmd := exec.Command("git", "remote", "get-url", remote)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL of remote %s: %w", remote, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// StateDir returns the directory where bonsai keeps per-repository state
+// (inside the common .git directory), creating it if needed
+func (r *Repository) StateDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %w", err)
+	}
+
+	dir := filepath.Join(strings.TrimSpace(string(output)), "bonsai")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// UserIdentity returns the user git commits as, like "Jane Doe <jane@example.com>",
+// or an empty string when no identity is configured
+func (r *Repository) UserIdentity() string {
+	config := func(key string) string {
+		cmd := exec.Command("git", "config", "--get", key)
+		if r.Path != "" {
+			cmd.Dir = r.Path
+		}
+		output, _ := cmd.Output()
+		return strings.TrimSpace(string(output))
+	}
+
+	name, email := config("user.name"), config("user.email")
+	switch {
+	case name != "" && email != "":
+		return fmt.Sprintf("%s <%s>", name, email)
+	case name != "":
+		return name
+	default:
+		return email
+	}
+}
+
 // ListLocalBranches returns a list of all local branches with their metadata
 func (r *Repository) ListLocalBranches() ([]*Branch, error) {
 	// Use git for-each-ref for efficient branch listing with all metadata
-	// Format: refname|committerdate:iso8601|subject|authorname
-	format := "%(refname:short)|%(committerdate:iso8601)|%(subject)|%(authorname)"
+	// Format: refname|committerdate:iso8601|authordate:iso8601|subject|authorname
+	format := "%(refname:short)|%(committerdate:iso8601)|%(authordate:iso8601)|%(subject)|%(authorname)"
 	cmd := exec.Command("git", "for-each-ref", "--format="+format, "refs/heads/")
 	if r.Path != "" {
 		cmd.Dir = r.Path
@@ -72,13 +137,22 @@ func (r *Repository) ListLocalBranches() ([]*Branch, error) {
 		return nil, err
 	}
 
-	return parseBranches(output, false, currentBranch)
+	branches, err := parseBranches(output, false, currentBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	r.applyProtection(branches)
+	if err := r.applyHolds(branches); err != nil {
+		return nil, err
+	}
+	return branches, nil
 }
 
 // ListRemoteBranches returns a list of all remote branches with their metadata
 func (r *Repository) ListRemoteBranches(remote string) ([]*Branch, error) {
-	// Format: refname|committerdate:iso8601|subject|authorname
-	format := "%(refname:short)|%(committerdate:iso8601)|%(subject)|%(authorname)"
+	// Format: refname|committerdate:iso8601|authordate:iso8601|subject|authorname
+	format := "%(refname:short)|%(committerdate:iso8601)|%(authordate:iso8601)|%(subject)|%(authorname)"
 	refPattern := fmt.Sprintf("refs/remotes/%s/", remote)
 	cmd := exec.Command("git", "for-each-ref", "--format="+format, refPattern)
 	if r.Path != "" {
@@ -102,9 +176,22 @@ func (r *Repository) ListRemoteBranches(remote string) ([]*Branch, error) {
 		branch.Name = strings.TrimPrefix(branch.Name, remote+"/")
 	}
 
+	r.applyProtection(branches)
+	if err := r.applyHolds(branches); err != nil {
+		return nil, err
+	}
 	return branches, nil
 }
 
+// applyProtection marks branches matching the repository's extra protection patterns
+func (r *Repository) applyProtection(branches []*Branch) {
+	for _, branch := range branches {
+		if r.Protection.Matches(branch.Name) {
+			branch.IsProtected = true
+		}
+	}
+}
+
 // parseBranches parses the output from git for-each-ref
 func parseBranches(output []byte, isRemote bool, currentBranch string) ([]*Branch, error) {
 	var branches []*Branch
@@ -116,29 +203,31 @@ func parseBranches(output []byte, isRemote bool, currentBranch string) ([]*Branc
 			continue
 		}
 
-		parts := strings.SplitN(line, "|", 4)
-		if len(parts) != 4 {
+		parts := strings.SplitN(line, "|", 5)
+		if len(parts) != 5 {
 			continue
 		}
 
 		name := parts[0]
 		commitDate := parts[1]
-		commitMsg := parts[2]
-		author := parts[3]
+		authorDate := parts[2]
+		commitMsg := parts[3]
+		author := parts[4]
 
 		// Parse commit date
-		lastCommitAt, err := time.Parse("2006-01-02 15:04:05 -0700", commitDate)
+		lastCommitAt, err := parseDate(commitDate)
+		if err != nil {
+			continue
+		}
+		authoredAt, err := parseDate(authorDate)
 		if err != nil {
-			// Try alternative format
-			lastCommitAt, err = time.Parse(time.RFC3339, commitDate)
-			if err != nil {
-				continue
-			}
+			authoredAt = lastCommitAt
 		}
 
 		branch := &Branch{
 			Name:          name,
 			LastCommitAt:  lastCommitAt,
+			AuthoredAt:    authoredAt,
 			LastCommitMsg: commitMsg,
 			LastAuthor:    author,
 			IsRemote:      isRemote,
@@ -156,6 +245,16 @@ func parseBranches(output []byte, isRemote bool, currentBranch string) ([]*Branc
 	return branches, nil
 }
 
+// parseDate parses a date in git's iso8601 format
+func parseDate(date string) (time.Time, error) {
+	parsed, err := time.Parse("2006-01-02 15:04:05 -0700", date)
+	if err != nil {
+		// Try alternative format
+		return time.Parse(time.RFC3339, date)
+	}
+	return parsed, nil
+}
+
 // isProtectedBranch checks if a branch name is in the protected list
 func isProtectedBranch(name string) bool {
 	// Remove remote prefix if present
@@ -198,8 +297,13 @@ func (r *Repository) DeleteLocalBranch(branchName string, force bool) error {
 	return nil
 }
 
-// DeleteRemoteBranch deletes a remote branch
+// DeleteRemoteBranch deletes a remote branch. Branches matching the
+// repository's protection patterns are refused without contacting the remote.
 func (r *Repository) DeleteRemoteBranch(remote, branchName string) error {
+	if r.Protection.Matches(branchName) {
+		return fmt.Errorf("%s is protected and cannot be deleted", branchName)
+	}
+
 	cmd := exec.Command("git", "push", remote, "--delete", branchName)
 	if r.Path != "" {
 		cmd.Dir = r.Path
@@ -218,3 +322,185 @@ func (r *Repository) DeleteRemoteBranch(remote, branchName string) error {
 
 	return nil
 }
+
+// DeleteRemoteBranchAt deletes a remote branch only while it still points at
+// the given commit, so nothing pushed to it in the meantime is lost
+func (r *Repository) DeleteRemoteBranchAt(remote, branchName, commit string) error {
+	if r.Protection.Matches(branchName) {
+		return fmt.Errorf("%s is protected and cannot be deleted", branchName)
+	}
+
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", branchName, commit)
+	cmd := exec.Command("git", "push", lease, remote, "--delete", branchName)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// RenameRemoteBranch moves a remote branch to a new name in one atomic push.
+// The push is refused when the branch has moved since it was last fetched or
+// the new name is already taken, leaving the remote as it was.
+func (r *Repository) RenameRemoteBranch(remote, oldName, newName string) error {
+	if r.Protection.Matches(oldName) {
+		return fmt.Errorf("%s is protected and cannot be renamed", oldName)
+	}
+
+	commit, err := r.ResolveCommit(fmt.Sprintf("refs/remotes/%s/%s", remote, oldName))
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "push", "--atomic",
+		fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", oldName, commit),
+		fmt.Sprintf("--force-with-lease=refs/heads/%s:", newName),
+		remote,
+		fmt.Sprintf("%s:refs/heads/%s", commit, newName),
+		":refs/heads/"+oldName)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// RenameLocalBranch renames a local branch, moving its config and reflog along
+func (r *Repository) RenameLocalBranch(oldName, newName string) error {
+	cmd := exec.Command("git", "branch", "-m", oldName, newName)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// CheckoutBranch switches the working tree to a local branch
+func (r *Repository) CheckoutBranch(branchName string) error {
+	cmd := exec.Command("git", "switch", branchName)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// SetUpstream makes a local branch track a branch on remote
+func (r *Repository) SetUpstream(branchName, remote, remoteBranch string) error {
+	cmd := exec.Command("git", "branch", "--set-upstream-to="+remote+"/"+remoteBranch, branchName)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// PushBranch publishes a local branch to a remote under the same name
+func (r *Repository) PushBranch(remote, branchName string) error {
+	refspec := fmt.Sprintf("refs/heads/%s:refs/heads/%s", branchName, branchName)
+	cmd := exec.Command("git", "push", remote, refspec)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	return nil
+}
+
+// Fetch updates the remote-tracking branches of a remote, pruning deleted
+// ones, and the holds shared on it
+func (r *Repository) Fetch(remote string) error {
+	cmd := exec.Command("git", "fetch", "--prune", remote)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("failed to fetch %s: %s", remote, errorMsg)
+	}
+
+	return r.FetchHolds(remote)
+}
+
+// ResolveCommit returns the commit ID a ref points to
+func (r *Repository) ResolveCommit(ref string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s", ref)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsAncestor reports whether commit is reachable from ref
+func (r *Repository) IsAncestor(commit, ref string) bool {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", commit, ref)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	return cmd.Run() == nil
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -83,7 +84,7 @@ func TestParseBranches(t *testing.T) {
 	}{
 		{
 			name: "single local branch",
-			output: []byte(`feature/test|2024-01-15 10:30:00 -0800|Add new feature|John Doe
+			output: []byte(`feature/test|2024-01-15 10:30:00 -0800|2024-01-15 10:30:00 -0800|Add new feature|John Doe
 `),
 			isRemote:      false,
 			currentBranch: "main",
@@ -92,8 +93,8 @@ func TestParseBranches(t *testing.T) {
 		},
 		{
 			name: "multiple local branches",
-			output: []byte(`feature/test|2024-01-15 10:30:00 -0800|Add new feature|John Doe
-bugfix/issue-123|2024-01-14 09:15:00 -0800|Fix critical bug|Jane Smith
+			output: []byte(`feature/test|2024-01-15 10:30:00 -0800|2024-01-15 10:30:00 -0800|Add new feature|John Doe
+bugfix/issue-123|2024-01-14 09:15:00 -0800|2024-01-14 09:15:00 -0800|Fix critical bug|Jane Smith
 `),
 			isRemote:      false,
 			currentBranch: "main",
@@ -102,8 +103,8 @@ bugfix/issue-123|2024-01-14 09:15:00 -0800|Fix critical bug|Jane Smith
 		},
 		{
 			name: "current branch is identified",
-			output: []byte(`main|2024-01-15 10:30:00 -0800|Update README|John Doe
-feature/test|2024-01-14 09:15:00 -0800|Add feature|Jane Smith
+			output: []byte(`main|2024-01-15 10:30:00 -0800|2024-01-15 10:30:00 -0800|Update README|John Doe
+feature/test|2024-01-14 09:15:00 -0800|2024-01-14 09:15:00 -0800|Add feature|Jane Smith
 `),
 			isRemote:      false,
 			currentBranch: "main",
@@ -120,9 +121,9 @@ feature/test|2024-01-14 09:15:00 -0800|Add feature|Jane Smith
 		},
 		{
 			name: "malformed line - should skip",
-			output: []byte(`feature/test|2024-01-15 10:30:00 -0800|Add new feature|John Doe
+			output: []byte(`feature/test|2024-01-15 10:30:00 -0800|2024-01-15 10:30:00 -0800|Add new feature|John Doe
 malformed-line
-bugfix/issue-123|2024-01-14 09:15:00 -0800|Fix bug|Jane Smith
+bugfix/issue-123|2024-01-14 09:15:00 -0800|2024-01-14 09:15:00 -0800|Fix bug|Jane Smith
 `),
 			isRemote:      false,
 			currentBranch: "main",
@@ -287,3 +288,17 @@ func TestBranch_Metadata(t *testing.T) {
 		t.Errorf("Age = %v, expected around 10 days", age)
 	}
 }
+
+func TestRepository_DeleteRemoteBranch_Protected(t *testing.T) {
+	repo := NewRepository(t.TempDir())
+	repo.Protection = NewProtectionMatcher("release/*")
+
+	// The guard must trigger before git is ever invoked
+	err := repo.DeleteRemoteBranch("origin", "release/1.0")
+	if err == nil {
+		t.Fatal("DeleteRemoteBranch() should refuse protected branches")
+	}
+	if !strings.Contains(err.Error(), "protected") {
+		t.Errorf("DeleteRemoteBranch() error = %v, want a protection error", err)
+	}
+}
@@ -0,0 +1,336 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Holds on local branches live in the branch's own git config section, e.g.
+// branch.feature.bonsaiPinned, so renaming or deleting the branch takes them
+// along. Holds on remote branches are shared through the remote: they are
+// committed as a holds file on refs/bonsai/meta there, and the local copy of
+// each remote's is kept under refs/bonsai/<remote>/meta.
+const (
+	pinnedKey  = "bonsaiPinned"
+	snoozeKey  = "bonsaiSnoozeUntil"
+	sharedMeta = "refs/bonsai/meta"
+	holdsFile  = "holds"
+)
+
+// Hold keeps a branch from being pruned: for good when pinned, or until a
+// snooze ends
+type Hold struct {
+	Pinned       bool
+	SnoozedUntil time.Time
+}
+
+// PinBranch keeps a branch from being pruned until it is unpinned
+func (r *Repository) PinBranch(branch *Branch) error {
+	return r.updateHold(branch, "pin", func(h *Hold) { h.Pinned = true })
+}
+
+// UnpinBranch lets a pinned branch be pruned again
+func (r *Repository) UnpinBranch(branch *Branch) error {
+	return r.updateHold(branch, "unpin", func(h *Hold) { h.Pinned = false })
+}
+
+// SnoozeBranch holds off pruning a branch until the given time
+func (r *Repository) SnoozeBranch(branch *Branch, until time.Time) error {
+	return r.updateHold(branch, "snooze", func(h *Hold) { h.SnoozedUntil = until.UTC().Truncate(time.Second) })
+}
+
+// UnsnoozeBranch lets a snoozed branch be pruned again
+func (r *Repository) UnsnoozeBranch(branch *Branch) error {
+	return r.updateHold(branch, "unsnooze", func(h *Hold) { h.SnoozedUntil = time.Time{} })
+}
+
+// updateHold changes the hold on a branch where it is stored
+func (r *Repository) updateHold(branch *Branch, action string, change func(*Hold)) error {
+	var err error
+	if branch.IsRemote {
+		err = r.updateRemoteHolds(branch.RemoteName, fmt.Sprintf("%s %s", action, branch.Name), func(holds map[string]Hold) {
+			hold := holds[branch.Name]
+			change(&hold)
+			holds[branch.Name] = hold
+		})
+	} else {
+		hold := Hold{Pinned: branch.IsPinned, SnoozedUntil: branch.SnoozedUntil}
+		change(&hold)
+		err = r.setLocalHold(branch.Name, hold)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to %s %s: %w", action, branch.FullName(), err)
+	}
+	return nil
+}
+
+// setLocalHold records the hold in the branch's config section
+func (r *Repository) setLocalHold(name string, hold Hold) error {
+	section := "branch." + name + "."
+
+	if hold.Pinned {
+		if err := r.config(section+pinnedKey, "true"); err != nil {
+			return err
+		}
+	} else if err := r.unsetConfig(section + pinnedKey); err != nil {
+		return err
+	}
+
+	if !hold.SnoozedUntil.IsZero() {
+		return r.config(section+snoozeKey, hold.SnoozedUntil.Format(time.RFC3339))
+	}
+	return r.unsetConfig(section + snoozeKey)
+}
+
+// Holds returns the holds on local branches, keyed by branch name
+func (r *Repository) Holds() (map[string]Hold, error) {
+	pattern := fmt.Sprintf(`^branch\..*\.(%s|%s)$`, strings.ToLower(pinnedKey), strings.ToLower(snoozeKey))
+	cmd := exec.Command("git", "config", "--get-regexp", pattern)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		// git config exits with 1 when nothing matches
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return map[string]Hold{}, nil
+		}
+		return nil, fmt.Errorf("failed to read branch holds: %w", err)
+	}
+
+	return parseLocalHolds(string(output)), nil
+}
+
+// parseLocalHolds parses git config --get-regexp output of hold entries. Git
+// lowercases the variable name but keeps the branch name as it is.
+func parseLocalHolds(output string) map[string]Hold {
+	holds := map[string]Hold{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		key, value, found := strings.Cut(line, " ")
+		if !found || !strings.HasPrefix(key, "branch.") {
+			continue
+		}
+
+		name, variable := key[len("branch."):], ""
+		if i := strings.LastIndex(name, "."); i != -1 {
+			name, variable = name[:i], name[i+1:]
+		}
+
+		hold := holds[name]
+		switch variable {
+		case strings.ToLower(pinnedKey):
+			hold.Pinned = value == "true"
+		case strings.ToLower(snoozeKey):
+			until, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				continue
+			}
+			hold.SnoozedUntil = until
+		default:
+			continue
+		}
+		holds[name] = hold
+	}
+	return holds
+}
+
+// RemoteHolds returns the holds shared on a remote as of the last fetch,
+// keyed by branch name
+func (r *Repository) RemoteHolds(remote string) (map[string]Hold, error) {
+	ref := remoteMeta(remote)
+	if _, err := r.ResolveCommit(ref); err != nil {
+		return map[string]Hold{}, nil
+	}
+
+	content, err := r.output("cat-file", "blob", ref+":"+holdsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holds shared on %s: %w", remote, err)
+	}
+	return parseSharedHolds(content), nil
+}
+
+// parseSharedHolds parses a holds file, which has one line per hold:
+//
+//	pin feature/login
+//	snooze 2030-01-02T03:04:05Z spike/cache
+func parseSharedHolds(content string) map[string]Hold {
+	holds := map[string]Hold{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 2 && fields[0] == "pin":
+			hold := holds[fields[1]]
+			hold.Pinned = true
+			holds[fields[1]] = hold
+		case len(fields) == 3 && fields[0] == "snooze":
+			until, err := time.Parse(time.RFC3339, fields[1])
+			if err != nil {
+				continue
+			}
+			hold := holds[fields[2]]
+			hold.SnoozedUntil = until
+			holds[fields[2]] = hold
+		}
+	}
+	return holds
+}
+
+// formatSharedHolds renders holds as a holds file, sorted by branch name so
+// that changes diff cleanly. Snoozes that are over are dropped.
+func formatSharedHolds(holds map[string]Hold) string {
+	names := make([]string, 0, len(holds))
+	for name := range holds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		hold := holds[name]
+		if hold.Pinned {
+			fmt.Fprintf(&b, "pin %s\n", name)
+		}
+		if time.Now().Before(hold.SnoozedUntil) {
+			fmt.Fprintf(&b, "snooze %s %s\n", hold.SnoozedUntil.UTC().Format(time.RFC3339), name)
+		}
+	}
+	return b.String()
+}
+
+// FetchHolds updates the local copy of the holds shared on a remote
+func (r *Repository) FetchHolds(remote string) error {
+	_, err := r.run("", "fetch", remote, "+"+sharedMeta+":"+remoteMeta(remote))
+
+	// A remote nobody has shared holds on yet has no holds ref
+	if err != nil && !strings.Contains(err.Error(), "couldn't find remote ref") {
+		return fmt.Errorf("failed to fetch holds from %s: %w", remote, err)
+	}
+	return nil
+}
+
+// updateRemoteHolds changes the holds shared on a remote: it fetches the
+// latest ones, commits the changed holds file on top and pushes it back.
+// A concurrent update on the remote makes the push fail rather than be lost.
+func (r *Repository) updateRemoteHolds(remote, message string, change func(map[string]Hold)) error {
+	if err := r.FetchHolds(remote); err != nil {
+		return err
+	}
+
+	holds, err := r.RemoteHolds(remote)
+	if err != nil {
+		return err
+	}
+	change(holds)
+
+	blob, err := r.run(formatSharedHolds(holds), "hash-object", "-w", "--stdin")
+	if err != nil {
+		return err
+	}
+	tree, err := r.run(fmt.Sprintf("100644 blob %s\t%s\n", blob, holdsFile), "mktree")
+	if err != nil {
+		return err
+	}
+
+	args := []string{"commit-tree", tree, "-m", "bonsai: " + message}
+	parent, parentErr := r.ResolveCommit(remoteMeta(remote))
+	if parentErr == nil {
+		args = append(args, "-p", parent)
+	}
+	commit, err := r.run("", args...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.run("", "push", remote, commit+":"+sharedMeta); err != nil {
+		return err
+	}
+	_, err = r.run("", "update-ref", remoteMeta(remote), commit)
+	return err
+}
+
+// applyHolds records on the branches how they are held
+func (r *Repository) applyHolds(branches []*Branch) error {
+	byRemote := map[string]map[string]Hold{}
+	for _, branch := range branches {
+		holds, known := byRemote[branch.RemoteName]
+		if !known {
+			var err error
+			if branch.IsRemote {
+				holds, err = r.RemoteHolds(branch.RemoteName)
+			} else {
+				holds, err = r.Holds()
+			}
+			if err != nil {
+				return err
+			}
+			byRemote[branch.RemoteName] = holds
+		}
+
+		hold := holds[branch.Name]
+		branch.IsPinned = hold.Pinned
+		branch.SnoozedUntil = hold.SnoozedUntil
+	}
+	return nil
+}
+
+// remoteMeta is where the holds shared on remote are kept locally
+func remoteMeta(remote string) string {
+	return "refs/bonsai/" + remote + "/meta"
+}
+
+// config sets a git config variable in the repository
+func (r *Repository) config(name, value string) error {
+	_, err := r.run("", "config", name, value)
+	return err
+}
+
+// unsetConfig removes a git config variable from the repository, if it is set
+func (r *Repository) unsetConfig(name string) error {
+	_, err := r.run("", "config", "--unset", name)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 5 {
+		return nil
+	}
+	return err
+}
+
+// run runs a git command in the repository with input on its standard input
+// and returns its trimmed output, or the trimmed error output as the error
+func (r *Repository) run(input string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+	cmd.Stdin = strings.NewReader(input)
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		errorMsg := strings.TrimSpace(stderr.String())
+		if errorMsg == "" {
+			return "", err
+		}
+		return "", &commandError{msg: errorMsg, err: err}
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// commandError is a failed git command described by its error output
+type commandError struct {
+	msg string
+	err error
+}
+
+func (e *commandError) Error() string { return e.msg }
+func (e *commandError) Unwrap() error { return e.err }
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLocalHolds(t *testing.T) {
+	output := "branch.feature/login.bonsaipinned true\n" +
+		"branch.release.v1.2.bonsaisnoozeuntil 2030-01-02T03:04:05Z\n" +
+		"branch.broken.bonsaisnoozeuntil not-a-time\n" +
+		"branch.feature/login.remote origin\n"
+
+	holds := parseLocalHolds(output)
+
+	if len(holds) != 2 {
+		t.Fatalf("parseLocalHolds() = %+v, want 2 holds", holds)
+	}
+	if !holds["feature/login"].Pinned {
+		t.Error("feature/login should be pinned")
+	}
+	if want := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC); !holds["release.v1.2"].SnoozedUntil.Equal(want) {
+		t.Errorf("release.v1.2 snoozed until %v, want %v", holds["release.v1.2"].SnoozedUntil, want)
+	}
+}
+
+func TestSharedHoldsRoundTrip(t *testing.T) {
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	holds := map[string]Hold{
+		"spike/cache":   {SnoozedUntil: until},
+		"feature/login": {Pinned: true},
+		"both":          {Pinned: true, SnoozedUntil: until},
+		"woke":          {SnoozedUntil: time.Now().Add(-time.Hour)},
+	}
+
+	parsed := parseSharedHolds(formatSharedHolds(holds))
+
+	if len(parsed) != 3 {
+		t.Fatalf("round trip = %+v, want the 3 holds still in effect", parsed)
+	}
+	for _, name := range []string{"spike/cache", "feature/login", "both"} {
+		if parsed[name].Pinned != holds[name].Pinned || !parsed[name].SnoozedUntil.Equal(holds[name].SnoozedUntil) {
+			t.Errorf("%s: round trip = %+v, want %+v", name, parsed[name], holds[name])
+		}
+	}
+}
+
+func TestBranch_IsHeld(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  Branch
+		snoozed bool
+		held    bool
+	}{
+		{"free", Branch{}, false, false},
+		{"pinned", Branch{IsPinned: true}, false, true},
+		{"snoozed", Branch{SnoozedUntil: time.Now().Add(time.Hour)}, true, true},
+		{"snooze over", Branch{SnoozedUntil: time.Now().Add(-time.Hour)}, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.branch.IsSnoozed(); got != tt.snoozed {
+			t.Errorf("%s: IsSnoozed() = %v, want %v", tt.name, got, tt.snoozed)
+		}
+		if got := tt.branch.IsHeld(); got != tt.held {
+			t.Errorf("%s: IsHeld() = %v, want %v", tt.name, got, tt.held)
+		}
+	}
+}
@@ -7,9 +7,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/kriscoleman/bonsai/internal/quarantine"
 )
 
 // TestHelper provides utilities for integration tests
@@ -497,3 +500,818 @@ func TestIntegration_ProtectedBranches(t *testing.T) {
 		}
 	}
 }
+
+func TestIntegration_InProgressOperation(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+
+	defaultBranch := helper.GetCurrentBranch()
+	repo := NewRepository(helper.RepoDir)
+
+	// Nothing in progress on a clean repository
+	op, err := repo.InProgressOperation()
+	if err != nil {
+		t.Fatalf("InProgressOperation() error = %v", err)
+	}
+	if op != nil {
+		t.Fatalf("InProgressOperation() = %+v, want nil", op)
+	}
+
+	// Create conflicting changes on two branches
+	conflictFile := filepath.Join(helper.RepoDir, "conflict.txt")
+	helper.CreateBranch("feature-conflict", true)
+	if err := os.WriteFile(conflictFile, []byte("feature\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	helper.runGitCommand("-C", helper.RepoDir, "add", ".")
+	helper.runGitCommand("-C", helper.RepoDir, "commit", "-m", "Feature change")
+
+	helper.CheckoutBranch(defaultBranch)
+	if err := os.WriteFile(conflictFile, []byte("main\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	helper.runGitCommand("-C", helper.RepoDir, "add", ".")
+	helper.runGitCommand("-C", helper.RepoDir, "commit", "-m", "Main change")
+
+	// Start a rebase that stops on the conflict
+	helper.CheckoutBranch("feature-conflict")
+	cmd := exec.Command("git", "-C", helper.RepoDir, "rebase", defaultBranch)
+	if err := cmd.Run(); err == nil {
+		t.Fatal("Expected rebase to stop on a conflict")
+	}
+
+	op, err = repo.InProgressOperation()
+	if err != nil {
+		t.Fatalf("InProgressOperation() error = %v", err)
+	}
+	if op == nil || op.Name != "rebase" {
+		t.Fatalf("InProgressOperation() = %+v, want a rebase", op)
+	}
+
+	involved := map[string]bool{}
+	for _, name := range op.Branches {
+		involved[name] = true
+	}
+	if !involved["feature-conflict"] {
+		t.Errorf("Branch being rebased should be involved, got %v", op.Branches)
+	}
+	if !involved[defaultBranch] {
+		t.Errorf("Rebase target %s should be involved, got %v", defaultBranch, op.Branches)
+	}
+
+	helper.runGitCommand("-C", helper.RepoDir, "rebase", "--abort")
+
+	op, err = repo.InProgressOperation()
+	if err != nil {
+		t.Fatalf("InProgressOperation() error = %v", err)
+	}
+	if op != nil {
+		t.Errorf("InProgressOperation() after abort = %+v, want nil", op)
+	}
+}
+
+func TestIntegration_ProtectionPatterns(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+
+	helper.CreateBranchWithCommit("release-1.0", "Release 1.0")
+	helper.CreateBranchWithCommit("feature-x", "Feature X")
+
+	repo := NewRepository(helper.RepoDir)
+	repo.Protection = NewProtectionMatcher("release-*")
+
+	branches, err := repo.ListLocalBranches()
+	if err != nil {
+		t.Fatalf("ListLocalBranches() error = %v", err)
+	}
+
+	for _, b := range branches {
+		switch b.Name {
+		case "release-1.0":
+			if !b.IsProtected {
+				t.Errorf("Branch %s should be protected by the release-* pattern", b.Name)
+			}
+		case "feature-x":
+			if b.IsProtected {
+				t.Errorf("Branch %s should NOT be protected", b.Name)
+			}
+		}
+	}
+}
+
+// AddBareRemote creates a bare repository, adds it as a remote and pushes every branch to it
+func (h *TestHelper) AddBareRemote(name string) string {
+	remoteDir := filepath.Join(h.TempDir, name+".git")
+	h.runGitCommand("init", "--bare", remoteDir)
+	h.runGitCommand("-C", h.RepoDir, "remote", "add", name, remoteDir)
+	h.runGitCommand("-C", h.RepoDir, "push", name, "--all")
+	h.runGitCommand("-C", h.RepoDir, "fetch", name)
+	return remoteDir
+}
+
+func TestIntegration_RenameRemoteBranch(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	helper.CreateBranchWithCommit("feature-old", "Old feature")
+	helper.AddBareRemote("origin")
+
+	repo := NewRepository(helper.RepoDir)
+
+	commit, err := repo.ResolveCommit("refs/remotes/origin/feature-old")
+	if err != nil {
+		t.Fatalf("ResolveCommit() error = %v", err)
+	}
+
+	if err := repo.RenameRemoteBranch("origin", "feature-old", "stale/feature-old"); err != nil {
+		t.Fatalf("RenameRemoteBranch() error = %v", err)
+	}
+
+	if err := repo.Fetch("origin"); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	branches, err := repo.ListRemoteBranches("origin")
+	if err != nil {
+		t.Fatalf("ListRemoteBranches() error = %v", err)
+	}
+
+	names := map[string]bool{}
+	for _, b := range branches {
+		names[b.Name] = true
+	}
+	if names["feature-old"] {
+		t.Error("feature-old should no longer exist on the remote")
+	}
+	if !names["stale/feature-old"] {
+		t.Error("stale/feature-old should exist on the remote")
+	}
+
+	moved, err := repo.ResolveCommit("refs/remotes/origin/stale/feature-old")
+	if err != nil {
+		t.Fatalf("ResolveCommit() error = %v", err)
+	}
+	if moved != commit {
+		t.Errorf("Renamed branch points at %s, want %s", moved, commit)
+	}
+
+	if !repo.IsAncestor(commit, "refs/remotes/origin/stale/feature-old") {
+		t.Error("IsAncestor() should be true for the branch tip")
+	}
+	if repo.IsAncestor(commit, helper.GetCurrentBranch()) {
+		t.Error("IsAncestor() should be false for an unmerged commit")
+	}
+}
+
+func TestIntegration_RenameRemoteBranchLeavesRemoteAloneOnFailure(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	helper.CreateBranchWithCommit("feature-old", "Old feature")
+	helper.CreateBranchWithCommit("release-1.0", "Release 1.0")
+	remoteDir := helper.AddBareRemote("origin")
+
+	remoteHead := func(name string) string {
+		output, err := exec.Command("git", "-C", remoteDir, "rev-parse", "--verify", "--quiet", "refs/heads/"+name).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	repo := NewRepository(helper.RepoDir)
+	repo.Protection = NewProtectionMatcher("release-*")
+
+	if err := repo.RenameRemoteBranch("origin", "release-1.0", "stale/release-1.0"); err == nil {
+		t.Error("RenameRemoteBranch() should refuse a protected branch")
+	}
+	if remoteHead("stale/release-1.0") != "" {
+		t.Error("A refused rename should not push the new name")
+	}
+
+	// Someone pushes to the branch after our last fetch
+	fetched := remoteHead("feature-old")
+	helper.CheckoutBranch("feature-old")
+	helper.runGitCommand("-C", helper.RepoDir, "commit", "--allow-empty", "-m", "More work")
+	helper.runGitCommand("-C", helper.RepoDir, "push", "origin", "feature-old")
+	helper.runGitCommand("-C", helper.RepoDir, "update-ref", "refs/remotes/origin/feature-old", fetched)
+	pushed := remoteHead("feature-old")
+
+	if err := repo.RenameRemoteBranch("origin", "feature-old", "stale/feature-old"); err == nil {
+		t.Error("RenameRemoteBranch() should refuse a branch that moved since the last fetch")
+	}
+	if remoteHead("feature-old") != pushed || remoteHead("stale/feature-old") != "" {
+		t.Error("A refused rename should leave the remote as it was")
+	}
+
+	if err := repo.DeleteRemoteBranchAt("origin", "feature-old", fetched); err == nil {
+		t.Error("DeleteRemoteBranchAt() should refuse a branch that moved away from the commit")
+	}
+	if err := repo.DeleteRemoteBranchAt("origin", "feature-old", pushed); err != nil {
+		t.Fatalf("DeleteRemoteBranchAt() error = %v", err)
+	}
+	if remoteHead("feature-old") != "" {
+		t.Error("DeleteRemoteBranchAt() should delete a branch still at the commit")
+	}
+}
+
+func TestIntegration_ArchiveBranch(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	helper.CreateBranchWithCommit("feature-archived", "Archived feature")
+
+	repo := NewRepository(helper.RepoDir)
+
+	commit, err := repo.ResolveCommit("refs/heads/feature-archived")
+	if err != nil {
+		t.Fatalf("ResolveCommit() error = %v", err)
+	}
+
+	branch := &Branch{Name: "feature-archived", LastAuthor: "Test User"}
+	tag, err := repo.ArchiveBranch(branch)
+	if err != nil {
+		t.Fatalf("ArchiveBranch() error = %v", err)
+	}
+	if tag != "archive/feature-archived" {
+		t.Errorf("ArchiveBranch() tag = %s, want archive/feature-archived", tag)
+	}
+
+	// Archiving the same name again must not overwrite the first archive
+	second, err := repo.ArchiveBranch(branch)
+	if err != nil {
+		t.Fatalf("ArchiveBranch() error = %v", err)
+	}
+	if second != "archive/feature-archived-2" {
+		t.Errorf("ArchiveBranch() tag = %s, want archive/feature-archived-2", second)
+	}
+
+	if err := repo.DeleteLocalBranch("feature-archived", true); err != nil {
+		t.Fatalf("DeleteLocalBranch() error = %v", err)
+	}
+
+	archives, err := repo.ListArchives()
+	if err != nil {
+		t.Fatalf("ListArchives() error = %v", err)
+	}
+	if len(archives) != 2 {
+		t.Fatalf("ListArchives() returned %d archives, want 2", len(archives))
+	}
+
+	archive := archives[0]
+	if archive.Branch != "feature-archived" || archive.Commit != commit || archive.Author != "Test User" {
+		t.Errorf("ListArchives()[0] = %+v", archive)
+	}
+	if archive.ArchivedAt.IsZero() {
+		t.Error("ArchivedAt should be recorded")
+	}
+
+	if err := repo.RestoreArchive(archive, "feature-restored"); err != nil {
+		t.Fatalf("RestoreArchive() error = %v", err)
+	}
+	if !helper.BranchExists("feature-restored") {
+		t.Error("feature-restored should exist after restoring")
+	}
+}
+
+func TestIntegration_BundleRoundTrip(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	base := helper.GetCurrentBranch()
+	helper.CreateBranchWithCommit("feature-bundled", "Bundled feature")
+	helper.CheckoutBranch(base)
+
+	repo := NewRepository(helper.RepoDir)
+
+	detected, err := repo.DefaultBranch("origin")
+	if err != nil {
+		t.Fatalf("DefaultBranch() error = %v", err)
+	}
+	if detected != base {
+		t.Errorf("DefaultBranch() = %s, want %s", detected, base)
+	}
+
+	unique, err := repo.CountUniqueCommits("refs/heads/feature-bundled", base)
+	if err != nil {
+		t.Fatalf("CountUniqueCommits() error = %v", err)
+	}
+	if unique != 1 {
+		t.Errorf("CountUniqueCommits() = %d, want 1", unique)
+	}
+
+	commit, err := repo.ResolveCommit("refs/heads/feature-bundled")
+	if err != nil {
+		t.Fatalf("ResolveCommit() error = %v", err)
+	}
+
+	path := filepath.Join(helper.TempDir, "feature.bundle")
+	if err := repo.CreateBundle(path, "refs/heads/feature-bundled", base); err != nil {
+		t.Fatalf("CreateBundle() error = %v", err)
+	}
+
+	heads, err := repo.BundleHeads(path)
+	if err != nil {
+		t.Fatalf("BundleHeads() error = %v", err)
+	}
+	if len(heads) != 1 || heads[0] != "refs/heads/feature-bundled" {
+		t.Errorf("BundleHeads() = %v, want [refs/heads/feature-bundled]", heads)
+	}
+
+	// Restore the deleted branch under a new name
+	if err := repo.DeleteLocalBranch("feature-bundled", true); err != nil {
+		t.Fatalf("DeleteLocalBranch() error = %v", err)
+	}
+	if err := repo.FetchBundle(path, "refs/heads/feature-bundled", "feature-restored"); err != nil {
+		t.Fatalf("FetchBundle() error = %v", err)
+	}
+
+	restored, err := repo.ResolveCommit("refs/heads/feature-restored")
+	if err != nil {
+		t.Fatalf("ResolveCommit() error = %v", err)
+	}
+	if restored != commit {
+		t.Errorf("Restored branch points at %s, want %s", restored, commit)
+	}
+}
+
+func TestIntegration_FormatPatch(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	base := helper.GetCurrentBranch()
+	helper.CreateBranchWithCommit("feature-salvaged", "Salvaged feature")
+	helper.CheckoutBranch(base)
+
+	repo := NewRepository(helper.RepoDir)
+
+	mergeBase, err := repo.MergeBase(base, "refs/heads/feature-salvaged")
+	if err != nil {
+		t.Fatalf("MergeBase() error = %v", err)
+	}
+
+	baseCommit, err := repo.ResolveCommit(base)
+	if err != nil {
+		t.Fatalf("ResolveCommit() error = %v", err)
+	}
+	if mergeBase != baseCommit {
+		t.Errorf("MergeBase() = %s, want %s", mergeBase, baseCommit)
+	}
+
+	dir := filepath.Join(helper.TempDir, "salvage")
+	patches, err := repo.FormatPatch(dir, mergeBase, "refs/heads/feature-salvaged")
+	if err != nil {
+		t.Fatalf("FormatPatch() error = %v", err)
+	}
+	if len(patches) != 1 {
+		t.Fatalf("FormatPatch() wrote %d patches, want 1", len(patches))
+	}
+
+	content, err := os.ReadFile(patches[0])
+	if err != nil {
+		t.Fatalf("Failed to read patch: %v", err)
+	}
+	if !strings.Contains(string(content), "Subject: [PATCH] Salvaged feature") {
+		t.Errorf("Patch does not contain the commit subject:\n%s", content)
+	}
+}
+
+func TestIntegration_MergedAndGoneBranches(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	base := helper.GetCurrentBranch()
+	helper.CreateBranch("feature-merged", false)
+	helper.CreateBranchWithCommit("feature-gone", "Gone feature")
+	helper.CheckoutBranch(base)
+	helper.AddBareRemote("origin")
+
+	repo := NewRepository(helper.RepoDir)
+
+	hooksDir, err := repo.HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir() error = %v", err)
+	}
+	if !filepath.IsAbs(hooksDir) || filepath.Base(hooksDir) != "hooks" {
+		t.Errorf("HooksDir() = %s, want an absolute .git/hooks path", hooksDir)
+	}
+
+	helper.runGitCommand("-C", helper.RepoDir, "config", "core.hooksPath", "custom-hooks")
+	hooksDir, err = repo.HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir() error = %v", err)
+	}
+	if filepath.Base(hooksDir) != "custom-hooks" {
+		t.Errorf("HooksDir() = %s, want core.hooksPath to be honored", hooksDir)
+	}
+
+	merged, err := repo.MergedBranches(base)
+	if err != nil {
+		t.Fatalf("MergedBranches() error = %v", err)
+	}
+	mergedSet := map[string]bool{}
+	for _, name := range merged {
+		mergedSet[name] = true
+	}
+	if !mergedSet["feature-merged"] || mergedSet["feature-gone"] {
+		t.Errorf("MergedBranches() = %v, want feature-merged but not feature-gone", merged)
+	}
+
+	mergedRemote, err := repo.MergedRemoteBranches("origin", base)
+	if err != nil {
+		t.Fatalf("MergedRemoteBranches() error = %v", err)
+	}
+	if !slices.Contains(mergedRemote, "origin/feature-merged") || slices.Contains(mergedRemote, "origin/feature-gone") {
+		t.Errorf("MergedRemoteBranches() = %v, want origin/feature-merged but not origin/feature-gone", mergedRemote)
+	}
+
+	helper.runGitCommand("-C", helper.RepoDir, "branch", "--set-upstream-to=origin/feature-gone", "feature-gone")
+	helper.runGitCommand("-C", helper.RepoDir, "push", "origin", "--delete", "feature-gone")
+
+	gone, err := repo.GoneBranches()
+	if err != nil {
+		t.Fatalf("GoneBranches() error = %v", err)
+	}
+	if len(gone) != 1 || gone[0] != "feature-gone" {
+		t.Errorf("GoneBranches() = %v, want [feature-gone]", gone)
+	}
+
+	upstreams, err := repo.Upstreams()
+	if err != nil {
+		t.Fatalf("Upstreams() error = %v", err)
+	}
+	if upstream := upstreams["feature-gone"]; upstream.Name != "origin/feature-gone" || upstream.Track != "gone" {
+		t.Errorf("Upstreams()[feature-gone] = %+v, want a gone origin/feature-gone", upstream)
+	}
+	if _, ok := upstreams["feature-merged"]; ok {
+		t.Errorf("Upstreams() = %v, want no upstream for feature-merged", upstreams)
+	}
+}
+
+func TestIntegration_BranchDetails(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	base := helper.GetCurrentBranch()
+	helper.CreateBranch("feature-merged", false)
+	helper.CreateBranchWithCommit("feature-details", "Add details")
+	helper.CheckoutBranch(base)
+	helper.AddBareRemote("origin")
+	helper.runGitCommand("-C", helper.RepoDir, "push", "--set-upstream", "origin", "feature-details")
+
+	repo := NewRepository(helper.RepoDir)
+
+	details, err := repo.BranchDetails(&Branch{Name: "feature-details"}, base, 10)
+	if err != nil {
+		t.Fatalf("BranchDetails() error = %v", err)
+	}
+	if details.Ahead != 1 || details.Behind != 0 || details.Merged {
+		t.Errorf("BranchDetails() ahead/behind/merged = %d/%d/%v, want 1/0/false", details.Ahead, details.Behind, details.Merged)
+	}
+	if len(details.Commits) != 1 || details.Commits[0].Subject != "Add details" {
+		t.Errorf("BranchDetails() commits = %+v, want the single unique commit", details.Commits)
+	}
+	if !strings.Contains(details.DiffStat, "1 file changed") {
+		t.Errorf("BranchDetails() diffstat = %q, want one changed file", details.DiffStat)
+	}
+	if details.Upstream != "origin/feature-details" || details.UpstreamTrack != "" {
+		t.Errorf("BranchDetails() upstream = %q %q, want an up to date origin/feature-details", details.Upstream, details.UpstreamTrack)
+	}
+
+	merged, err := repo.BranchDetails(&Branch{Name: "feature-merged"}, base, 10)
+	if err != nil {
+		t.Fatalf("BranchDetails() error = %v", err)
+	}
+	if !merged.Merged || len(merged.Commits) != 0 || merged.DiffStat != "" || merged.Upstream != "" {
+		t.Errorf("BranchDetails() = %+v, want a merged branch without upstream", merged)
+	}
+}
+
+func TestIntegration_AheadCount(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	base := helper.GetCurrentBranch()
+	helper.CreateBranch("feature-merged", false)
+	helper.CreateBranchWithCommit("feature-ahead", "Add ahead")
+	helper.CheckoutBranch(base)
+
+	repo := NewRepository(helper.RepoDir)
+
+	for name, want := range map[string]int{"feature-merged": 0, "feature-ahead": 1} {
+		ahead, err := repo.AheadCount(&Branch{Name: name}, base)
+		if err != nil {
+			t.Fatalf("AheadCount(%s) error = %v", name, err)
+		}
+		if ahead != want {
+			t.Errorf("AheadCount(%s) = %d, want %d", name, ahead, want)
+		}
+	}
+
+	if _, err := repo.AheadCount(&Branch{Name: "missing"}, base); err == nil {
+		t.Error("AheadCount() expected an error for a missing branch")
+	}
+}
+
+func TestIntegration_BranchActions(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	helper.CreateBranch("feature-old", false)
+
+	repo := NewRepository(helper.RepoDir)
+
+	if err := repo.RenameLocalBranch("feature-old", "feature-new"); err != nil {
+		t.Fatalf("RenameLocalBranch() error = %v", err)
+	}
+	if helper.BranchExists("feature-old") || !helper.BranchExists("feature-new") {
+		t.Error("RenameLocalBranch() did not rename the branch")
+	}
+
+	if err := repo.CheckoutBranch("feature-new"); err != nil {
+		t.Fatalf("CheckoutBranch() error = %v", err)
+	}
+	if current := helper.GetCurrentBranch(); current != "feature-new" {
+		t.Errorf("current branch = %s, want feature-new", current)
+	}
+
+	until := time.Now().Add(21 * 24 * time.Hour).Truncate(time.Second)
+	if err := repo.SnoozeBranch(&Branch{Name: "feature-new"}, until); err != nil {
+		t.Fatalf("SnoozeBranch() error = %v", err)
+	}
+
+	// The hold moves along when the branch is renamed
+	if err := repo.RenameLocalBranch("feature-new", "feature-renamed"); err != nil {
+		t.Fatalf("RenameLocalBranch() error = %v", err)
+	}
+
+	branches, err := repo.ListLocalBranches()
+	if err != nil {
+		t.Fatalf("ListLocalBranches() error = %v", err)
+	}
+	for _, branch := range branches {
+		snoozed := branch.Name == "feature-renamed"
+		if branch.IsSnoozed() != snoozed {
+			t.Errorf("%s: IsSnoozed() = %v, want %v", branch.Name, branch.IsSnoozed(), snoozed)
+		}
+		if snoozed && !branch.SnoozedUntil.Equal(until) {
+			t.Errorf("SnoozedUntil = %v, want %v", branch.SnoozedUntil, until)
+		}
+	}
+
+	branch := &Branch{Name: "feature-renamed", SnoozedUntil: until}
+	if err := repo.PinBranch(branch); err != nil {
+		t.Fatalf("PinBranch() error = %v", err)
+	}
+	if err := repo.UnsnoozeBranch(&Branch{Name: "feature-renamed", IsPinned: true}); err != nil {
+		t.Fatalf("UnsnoozeBranch() error = %v", err)
+	}
+	holds, err := repo.Holds()
+	if err != nil {
+		t.Fatalf("Holds() error = %v", err)
+	}
+	if hold := holds["feature-renamed"]; !hold.Pinned || !hold.SnoozedUntil.IsZero() {
+		t.Errorf("Holds() = %+v, want only a pin", holds)
+	}
+
+	if err := repo.UnpinBranch(&Branch{Name: "feature-renamed", IsPinned: true}); err != nil {
+		t.Fatalf("UnpinBranch() error = %v", err)
+	}
+	if holds, _ := repo.Holds(); len(holds) != 0 {
+		t.Errorf("Holds() = %v, want none", holds)
+	}
+}
+
+func TestIntegration_SharedHolds(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	helper.CreateBranchWithCommit("feature-kept", "Kept feature")
+	helper.CreateBranchWithCommit("feature-later", "Later feature")
+	remoteDir := helper.AddBareRemote("origin")
+
+	repo := NewRepository(helper.RepoDir)
+	branches, err := repo.ListRemoteBranches("origin")
+	if err != nil {
+		t.Fatalf("ListRemoteBranches() error = %v", err)
+	}
+
+	until := time.Now().Add(21 * 24 * time.Hour).Truncate(time.Second)
+	for _, branch := range branches {
+		switch branch.Name {
+		case "feature-kept":
+			err = repo.PinBranch(branch)
+		case "feature-later":
+			err = repo.SnoozeBranch(branch, until)
+		}
+		if err != nil {
+			t.Fatalf("holding %s: %v", branch.FullName(), err)
+		}
+	}
+
+	// Another clone sees the holds once it fetches
+	cloneDir := filepath.Join(helper.TempDir, "clone")
+	helper.runGitCommand("clone", "-q", remoteDir, cloneDir)
+	helper.runGitCommand("-C", cloneDir, "config", "user.name", "Other User")
+	helper.runGitCommand("-C", cloneDir, "config", "user.email", "other@example.com")
+	clone := NewRepository(cloneDir)
+	if err := clone.Fetch("origin"); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	branches, err = clone.ListRemoteBranches("origin")
+	if err != nil {
+		t.Fatalf("ListRemoteBranches() error = %v", err)
+	}
+	for _, branch := range branches {
+		if pinned := branch.Name == "feature-kept"; branch.IsPinned != pinned {
+			t.Errorf("%s: IsPinned = %v, want %v", branch.Name, branch.IsPinned, pinned)
+		}
+		if snoozed := branch.Name == "feature-later"; branch.IsSnoozed() != snoozed {
+			t.Errorf("%s: IsSnoozed() = %v, want %v", branch.Name, branch.IsSnoozed(), snoozed)
+		}
+	}
+
+	// Updates from the clone build on the holds already shared
+	for _, branch := range branches {
+		if branch.Name == "feature-kept" {
+			if err := clone.UnpinBranch(branch); err != nil {
+				t.Fatalf("UnpinBranch() error = %v", err)
+			}
+		}
+	}
+	if err := repo.FetchHolds("origin"); err != nil {
+		t.Fatalf("FetchHolds() error = %v", err)
+	}
+	holds, err := repo.RemoteHolds("origin")
+	if err != nil {
+		t.Fatalf("RemoteHolds() error = %v", err)
+	}
+	if len(holds) != 1 || !holds["feature-later"].SnoozedUntil.Equal(until) {
+		t.Errorf("RemoteHolds() = %+v, want only the snooze of feature-later", holds)
+	}
+}
+
+func TestIntegration_AgeBasis(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+
+	// Committed to 100 days ago, but checked out and created just now
+	initial := helper.GetCurrentBranch()
+	helper.CreateBranchWithAge("revisited", 100)
+	helper.CheckoutBranch("revisited")
+	helper.CheckoutBranch(initial)
+
+	// Created from an old commit without ever being checked out
+	helper.runGitCommand("-C", helper.RepoDir, "branch", "untouched", "revisited")
+
+	repo := NewRepository(helper.RepoDir)
+	branches, err := repo.ListLocalBranches()
+	if err != nil {
+		t.Fatalf("ListLocalBranches() error = %v", err)
+	}
+
+	ages := func(basis AgeBasis) map[string]time.Duration {
+		if err := repo.ApplyAgeBasis(branches, basis); err != nil {
+			t.Fatalf("ApplyAgeBasis(%s) error = %v", basis, err)
+		}
+		result := map[string]time.Duration{}
+		for _, branch := range branches {
+			result[branch.Name] = branch.Age()
+		}
+		return result
+	}
+
+	old := 99 * 24 * time.Hour
+	if got := ages(AgeByCommitter); got["revisited"] < old || got["untouched"] < old {
+		t.Errorf("committer ages = %v, want both over 99 days", got)
+	}
+	if got := ages(AgeByLastCheckout); got["revisited"] > time.Hour || got["untouched"] < old {
+		t.Errorf("last-checkout ages = %v, want revisited fresh and untouched falling back to its commit", got)
+	}
+	if got := ages(AgeByCreation); got["revisited"] > time.Hour || got["untouched"] > time.Hour {
+		t.Errorf("created ages = %v, want both fresh", got)
+	}
+
+	// A commit after the last checkout counts, as the committer basis would
+	helper.CheckoutBranch("untouched")
+	future := time.Now().Add(48 * time.Hour).Format(time.RFC3339)
+	cmd := exec.Command("git", "-C", helper.RepoDir, "commit", "--allow-empty", "-m", "Later work")
+	cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+future)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, output)
+	}
+	helper.CheckoutBranch(initial)
+
+	if branches, err = repo.ListLocalBranches(); err != nil {
+		t.Fatalf("ListLocalBranches() error = %v", err)
+	}
+	if got := ages(AgeByLastCheckout); got["untouched"] > -time.Hour {
+		t.Errorf("last-checkout age of untouched = %v, want it counted from its later commit", got["untouched"])
+	}
+}
+
+func TestIntegration_Unpushed(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	helper.CreateBranchWithCommit("feature-pushed", "Pushed work")
+	helper.CreateBranchWithCommit("feature-ahead", "Pushed first")
+	helper.AddBareRemote("origin")
+
+	// One commit on top of what was pushed, and a branch never pushed at all
+	initial := helper.GetCurrentBranch()
+	helper.CheckoutBranch("feature-ahead")
+	helper.runGitCommand("-C", helper.RepoDir, "commit", "--allow-empty", "-m", "Not pushed yet")
+	helper.CheckoutBranch(initial)
+	helper.CreateBranchWithCommit("feature-local", "Local only")
+
+	// Two branches sharing a commit nobody else has
+	helper.CreateBranchWithCommit("feature-a", "Shared")
+	helper.runGitCommand("-C", helper.RepoDir, "branch", "feature-b", "feature-a")
+
+	repo := NewRepository(helper.RepoDir)
+	branches, err := repo.ListLocalBranches()
+	if err != nil {
+		t.Fatalf("ListLocalBranches() error = %v", err)
+	}
+	byName := map[string]*Branch{}
+	for _, branch := range branches {
+		byName[branch.Name] = branch
+	}
+
+	check := func(batch []*Branch, want map[string]int) {
+		t.Helper()
+		if err := repo.ApplyUnpushed(branches, batch); err != nil {
+			t.Fatalf("ApplyUnpushed() error = %v", err)
+		}
+		for name, count := range want {
+			if got := byName[name].Unpushed; got != count {
+				t.Errorf("%s has %d unpushed commit(s), want %d", name, got, count)
+			}
+		}
+	}
+
+	// Deleted together, both branches would take the shared commit along
+	check([]*Branch{byName["feature-a"], byName["feature-b"], byName["feature-pushed"]},
+		map[string]int{"feature-pushed": 0, "feature-ahead": 1, "feature-local": 1, "feature-a": 1, "feature-b": 1})
+
+	// A branch that stays keeps the commit safe, but not if it went as well
+	check([]*Branch{byName["feature-a"]}, map[string]int{"feature-a": 0, "feature-b": 1})
+}
+
+func TestIntegration_QuarantineRescue(t *testing.T) {
+	helper := NewTestHelper(t)
+	helper.InitRepo()
+	helper.CreateInitialCommit()
+	helper.CreateBranchWithAge("feature-old", 100)
+	helper.AddBareRemote("origin")
+
+	repo := NewRepository(helper.RepoDir)
+	store, err := quarantine.Load(filepath.Join(helper.TempDir, "quarantine.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	commit, err := repo.ResolveCommit("refs/remotes/origin/feature-old")
+	if err != nil {
+		t.Fatalf("ResolveCommit() error = %v", err)
+	}
+	if err := repo.RenameRemoteBranch("origin", "feature-old", quarantine.Namespace+"feature-old"); err != nil {
+		t.Fatalf("RenameRemoteBranch() error = %v", err)
+	}
+	store.Add(quarantine.Entry{Remote: "origin", Branch: "feature-old", Commit: commit, QuarantinedAt: time.Now()})
+
+	// The owner pushes the branch back as it was
+	helper.runGitCommand("-C", helper.RepoDir, "push", "origin", commit+":refs/heads/feature-old")
+	if err := repo.Fetch("origin"); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if _, err := repo.ResolveCommit("refs/remotes/origin/feature-old"); err != nil {
+		t.Fatalf("rescued branch not found: %v", err)
+	}
+	store.MarkRescued("origin", "feature-old", time.Now())
+
+	// The next quarantine run still sees an old tip, but must spare the branch
+	branches, err := repo.ListRemoteBranches("origin")
+	if err != nil {
+		t.Fatalf("ListRemoteBranches() error = %v", err)
+	}
+	grace := 14 * 24 * time.Hour
+	for _, branch := range branches {
+		if branch.Name != "feature-old" {
+			continue
+		}
+		if !branch.IsStale(grace) {
+			t.Fatalf("rescued branch should still look stale, age %v", branch.Age())
+		}
+		if !store.RecentlyRescued("origin", branch.Name, grace) {
+			t.Error("rescued branch would be quarantined again right away")
+		}
+		return
+	}
+	t.Error("feature-old is missing from the remote branches")
+}
@@ -0,0 +1,222 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Operation describes a Git operation that was started but not yet finished
+type Operation struct {
+	Name     string   // e.g., "rebase", "merge", "cherry-pick", "revert", "bisect"
+	Branches []string // Branches the operation is based on, remote ones prefixed with the remote name
+}
+
+// Involves reports whether the given branch takes part in the operation
+func (o *Operation) Involves(branch *Branch) bool {
+	if o == nil {
+		return false
+	}
+
+	for _, name := range o.Branches {
+		if name == branch.FullName() {
+			return true
+		}
+	}
+	return false
+}
+
+// GitDir returns the absolute path of the repository's .git directory
+func (r *Repository) GitDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// InProgressOperation detects an unfinished rebase, merge, cherry-pick, revert
+// or bisect by looking for the marker files Git leaves in the .git directory.
+// It returns nil when no operation is in progress.
+func (r *Repository) InProgressOperation() (*Operation, error) {
+	gitDir, err := r.GitDir()
+	if err != nil {
+		return nil, err
+	}
+
+	op := detectOperation(gitDir)
+	if op == nil {
+		return nil, nil
+	}
+
+	// Resolve the commits recorded in the markers to branch names
+	var branches []string
+	for _, ref := range op.Branches {
+		if strings.HasPrefix(ref, "refs/") {
+			branches = append(branches, shortRefName(ref))
+			continue
+		}
+
+		names, err := r.branchesPointingAt(ref)
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, names...)
+	}
+
+	// The branch that was checked out when the operation started is involved too
+	if current, err := r.GetCurrentBranch(); err == nil && current != "HEAD" {
+		branches = append(branches, current)
+	}
+
+	op.Branches = uniqueStrings(branches)
+	return op, nil
+}
+
+// detectOperation inspects the marker files in gitDir. The returned operation
+// lists the raw refs and commit IDs found in the markers.
+func detectOperation(gitDir string) *Operation {
+	// Interactive and merge-based rebases
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		if !pathExists(filepath.Join(gitDir, dir)) {
+			continue
+		}
+
+		name := "rebase"
+		if pathExists(filepath.Join(gitDir, dir, "applying")) {
+			name = "am"
+		}
+
+		var refs []string
+		if headName := readMarker(gitDir, dir, "head-name"); headName != "" && headName != "detached HEAD" {
+			refs = append(refs, headName)
+		}
+		if onto := readMarker(gitDir, dir, "onto"); onto != "" {
+			refs = append(refs, onto)
+		}
+		return &Operation{Name: name, Branches: refs}
+	}
+
+	if mergeHead := readMarker(gitDir, "MERGE_HEAD"); mergeHead != "" {
+		return &Operation{Name: "merge", Branches: strings.Fields(mergeHead)}
+	}
+
+	if pick := readMarker(gitDir, "CHERRY_PICK_HEAD"); pick != "" {
+		return &Operation{Name: "cherry-pick", Branches: []string{pick}}
+	}
+
+	if revert := readMarker(gitDir, "REVERT_HEAD"); revert != "" {
+		return &Operation{Name: "revert", Branches: []string{revert}}
+	}
+
+	// A multi-commit cherry-pick or revert between two steps
+	if pathExists(filepath.Join(gitDir, "sequencer")) {
+		name := "cherry-pick"
+		if strings.HasPrefix(readMarker(gitDir, "sequencer", "todo"), "revert") {
+			name = "revert"
+		}
+		return &Operation{Name: name}
+	}
+
+	if pathExists(filepath.Join(gitDir, "BISECT_LOG")) {
+		var refs []string
+		if start := readMarker(gitDir, "BISECT_START"); start != "" {
+			// BISECT_START holds the branch name (or commit) bisect will return to
+			if isCommitID(start) {
+				refs = append(refs, start)
+			} else {
+				refs = append(refs, "refs/heads/"+start)
+			}
+		}
+		return &Operation{Name: "bisect", Branches: refs}
+	}
+
+	return nil
+}
+
+// branchesPointingAt returns the local and remote branches whose tip is the given commit
+func (r *Repository) branchesPointingAt(commit string) ([]string, error) {
+	cmd := exec.Command("git", "for-each-ref", "--points-at="+commit, "--format=%(refname)", "refs/heads/", "refs/remotes/")
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve branches for %s: %w", commit, err)
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		ref := strings.TrimSpace(scanner.Text())
+		if ref == "" || strings.HasSuffix(ref, "/HEAD") {
+			continue
+		}
+		names = append(names, shortRefName(ref))
+	}
+
+	return names, scanner.Err()
+}
+
+// shortRefName strips the refs/heads/ or refs/remotes/ prefix from a full ref
+func shortRefName(ref string) string {
+	if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+		return name
+	}
+	if name, ok := strings.CutPrefix(ref, "refs/remotes/"); ok {
+		return name
+	}
+	return ref
+}
+
+// readMarker returns the trimmed contents of a file inside the git directory,
+// or an empty string if it does not exist
+func readMarker(gitDir string, elem ...string) string {
+	data, err := os.ReadFile(filepath.Join(append([]string{gitDir}, elem...)...))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// isCommitID checks whether s looks like a full hexadecimal object name
+func isCommitID(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
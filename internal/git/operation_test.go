@@ -0,0 +1,167 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectOperation(t *testing.T) {
+	const commit = "621fd4ee4e7815d2729e61c1c565fd9668a40ff4"
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantName string
+		wantRefs []string
+		wantNoOp bool
+	}{
+		{
+			name:     "idle repository",
+			files:    map[string]string{},
+			wantNoOp: true,
+		},
+		{
+			name: "interactive rebase",
+			files: map[string]string{
+				"rebase-merge/head-name": "refs/heads/feature/test\n",
+				"rebase-merge/onto":      commit + "\n",
+			},
+			wantName: "rebase",
+			wantRefs: []string{"refs/heads/feature/test", commit},
+		},
+		{
+			name: "am session",
+			files: map[string]string{
+				"rebase-apply/applying": "",
+			},
+			wantName: "am",
+		},
+		{
+			name: "merge",
+			files: map[string]string{
+				"MERGE_HEAD": commit + "\n",
+			},
+			wantName: "merge",
+			wantRefs: []string{commit},
+		},
+		{
+			name: "cherry-pick",
+			files: map[string]string{
+				"CHERRY_PICK_HEAD": commit + "\n",
+			},
+			wantName: "cherry-pick",
+			wantRefs: []string{commit},
+		},
+		{
+			name: "sequencer revert",
+			files: map[string]string{
+				"sequencer/todo": "revert " + commit + " Some change\n",
+			},
+			wantName: "revert",
+		},
+		{
+			name: "bisect started from a branch",
+			files: map[string]string{
+				"BISECT_LOG":   "git bisect start\n",
+				"BISECT_START": "feature/test\n",
+			},
+			wantName: "bisect",
+			wantRefs: []string{"refs/heads/feature/test"},
+		},
+		{
+			name: "bisect started from a detached commit",
+			files: map[string]string{
+				"BISECT_LOG":   "git bisect start\n",
+				"BISECT_START": commit + "\n",
+			},
+			wantName: "bisect",
+			wantRefs: []string{commit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitDir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(gitDir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create marker directory: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to create marker file: %v", err)
+				}
+			}
+
+			op := detectOperation(gitDir)
+			if tt.wantNoOp {
+				if op != nil {
+					t.Errorf("detectOperation() = %+v, want nil", op)
+				}
+				return
+			}
+
+			if op == nil {
+				t.Fatal("detectOperation() = nil, want an operation")
+			}
+			if op.Name != tt.wantName {
+				t.Errorf("Name = %s, want %s", op.Name, tt.wantName)
+			}
+			if len(op.Branches) != len(tt.wantRefs) {
+				t.Fatalf("Branches = %v, want %v", op.Branches, tt.wantRefs)
+			}
+			for i, ref := range tt.wantRefs {
+				if op.Branches[i] != ref {
+					t.Errorf("Branches[%d] = %s, want %s", i, op.Branches[i], ref)
+				}
+			}
+		})
+	}
+}
+
+func TestOperation_Involves(t *testing.T) {
+	op := &Operation{
+		Name:     "rebase",
+		Branches: []string{"feature/test", "origin/feature/shared"},
+	}
+
+	tests := []struct {
+		name   string
+		branch *Branch
+		want   bool
+	}{
+		{
+			name:   "local branch being rebased",
+			branch: &Branch{Name: "feature/test"},
+			want:   true,
+		},
+		{
+			name:   "remote branch at the same commit",
+			branch: &Branch{Name: "feature/shared", IsRemote: true, RemoteName: "origin"},
+			want:   true,
+		},
+		{
+			name:   "local branch with the remote's name",
+			branch: &Branch{Name: "origin/feature/test"},
+			want:   false,
+		},
+		{
+			name:   "unrelated branch",
+			branch: &Branch{Name: "feature/other"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := op.Involves(tt.branch); got != tt.want {
+				t.Errorf("Involves(%s) = %v, want %v", tt.branch.FullName(), got, tt.want)
+			}
+		})
+	}
+
+	var none *Operation
+	if none.Involves(&Branch{Name: "feature/test"}) {
+		t.Error("nil operation should not involve any branch")
+	}
+}
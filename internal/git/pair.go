@@ -0,0 +1,83 @@
+package git
+
+// BranchPair is one logical branch with its local branch, its branch on the
+// remote, or both
+type BranchPair struct {
+	Name   string // Branch name without the remote prefix
+	Local  *Branch
+	Remote *Branch
+
+	// Tracking is set when Local has Remote configured as its upstream rather
+	// than merely sharing its name
+	Tracking bool
+	Track    string // Tracking status of Local, e.g. "ahead 1" or "gone"
+}
+
+// Sides returns the branches of the pair, local first
+func (p *BranchPair) Sides() []*Branch {
+	var sides []*Branch
+	if p.Local != nil {
+		sides = append(sides, p.Local)
+	}
+	if p.Remote != nil {
+		sides = append(sides, p.Remote)
+	}
+	return sides
+}
+
+// Primary returns the local branch, or the remote one when there is none
+func (p *BranchPair) Primary() *Branch {
+	if p.Local != nil {
+		return p.Local
+	}
+	return p.Remote
+}
+
+// PairBranches matches local branches with the remote branches they track,
+// falling back to a remote branch of the same name. Branches without a
+// counterpart get a pair of their own. Local branches come first, in order,
+// followed by the remaining remote branches.
+func PairBranches(local, remote []*Branch, upstreams map[string]Upstream) []*BranchPair {
+	byName := make(map[string]*Branch, len(remote))
+	for _, branch := range remote {
+		byName[branch.FullName()] = branch
+	}
+
+	paired := map[*Branch]bool{}
+	pairs := make([]*BranchPair, 0, len(local))
+
+	// Configured upstreams win over names, so match them first
+	for _, branch := range local {
+		pair := &BranchPair{Name: branch.Name, Local: branch}
+		if upstream, ok := upstreams[branch.Name]; ok {
+			pair.Track = upstream.Track
+			if counterpart, ok := byName[upstream.Name]; ok && !paired[counterpart] {
+				pair.Remote = counterpart
+				pair.Tracking = true
+				paired[counterpart] = true
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+
+	for _, pair := range pairs {
+		if pair.Remote != nil {
+			continue
+		}
+		for _, counterpart := range remote {
+			if counterpart.Name == pair.Name && !paired[counterpart] {
+				pair.Remote = counterpart
+				paired[counterpart] = true
+				break
+			}
+		}
+	}
+
+	for _, branch := range remote {
+		if !paired[branch] {
+			pairs = append(pairs, &BranchPair{Name: branch.Name, Remote: branch})
+		}
+	}
+
+	return pairs
+}
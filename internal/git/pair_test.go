@@ -0,0 +1,67 @@
+package git
+
+import "testing"
+
+func TestPairBranches(t *testing.T) {
+	local := []*Branch{
+		{Name: "feature/tracked"},
+		{Name: "feature/renamed"},
+		{Name: "feature/same-name"},
+		{Name: "feature/gone"},
+		{Name: "scratch"},
+	}
+	remote := []*Branch{
+		{Name: "feature/tracked", IsRemote: true, RemoteName: "origin"},
+		{Name: "feature/old-name", IsRemote: true, RemoteName: "origin"},
+		{Name: "feature/same-name", IsRemote: true, RemoteName: "origin"},
+		{Name: "feature/remote-only", IsRemote: true, RemoteName: "origin"},
+	}
+	upstreams := map[string]Upstream{
+		"feature/tracked": {Name: "origin/feature/tracked", Track: "ahead 1"},
+		"feature/renamed": {Name: "origin/feature/old-name"},
+		"feature/gone":    {Name: "origin/feature/gone", Track: "gone"},
+	}
+
+	pairs := PairBranches(local, remote, upstreams)
+	if len(pairs) != 6 {
+		t.Fatalf("PairBranches() returned %d pairs, want 6", len(pairs))
+	}
+
+	tests := []struct {
+		name     string
+		remote   *Branch
+		tracking bool
+		track    string
+	}{
+		{"feature/tracked", remote[0], true, "ahead 1"},
+		{"feature/renamed", remote[1], true, ""},
+		{"feature/same-name", remote[2], false, ""},
+		{"feature/gone", nil, false, "gone"},
+		{"scratch", nil, false, ""},
+	}
+	for i, tt := range tests {
+		pair := pairs[i]
+		if pair.Name != tt.name || pair.Local != local[i] || pair.Remote != tt.remote || pair.Tracking != tt.tracking || pair.Track != tt.track {
+			t.Errorf("pairs[%d] = %+v, want %s paired with %v (tracking %v, %q)", i, pair, tt.name, tt.remote, tt.tracking, tt.track)
+		}
+	}
+
+	last := pairs[5]
+	if last.Local != nil || last.Remote != remote[3] || last.Primary() != remote[3] {
+		t.Errorf("pairs[5] = %+v, want the remote-only branch", last)
+	}
+	if sides := pairs[0].Sides(); len(sides) != 2 || sides[0] != local[0] || sides[1] != remote[0] {
+		t.Errorf("Sides() = %v, want local then remote", sides)
+	}
+}
+
+func TestPairBranches_UpstreamWinsOverName(t *testing.T) {
+	local := []*Branch{{Name: "topic"}, {Name: "topic-v2"}}
+	remote := []*Branch{{Name: "topic", IsRemote: true, RemoteName: "origin"}}
+	upstreams := map[string]Upstream{"topic-v2": {Name: "origin/topic"}}
+
+	pairs := PairBranches(local, remote, upstreams)
+	if pairs[0].Remote != nil || pairs[1].Remote != remote[0] || !pairs[1].Tracking {
+		t.Errorf("PairBranches() = %+v, %+v, want origin/topic paired with the branch tracking it", pairs[0], pairs[1])
+	}
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ProtectionMatcher decides whether a branch is protected from pruning.
+// Patterns are exact branch names or may use "*" as a wildcard matching any
+// run of characters (including "/"), e.g. "release/*".
+type ProtectionMatcher struct {
+	exact    map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// NewProtectionMatcher creates a matcher for the given branch patterns
+func NewProtectionMatcher(patterns ...string) *ProtectionMatcher {
+	m := &ProtectionMatcher{exact: make(map[string]bool)}
+	m.Add(patterns...)
+	return m
+}
+
+// Add registers additional branch patterns
+func (m *ProtectionMatcher) Add(patterns ...string) {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		if !strings.Contains(pattern, "*") {
+			m.exact[pattern] = true
+			continue
+		}
+
+		// Translate the wildcard pattern into an anchored regular expression
+		parts := strings.Split(pattern, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		m.patterns = append(m.patterns, regexp.MustCompile("^"+strings.Join(parts, ".*")+"$"))
+	}
+}
+
+// Matches reports whether the branch name is protected by any pattern
+func (m *ProtectionMatcher) Matches(name string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.exact[name] {
+		return true
+	}
+
+	for _, re := range m.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package git
+
+import "testing"
+
+func TestProtectionMatcher_Matches(t *testing.T) {
+	m := NewProtectionMatcher("production", "release/*", "*-stable", "  ")
+
+	tests := []struct {
+		name   string
+		branch string
+		want   bool
+	}{
+		{name: "exact name", branch: "production", want: true},
+		{name: "exact name is not a prefix", branch: "production-hotfix", want: false},
+		{name: "wildcard suffix", branch: "release/1.0", want: true},
+		{name: "wildcard spans slashes", branch: "release/2024/q1", want: true},
+		{name: "wildcard needs the prefix", branch: "prerelease/1.0", want: false},
+		{name: "wildcard prefix", branch: "v2-stable", want: true},
+		{name: "regexp characters are literal", branch: "v2.stable", want: false},
+		{name: "unrelated branch", branch: "feature/test", want: false},
+		{name: "blank patterns are ignored", branch: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Matches(tt.branch); got != tt.want {
+				t.Errorf("Matches(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProtectionMatcher_Nil(t *testing.T) {
+	var m *ProtectionMatcher
+	if m.Matches("main") {
+		t.Error("nil matcher should not protect anything")
+	}
+}
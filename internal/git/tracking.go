@@ -0,0 +1,147 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HooksDir returns the directory git runs hooks from, honoring core.hooksPath
+func (r *Repository) HooksDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--path-format=absolute", "--git-path", "hooks")
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate hooks directory: %w", err)
+	}
+
+	return filepath.Clean(strings.TrimSpace(string(output))), nil
+}
+
+// MergedBranches returns the local branches whose tips are reachable from base
+func (r *Repository) MergedBranches(base string) ([]string, error) {
+	return r.mergedRefs(base, "refs/heads/")
+}
+
+// MergedRemoteBranches returns the branches on remote, named like
+// origin/feature, whose tips are reachable from base
+func (r *Repository) MergedRemoteBranches(remote, base string) ([]string, error) {
+	return r.mergedRefs(base, "refs/remotes/"+remote+"/")
+}
+
+// mergedRefs returns the short names of the refs under prefix whose tips are
+// reachable from base
+func (r *Repository) mergedRefs(base, prefix string) ([]string, error) {
+	cmd := exec.Command("git", "for-each-ref", "--merged", base, "--format=%(refname:short)", prefix)
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches merged into %s: %w", base, err)
+	}
+
+	return splitLines(output), nil
+}
+
+// GoneBranches returns the local branches whose upstream branch no longer exists
+func (r *Repository) GoneBranches() ([]string, error) {
+	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short)%00%(upstream:track)", "refs/heads/")
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upstream tracking status: %w", err)
+	}
+
+	var gone []string
+	for _, line := range splitLines(output) {
+		name, track, _ := strings.Cut(line, "\x00")
+		if track == "[gone]" {
+			gone = append(gone, name)
+		}
+	}
+
+	return gone, nil
+}
+
+// Upstream is the branch a local branch is configured to track
+type Upstream struct {
+	Name  string // Short name such as origin/feature
+	Track string // Tracking status such as "ahead 1, behind 2" or "gone"
+}
+
+// Upstreams returns the upstream of every local branch that has one
+func (r *Repository) Upstreams() (map[string]Upstream, error) {
+	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short)%00%(upstream:short)%00%(upstream:track,nobracket)", "refs/heads/")
+	if r.Path != "" {
+		cmd.Dir = r.Path
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upstream branches: %w", err)
+	}
+
+	upstreams := map[string]Upstream{}
+	for _, line := range splitLines(output) {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 || fields[1] == "" {
+			continue
+		}
+		upstreams[fields[0]] = Upstream{Name: fields[1], Track: fields[2]}
+	}
+
+	return upstreams, nil
+}
+
+// ApplyUnpushed counts, for each local branch, the commits that would be lost
+// if it were deleted together with the batch of branches about to go: those
+// no remote-tracking branch and no local branch outside the batch has.
+// Protected and current branches are skipped, as they are never pruned.
+func (r *Repository) ApplyUnpushed(branches, batch []*Branch) error {
+	// Branch names can't contain glob characters, so they exclude themselves
+	var excluded []string
+	for _, branch := range batch {
+		if !branch.IsRemote {
+			excluded = append(excluded, "--exclude="+branch.Name)
+		}
+	}
+
+	for _, branch := range branches {
+		branch.Unpushed = 0
+		if branch.IsRemote || branch.IsProtected || branch.IsCurrent {
+			continue
+		}
+
+		args := []string{"rev-list", "--count", branch.Ref(), "--not", "--remotes", "--exclude=" + branch.Name}
+		args = append(append(args, excluded...), "--branches")
+		output, err := r.output(args...)
+		if err != nil {
+			return fmt.Errorf("failed to count unpushed commits on %s: %w", branch.Name, err)
+		}
+		if branch.Unpushed, err = strconv.Atoi(strings.TrimSpace(output)); err != nil {
+			return fmt.Errorf("failed to count unpushed commits on %s: %w", branch.Name, err)
+		}
+	}
+	return nil
+}
+
+// splitLines splits command output into its non-empty lines
+func splitLines(output []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
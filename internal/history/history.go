@@ -0,0 +1,205 @@
+// Package history keeps an audit log of bonsai sessions: who ran which
+// command, with what settings, and what came of it
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the history file in bonsai's state directory
+const FileName = "history.jsonl"
+
+// Actions taken on branches
+const (
+	ActionDeleted     = "deleted"
+	ActionArchived    = "archived"
+	ActionQuarantined = "quarantined"
+	ActionReaped      = "reaped"
+)
+
+// Session is one run of a bonsai command
+type Session struct {
+	Number     int               `json:"number,omitempty"` // Position in the history, from 1; not stored
+	StartedAt  time.Time         `json:"started_at"`
+	Duration   string            `json:"duration"`
+	User       string            `json:"user"`
+	Command    string            `json:"command"`
+	Args       []string          `json:"args,omitempty"`
+	Flags      map[string]string `json:"flags,omitempty"` // Flags given on the command line
+	Config     Config            `json:"config"`
+	Candidates []string          `json:"candidates,omitempty"`
+	Results    []Result          `json:"results,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	ExitCode   int               `json:"exit_code"`
+
+	mu sync.Mutex
+}
+
+// Config is the configuration a session ran with. Secrets such as forge
+// tokens are left out.
+type Config struct {
+	Age       string   `json:"age"` // Age at which branches were stale
+	AgeBasis  string   `json:"age_basis,omitempty"`
+	Remote    string   `json:"remote"`
+	Protected []string `json:"protected_branches,omitempty"`
+	Forge     string   `json:"forge,omitempty"`
+}
+
+// Result is what a session did to one branch
+type Result struct {
+	Branch string `json:"branch"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"` // e.g. the archive tag or the new name
+	Error  string `json:"error,omitempty"`
+}
+
+// Start begins recording a session of command
+func Start(command string) *Session {
+	return &Session{StartedAt: time.Now(), Command: command}
+}
+
+// SetCandidates records the full names of the branches the session found to
+// prune. It does nothing without a session.
+func (s *Session) SetCandidates(names []string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Candidates = names
+}
+
+// Record adds what happened to a branch. It is safe to call from several
+// goroutines, and does nothing without a session.
+func (s *Session) Record(result Result) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Results = append(s.Results, result)
+}
+
+// Finish records how the session ended
+func (s *Session) Finish(err error, exitCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Duration = time.Since(s.StartedAt).Round(time.Millisecond).String()
+	s.ExitCode = exitCode
+	if err != nil {
+		s.Error = err.Error()
+	}
+}
+
+// Failed returns the number of branches the session failed to handle
+func (s *Session) Failed() int {
+	count := 0
+	for _, result := range s.Results {
+		if result.Error != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// Touches reports whether the session found or handled a branch matching
+// pattern, a full name such as origin/feature or a glob such as feature/*
+func (s *Session) Touches(pattern string) bool {
+	matches := func(name string) bool {
+		if name == pattern || strings.TrimPrefix(name, s.Config.Remote+"/") == pattern {
+			return true
+		}
+		matched, _ := filepath.Match(pattern, name)
+		return matched
+	}
+
+	for _, name := range s.Candidates {
+		if matches(name) {
+			return true
+		}
+	}
+	for _, result := range s.Results {
+		if matches(result.Branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// Append adds a finished session to the history file at path
+func Append(path string, s *Session) error {
+	s.mu.Lock()
+	data, err := json.Marshal(s)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Load reads every session in the history file at path, oldest first. A
+// missing file is an empty history; lines that can't be parsed are skipped.
+func Load(path string) ([]*Session, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer file.Close()
+
+	var sessions []*Session
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		session := &Session{}
+		if err := json.Unmarshal(scanner.Bytes(), session); err != nil {
+			continue
+		}
+		session.Number = number
+		sessions = append(sessions, session)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return sessions, nil
+}
+
+// Filter picks sessions by when they ran and which branches they touched
+type Filter struct {
+	Since  time.Time // Zero for no lower bound
+	Until  time.Time // Zero for no upper bound
+	Branch string    // Name or glob; empty for any branch
+}
+
+// Match reports whether the session passes the filter
+func (f Filter) Match(s *Session) bool {
+	if !f.Since.IsZero() && s.StartedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && s.StartedAt.After(f.Until) {
+		return false
+	}
+	return f.Branch == "" || s.Touches(f.Branch)
+}
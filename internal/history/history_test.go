@@ -0,0 +1,107 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	first := Start("local")
+	first.SetCandidates([]string{"feature/a", "feature/b"})
+	first.Record(Result{Branch: "feature/a", Action: ActionDeleted})
+	first.Record(Result{Branch: "feature/b", Action: ActionDeleted, Error: "not fully merged"})
+	first.Finish(errors.New("1 of 2 branch(es) could not be pruned"), 3)
+
+	second := Start("remote")
+	second.Config.Remote = "origin"
+	second.Finish(nil, 0)
+
+	for _, s := range []*Session{first, second} {
+		if err := Append(path, s); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	// A damaged line is skipped but still counts towards the numbering
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("{not json\n")
+	file.Close()
+	if err := Append(path, Start("all")); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	sessions, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("Load() returned %d sessions, want 3", len(sessions))
+	}
+
+	got := sessions[0]
+	if got.Number != 1 || got.Command != "local" || got.ExitCode != 3 || got.Failed() != 1 || len(got.Candidates) != 2 {
+		t.Errorf("first session = %+v", got)
+	}
+	if sessions[1].Number != 2 || sessions[1].Config.Remote != "origin" {
+		t.Errorf("second session = %+v", sessions[1])
+	}
+	if sessions[2].Number != 4 || sessions[2].Command != "all" {
+		t.Errorf("third session = %+v, want number 4", sessions[2])
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	sessions, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil || sessions != nil {
+		t.Errorf("Load() = %v, %v, want an empty history", sessions, err)
+	}
+}
+
+func TestRecordWithoutSession(t *testing.T) {
+	var s *Session
+	s.Record(Result{Branch: "feature/a"})
+	s.SetCandidates([]string{"feature/a"})
+}
+
+func TestFilterMatch(t *testing.T) {
+	now := time.Now()
+	s := &Session{
+		StartedAt:  now.Add(-48 * time.Hour),
+		Config:     Config{Remote: "origin"},
+		Candidates: []string{"origin/feature/login"},
+		Results:    []Result{{Branch: "fix/typo", Action: ActionDeleted}},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"no filter", Filter{}, true},
+		{"since before", Filter{Since: now.Add(-72 * time.Hour)}, true},
+		{"since after", Filter{Since: now.Add(-24 * time.Hour)}, false},
+		{"until after", Filter{Until: now}, true},
+		{"until before", Filter{Until: now.Add(-72 * time.Hour)}, false},
+		{"full remote name", Filter{Branch: "origin/feature/login"}, true},
+		{"name without remote", Filter{Branch: "feature/login"}, true},
+		{"result branch", Filter{Branch: "fix/typo"}, true},
+		{"glob", Filter{Branch: "fix/*"}, true},
+		{"other branch", Filter{Branch: "feature/other"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(s); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Package hook installs the git hooks that let bonsai react to merges and checkouts
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names lists the hooks bonsai installs
+var Names = []string{"post-merge", "post-checkout"}
+
+// Marker identifies hook scripts written by bonsai
+const Marker = "# Installed by bonsai"
+
+// ChainedSuffix is appended to an existing hook that bonsai moved aside
+const ChainedSuffix = ".pre-bonsai"
+
+// Policy decides what happens to branches that just became merged or gone
+type Policy string
+
+const (
+	PolicySuggest Policy = "suggest" // Print a one-line suggestion
+	PolicyPrune   Policy = "prune"   // Delete merged branches right away
+)
+
+// ParsePolicy validates a policy name
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case PolicySuggest, PolicyPrune:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported hook policy: %s (use suggest or prune)", s)
+	}
+}
+
+// Script returns the hook script for name. It runs any hook that was moved
+// aside first, then hands over to bonsai without ever failing the git command.
+func Script(name, executable string, policy Policy) string {
+	chained := name + ChainedSuffix
+	return fmt.Sprintf(`#!/bin/sh
+%s - remove with: bonsai hook uninstall
+hook_dir=$(dirname "$0")
+if [ -x "$hook_dir/%s" ]; then
+	"$hook_dir/%s" "$@" || exit $?
+fi
+%s hook run %s --policy %s -- "$@" || true
+`, Marker, chained, chained, shellQuote(executable), name, policy)
+}
+
+// IsInstalled reports whether the hook at path was written by bonsai
+func IsInstalled(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), Marker)
+}
+
+// Install writes the bonsai hook into dir. An existing hook that bonsai did
+// not write is moved aside and chained rather than overwritten. It reports
+// whether an existing hook was chained.
+func Install(dir, name, executable string, policy Policy) (bool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	path := filepath.Join(dir, name)
+	chained := false
+
+	if _, err := os.Stat(path); err == nil && !IsInstalled(path) {
+		target := path + ChainedSuffix
+		if _, err := os.Stat(target); err == nil {
+			return false, fmt.Errorf("cannot chain %s: %s already exists", name, target)
+		}
+		if err := os.Rename(path, target); err != nil {
+			return false, fmt.Errorf("failed to move existing %s hook aside: %w", name, err)
+		}
+		chained = true
+	}
+
+	if err := os.WriteFile(path, []byte(Script(name, executable, policy)), 0755); err != nil {
+		return chained, fmt.Errorf("failed to write %s hook: %w", name, err)
+	}
+
+	return chained, nil
+}
+
+// Uninstall removes the bonsai hook from dir and puts back the hook it had
+// chained, if any. Hooks bonsai did not write are left alone. It reports
+// whether a bonsai hook was removed.
+func Uninstall(dir, name string) (bool, error) {
+	path := filepath.Join(dir, name)
+	if !IsInstalled(path) {
+		return false, nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return false, fmt.Errorf("failed to remove %s hook: %w", name, err)
+	}
+
+	chained := path + ChainedSuffix
+	if _, err := os.Stat(chained); errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err := os.Rename(chained, path); err != nil {
+		return true, fmt.Errorf("failed to restore the original %s hook: %w", name, err)
+	}
+
+	return true, nil
+}
+
+// shellQuote quotes s for safe use in a POSIX shell script
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
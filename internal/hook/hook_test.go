@@ -0,0 +1,142 @@
+package hook
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Policy
+		wantErr bool
+	}{
+		{"suggest", PolicySuggest, false},
+		{"prune", PolicyPrune, false},
+		{"delete", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePolicy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePolicy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePolicy(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScript(t *testing.T) {
+	script := Script("post-merge", "/opt/it's here/bonsai", PolicyPrune)
+
+	if !strings.HasPrefix(script, "#!/bin/sh\n") {
+		t.Error("Script() should start with a shebang")
+	}
+	if !strings.Contains(script, Marker) {
+		t.Error("Script() should contain the bonsai marker")
+	}
+	if !strings.Contains(script, `"$hook_dir/post-merge.pre-bonsai" "$@"`) {
+		t.Error("Script() should run the chained hook")
+	}
+	if !strings.Contains(script, `'/opt/it'\''s here/bonsai' hook run post-merge --policy prune -- "$@" || true`) {
+		t.Errorf("Script() does not invoke bonsai safely:\n%s", script)
+	}
+}
+
+func TestInstallAndUninstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hooks")
+	original := "#!/bin/sh\necho existing\n"
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create hooks directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "post-merge"), []byte(original), 0755); err != nil {
+		t.Fatalf("Failed to create existing hook: %v", err)
+	}
+
+	chained, err := Install(dir, "post-merge", "bonsai", PolicySuggest)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if !chained {
+		t.Error("Install() should chain the existing hook")
+	}
+
+	moved, err := os.ReadFile(filepath.Join(dir, "post-merge"+ChainedSuffix))
+	if err != nil || string(moved) != original {
+		t.Errorf("Existing hook was not moved aside intact: %q, %v", moved, err)
+	}
+
+	// Reinstalling replaces bonsai's own hook without chaining it
+	chained, err = Install(dir, "post-merge", "bonsai", PolicyPrune)
+	if err != nil {
+		t.Fatalf("Install() again error = %v", err)
+	}
+	if chained {
+		t.Error("Install() should not chain its own hook")
+	}
+
+	if _, err := Install(dir, "post-checkout", "bonsai", PolicySuggest); err != nil {
+		t.Fatalf("Install(post-checkout) error = %v", err)
+	}
+
+	removed, err := Uninstall(dir, "post-merge")
+	if err != nil || !removed {
+		t.Fatalf("Uninstall() = %v, %v; want true, nil", removed, err)
+	}
+	restored, err := os.ReadFile(filepath.Join(dir, "post-merge"))
+	if err != nil || string(restored) != original {
+		t.Errorf("Chained hook was not restored: %q, %v", restored, err)
+	}
+
+	removed, err = Uninstall(dir, "post-checkout")
+	if err != nil || !removed {
+		t.Fatalf("Uninstall(post-checkout) = %v, %v; want true, nil", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post-checkout")); !os.IsNotExist(err) {
+		t.Error("post-checkout hook should be gone")
+	}
+
+	// Hooks bonsai did not write are left alone
+	removed, err = Uninstall(dir, "post-merge")
+	if err != nil || removed {
+		t.Errorf("Uninstall() of a foreign hook = %v, %v; want false, nil", removed, err)
+	}
+}
+
+func TestState_Update(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hook-state.json")
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+
+	// The first update only records a baseline
+	merged, gone := state.Update([]string{"feature/a"}, []string{"feature/b"})
+	if len(merged) != 0 || len(gone) != 0 {
+		t.Errorf("First Update() = %v, %v; want nothing new", merged, gone)
+	}
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+
+	merged, gone = loaded.Update([]string{"feature/c", "feature/a"}, []string{"feature/b", "feature/d"})
+	if len(merged) != 1 || merged[0] != "feature/c" {
+		t.Errorf("Update() merged = %v, want [feature/c]", merged)
+	}
+	if len(gone) != 1 || gone[0] != "feature/d" {
+		t.Errorf("Update() gone = %v, want [feature/d]", gone)
+	}
+}
@@ -0,0 +1,89 @@
+package hook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// State remembers which branches were already merged or gone the last time a
+// hook ran, so only branches that changed since are reported
+type State struct {
+	path      string
+	Merged    []string  `json:"merged"`
+	Gone      []string  `json:"gone"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoadState reads the state at path. A missing file yields an empty state.
+func LoadState(path string) (*State, error) {
+	state := &State{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse hook state: %w", err)
+	}
+
+	return state, nil
+}
+
+// Save writes the state back to disk
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write hook state: %w", err)
+	}
+	return nil
+}
+
+// Update records the currently merged and gone branches and returns the ones
+// that were not merged or gone before. The first update only records a baseline.
+func (s *State) Update(merged, gone []string) (newlyMerged, newlyGone []string) {
+	if !s.UpdatedAt.IsZero() {
+		newlyMerged = difference(merged, s.Merged)
+		newlyGone = difference(gone, s.Gone)
+	}
+
+	s.Merged = sorted(merged)
+	s.Gone = sorted(gone)
+	s.UpdatedAt = time.Now()
+
+	return newlyMerged, newlyGone
+}
+
+// difference returns the names in a that are not in b
+func difference(a, b []string) []string {
+	seen := make(map[string]bool, len(b))
+	for _, name := range b {
+		seen[name] = true
+	}
+
+	var result []string
+	for _, name := range a {
+		if !seen[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// sorted returns a sorted copy of names
+func sorted(names []string) []string {
+	result := append([]string(nil), names...)
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,74 @@
+package prune
+
+import (
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+// Candidate reports whether a branch qualifies for pruning at the age
+// threshold, recording why on the branch when it does
+func Candidate(branch *git.Branch, threshold time.Duration) bool {
+	// Never the current branch or a protected one
+	if branch.IsCurrent || branch.IsProtected {
+		return false
+	}
+
+	// Pinned branches are left alone, snoozed ones until the snooze ends
+	if branch.IsHeld() {
+		return false
+	}
+
+	// An open pull request means the branch is still in review
+	pr := branch.PullRequest
+	if pr != nil && pr.State == git.PullRequestOpen {
+		return false
+	}
+
+	// A merged or closed pull request qualifies the branch regardless of age
+	switch {
+	case pr != nil && pr.State == git.PullRequestMerged:
+		branch.Reason = git.ReasonPullRequestMerged
+	case pr != nil && pr.State == git.PullRequestClosed:
+		branch.Reason = git.ReasonPullRequestClosed
+	case branch.IsStale(threshold):
+		branch.Reason = git.ReasonStale
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Candidates returns the branches that qualify for pruning at the age threshold
+func Candidates(branches []*git.Branch, threshold time.Duration) []*git.Branch {
+	var candidates []*git.Branch
+	for _, branch := range branches {
+		if Candidate(branch, threshold) {
+			candidates = append(candidates, branch)
+		}
+	}
+	return candidates
+}
+
+// PairCandidate reports whether every side of a local/remote pair qualifies
+// for pruning, so a branch that is still growing on either side is left alone
+func PairCandidate(pair *git.BranchPair, threshold time.Duration) bool {
+	for _, side := range pair.Sides() {
+		if !Candidate(side, threshold) {
+			return false
+		}
+	}
+	return true
+}
+
+// PairCandidates returns the pairs that qualify for pruning at the age threshold
+func PairCandidates(pairs []*git.BranchPair, threshold time.Duration) []*git.BranchPair {
+	var candidates []*git.BranchPair
+	for _, pair := range pairs {
+		if PairCandidate(pair, threshold) {
+			candidates = append(candidates, pair)
+		}
+	}
+	return candidates
+}
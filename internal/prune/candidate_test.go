@@ -0,0 +1,62 @@
+package prune
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+func TestCandidate(t *testing.T) {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	fresh := time.Now().Add(-time.Hour)
+	threshold := 14 * 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		branch *git.Branch
+		want   bool
+		reason git.CandidateReason
+	}{
+		{"stale", &git.Branch{Name: "old", LastCommitAt: old}, true, git.ReasonStale},
+		{"fresh", &git.Branch{Name: "new", LastCommitAt: fresh}, false, ""},
+		{"current", &git.Branch{Name: "old", LastCommitAt: old, IsCurrent: true}, false, ""},
+		{"protected", &git.Branch{Name: "old", LastCommitAt: old, IsProtected: true}, false, ""},
+		{"pinned", &git.Branch{Name: "old", LastCommitAt: old, IsPinned: true}, false, ""},
+		{"snoozed", &git.Branch{Name: "old", LastCommitAt: old, SnoozedUntil: time.Now().Add(time.Hour)}, false, ""},
+		{"snooze over", &git.Branch{Name: "old", LastCommitAt: old, SnoozedUntil: time.Now().Add(-time.Hour)}, true, git.ReasonStale},
+		{"open pull request", &git.Branch{Name: "old", LastCommitAt: old, PullRequest: &git.PullRequest{State: git.PullRequestOpen}}, false, ""},
+		{"merged pull request", &git.Branch{Name: "new", LastCommitAt: fresh, PullRequest: &git.PullRequest{State: git.PullRequestMerged}}, true, git.ReasonPullRequestMerged},
+		{"closed pull request", &git.Branch{Name: "new", LastCommitAt: fresh, PullRequest: &git.PullRequest{State: git.PullRequestClosed}}, true, git.ReasonPullRequestClosed},
+	}
+
+	for _, tt := range tests {
+		if got := Candidate(tt.branch, threshold); got != tt.want {
+			t.Errorf("%s: Candidate() = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.branch.Reason != tt.reason {
+			t.Errorf("%s: Reason = %q, want %q", tt.name, tt.branch.Reason, tt.reason)
+		}
+	}
+}
+
+func TestPairCandidates(t *testing.T) {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	fresh := time.Now().Add(-time.Hour)
+
+	stale := &git.BranchPair{
+		Name:   "stale",
+		Local:  &git.Branch{Name: "stale", LastCommitAt: old},
+		Remote: &git.Branch{Name: "stale", LastCommitAt: old, IsRemote: true, RemoteName: "origin"},
+	}
+	growing := &git.BranchPair{
+		Name:   "growing",
+		Local:  &git.Branch{Name: "growing", LastCommitAt: old},
+		Remote: &git.Branch{Name: "growing", LastCommitAt: fresh, IsRemote: true, RemoteName: "origin"},
+	}
+
+	candidates := PairCandidates([]*git.BranchPair{stale, growing}, 14*24*time.Hour)
+	if len(candidates) != 1 || candidates[0] != stale {
+		t.Errorf("PairCandidates() = %v, want only the pair that is stale on both sides", candidates)
+	}
+}
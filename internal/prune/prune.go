@@ -0,0 +1,226 @@
+// Package prune removes branches, optionally preserving them first
+package prune
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/bundle"
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+// Options controls how branches are removed
+type Options struct {
+	Force   bool // Delete local branches even if they are not fully merged
+	Archive bool // Keep an archive/<branch> tag before deleting
+
+	// BundleDir, when set, receives a git bundle of each branch's commits
+	// that are not on Base, recorded in the directory's manifest
+	BundleDir string
+	Base      string
+
+	// SalvageDir, when set, receives a format-patch series of each unmerged
+	// branch's commits since its merge-base with Base, one directory per branch
+	SalvageDir string
+
+	// Record, when set, is told what happened to every branch pruned, e.g. to
+	// keep a history. It may be called from another goroutine.
+	Record func(branch *git.Branch, result *Result, err error)
+}
+
+// Result describes what happened to a pruned branch
+type Result struct {
+	ArchiveTag string // Tag the branch was archived as, if any
+	Bundle     string // Bundle file the branch was exported to, if any
+	Salvage    *Salvage
+}
+
+// Salvage describes the patch series written for a branch
+type Salvage struct {
+	Dir     string
+	Patches []string
+}
+
+// Pruner removes branches from a repository
+type Pruner struct {
+	repo     *git.Repository
+	opts     Options
+	manifest *bundle.Manifest
+}
+
+// New creates a Pruner for the repository
+func New(repo *git.Repository, opts Options) *Pruner {
+	return &Pruner{repo: repo, opts: opts}
+}
+
+// Prune removes a single branch, preserving it first when requested
+func (p *Pruner) Prune(branch *git.Branch) (*Result, error) {
+	result, err := p.prune(branch)
+	if p.opts.Record != nil {
+		p.opts.Record(branch, result, err)
+	}
+	return result, err
+}
+
+func (p *Pruner) prune(branch *git.Branch) (*Result, error) {
+	result := &Result{}
+	force := p.opts.Force
+
+	if p.opts.BundleDir != "" {
+		path, err := p.exportBundle(branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export bundle: %w", err)
+		}
+		result.Bundle = path
+	}
+
+	if p.opts.SalvageDir != "" {
+		salvage, err := p.Salvage(branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to salvage commits: %w", err)
+		}
+		result.Salvage = salvage
+	}
+
+	if p.opts.Archive {
+		tag, err := p.repo.ArchiveBranch(branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to archive: %w", err)
+		}
+		result.ArchiveTag = tag
+
+		if branch.IsRemote {
+			if err := p.repo.PushTag(branch.RemoteName, tag); err != nil {
+				return result, fmt.Errorf("failed to push archive tag %s: %w", tag, err)
+			}
+		}
+
+		// The archive tag keeps the commits reachable, so unmerged work is safe
+		force = true
+	}
+
+	var err error
+	if branch.IsRemote {
+		err = p.repo.DeleteRemoteBranch(branch.RemoteName, branch.Name)
+	} else {
+		err = p.repo.DeleteLocalBranch(branch.Name, force)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// exportBundle writes the branch's commits that are not on the base branch to
+// a bundle and records it in the manifest. Branches without such commits are
+// still recorded, but no bundle file is written for them.
+func (p *Pruner) exportBundle(branch *git.Branch) (string, error) {
+	if p.opts.Base == "" {
+		return "", fmt.Errorf("no base branch to compare %s against", branch.FullName())
+	}
+
+	if p.manifest == nil {
+		manifest, err := bundle.Load(p.opts.BundleDir)
+		if err != nil {
+			return "", err
+		}
+		p.manifest = manifest
+	}
+
+	commit, err := p.repo.ResolveCommit(branch.Ref())
+	if err != nil {
+		return "", err
+	}
+
+	unique, err := p.repo.CountUniqueCommits(branch.Ref(), p.opts.Base)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	entry := bundle.Entry{
+		Branch:        branch.Name,
+		Remote:        branch.RemoteName,
+		Ref:           branch.Ref(),
+		Commit:        commit,
+		Author:        branch.LastAuthor,
+		Base:          p.opts.Base,
+		UniqueCommits: unique,
+		ExportedAt:    now,
+	}
+
+	// Git refuses to write an empty bundle
+	path := ""
+	if unique > 0 {
+		entry.Bundle = bundle.FileName(branch.FullName(), now)
+		path = p.manifest.Path(entry)
+
+		if err := os.MkdirAll(p.manifest.Dir(), 0755); err != nil {
+			return "", fmt.Errorf("failed to create bundle directory: %w", err)
+		}
+		if err := p.repo.CreateBundle(path, branch.Ref(), p.opts.Base); err != nil {
+			return "", err
+		}
+	}
+
+	p.manifest.Add(entry)
+	if err := p.manifest.Save(); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Salvage writes the branch's commits since its merge-base with the base
+// branch as patch files into a directory of their own. It returns nil when
+// the branch has no such commits.
+func (p *Pruner) Salvage(branch *git.Branch) (*Salvage, error) {
+	if p.opts.SalvageDir == "" {
+		return nil, fmt.Errorf("no salvage directory configured")
+	}
+	if p.opts.Base == "" {
+		return nil, fmt.Errorf("no base branch to compare %s against", branch.FullName())
+	}
+
+	mergeBase, err := p.repo.MergeBase(p.opts.Base, branch.Ref())
+	if err != nil {
+		return nil, err
+	}
+
+	unique, err := p.repo.CountUniqueCommits(branch.Ref(), mergeBase)
+	if err != nil {
+		return nil, err
+	}
+	if unique == 0 {
+		return nil, nil
+	}
+
+	root, err := filepath.Abs(p.opts.SalvageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Never mix a new series into an earlier salvage of the same branch
+	name := strings.ReplaceAll(branch.FullName(), "/", "-")
+	dir := filepath.Join(root, name)
+	for i := 2; pathExists(dir); i++ {
+		dir = filepath.Join(root, fmt.Sprintf("%s-%d", name, i))
+	}
+
+	patches, err := p.repo.FormatPatch(dir, mergeBase, branch.Ref())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Salvage{Dir: dir, Patches: patches}, nil
+}
+
+// pathExists checks whether a file or directory exists
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,148 @@
+// Package quarantine records remote branches that were moved aside before deletion
+package quarantine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Namespace is the prefix quarantined branches are renamed into
+const Namespace = "stale/"
+
+// Entry describes one quarantined remote branch
+type Entry struct {
+	Remote        string    `json:"remote"`
+	Branch        string    `json:"branch"` // Original branch name, without the namespace
+	Commit        string    `json:"commit"` // Tip of the branch when it was quarantined
+	QuarantinedAt time.Time `json:"quarantined_at"`
+}
+
+// QuarantinedName returns the branch name the entry lives under on the remote
+func (e Entry) QuarantinedName() string {
+	return Namespace + e.Branch
+}
+
+// Age returns how long the branch has been in quarantine
+func (e Entry) Age() time.Duration {
+	return time.Since(e.QuarantinedAt)
+}
+
+// IsQuarantined reports whether a branch name lives in the quarantine namespace
+func IsQuarantined(name string) bool {
+	return strings.HasPrefix(name, Namespace)
+}
+
+// Rescue records a quarantined branch its owner pushed back
+type Rescue struct {
+	Remote    string    `json:"remote"`
+	Branch    string    `json:"branch"`
+	RescuedAt time.Time `json:"rescued_at"`
+}
+
+// Store is the on-disk list of quarantined and rescued branches
+type Store struct {
+	path    string
+	Entries []Entry  `json:"entries"`
+	Rescued []Rescue `json:"rescued,omitempty"`
+}
+
+// Load reads the store at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine records: %w", err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse quarantine records: %w", err)
+	}
+
+	return store, nil
+}
+
+// Save writes the store back to disk
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write quarantine records: %w", err)
+	}
+	return nil
+}
+
+// Add records a quarantined branch, replacing any previous record for it
+func (s *Store) Add(entry Entry) {
+	s.Remove(entry.Remote, entry.Branch)
+	s.Entries = append(s.Entries, entry)
+}
+
+// Remove drops the record for a branch
+func (s *Store) Remove(remote, branch string) {
+	kept := s.Entries[:0]
+	for _, e := range s.Entries {
+		if e.Remote != remote || e.Branch != branch {
+			kept = append(kept, e)
+		}
+	}
+	s.Entries = kept
+}
+
+// MarkRescued drops the record of a quarantined branch whose owner pushed it
+// back and remembers when that happened
+func (s *Store) MarkRescued(remote, branch string, at time.Time) {
+	s.Remove(remote, branch)
+
+	kept := s.Rescued[:0]
+	for _, r := range s.Rescued {
+		if r.Remote != remote || r.Branch != branch {
+			kept = append(kept, r)
+		}
+	}
+	s.Rescued = append(kept, Rescue{Remote: remote, Branch: branch, RescuedAt: at})
+}
+
+// RecentlyRescued reports whether a branch was rescued less than grace ago.
+// A rescued branch keeps its old tip, so it would otherwise look stale and
+// be quarantined again right away.
+func (s *Store) RecentlyRescued(remote, branch string, grace time.Duration) bool {
+	for _, r := range s.Rescued {
+		if r.Remote == remote && r.Branch == branch && time.Since(r.RescuedAt) < grace {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpireRescues forgets the branches rescued more than grace ago
+func (s *Store) ExpireRescues(grace time.Duration) {
+	kept := s.Rescued[:0]
+	for _, r := range s.Rescued {
+		if time.Since(r.RescuedAt) < grace {
+			kept = append(kept, r)
+		}
+	}
+	s.Rescued = kept
+}
+
+// ForRemote returns the records belonging to a remote
+func (s *Store) ForRemote(remote string) []Entry {
+	var entries []Entry
+	for _, e := range s.Entries {
+		if e.Remote == remote {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,110 @@
+package quarantine
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quarantine.json")
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() on a missing file error = %v", err)
+	}
+	if len(store.Entries) != 0 {
+		t.Fatalf("Load() on a missing file returned %d entries, want 0", len(store.Entries))
+	}
+
+	quarantinedAt := time.Now().Add(-3 * 24 * time.Hour).Truncate(time.Second)
+	store.Add(Entry{Remote: "origin", Branch: "feature/old", Commit: "abc123", QuarantinedAt: quarantinedAt})
+	store.Add(Entry{Remote: "upstream", Branch: "feature/old", Commit: "def456", QuarantinedAt: quarantinedAt})
+
+	// Adding the same branch again replaces the record
+	store.Add(Entry{Remote: "origin", Branch: "feature/old", Commit: "fff999", QuarantinedAt: quarantinedAt})
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	origin := loaded.ForRemote("origin")
+	if len(origin) != 1 {
+		t.Fatalf("ForRemote(origin) returned %d entries, want 1", len(origin))
+	}
+	if origin[0].Commit != "fff999" {
+		t.Errorf("Commit = %s, want fff999", origin[0].Commit)
+	}
+	if !origin[0].QuarantinedAt.Equal(quarantinedAt) {
+		t.Errorf("QuarantinedAt = %v, want %v", origin[0].QuarantinedAt, quarantinedAt)
+	}
+	if origin[0].QuarantinedName() != "stale/feature/old" {
+		t.Errorf("QuarantinedName() = %s, want stale/feature/old", origin[0].QuarantinedName())
+	}
+
+	loaded.Remove("origin", "feature/old")
+	if len(loaded.ForRemote("origin")) != 0 {
+		t.Error("Remove() did not drop the origin record")
+	}
+	if len(loaded.ForRemote("upstream")) != 1 {
+		t.Error("Remove() dropped a record of another remote")
+	}
+}
+
+func TestIsQuarantined(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "stale/feature/old", want: true},
+		{name: "feature/stale/old", want: false},
+		{name: "stale", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsQuarantined(tt.name); got != tt.want {
+			t.Errorf("IsQuarantined(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStore_Rescued(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quarantine.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grace := 14 * 24 * time.Hour
+	store.Add(Entry{Remote: "origin", Branch: "feature/new", QuarantinedAt: time.Now()})
+	store.MarkRescued("origin", "feature/new", time.Now())
+	store.MarkRescued("origin", "feature/old", time.Now().Add(-2*grace))
+
+	if len(store.ForRemote("origin")) != 0 {
+		t.Error("MarkRescued() kept the quarantine record")
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !loaded.RecentlyRescued("origin", "feature/new", grace) {
+		t.Error("feature/new should be exempt from quarantine")
+	}
+	if loaded.RecentlyRescued("upstream", "feature/new", grace) || loaded.RecentlyRescued("origin", "feature/old", grace) {
+		t.Error("RecentlyRescued() matched another remote or an expired rescue")
+	}
+
+	loaded.ExpireRescues(grace)
+	if len(loaded.Rescued) != 1 || loaded.Rescued[0].Branch != "feature/new" {
+		t.Errorf("ExpireRescues() left %+v, want only feature/new", loaded.Rescued)
+	}
+}
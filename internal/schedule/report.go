@@ -0,0 +1,137 @@
+package schedule
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Report records what a scheduled run found and did
+type Report struct {
+	StartedAt    time.Time
+	Duration     time.Duration
+	Repositories []*RepositoryReport
+}
+
+// RepositoryReport records the outcome for one repository
+type RepositoryReport struct {
+	Path       string
+	Unattended bool   // Whether the policy allowed deletions
+	Skipped    string // Why the repository was left alone, if it was
+	Error      string
+	Candidates []Candidate
+}
+
+// Candidate is a branch the run picked for pruning
+type Candidate struct {
+	Name       string
+	Age        time.Duration
+	Reason     string
+	Pruned     bool
+	ArchiveTag string
+	Kept       string // Why the run left the branch alone, if it did
+	Error      string
+}
+
+// Failed reports whether the repository could not be processed or a deletion failed
+func (r *RepositoryReport) Failed() bool {
+	if r.Error != "" {
+		return true
+	}
+	for _, c := range r.Candidates {
+		if c.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Failures returns the number of repositories with errors
+func (r *Report) Failures() int {
+	count := 0
+	for _, repo := range r.Repositories {
+		if repo.Failed() {
+			count++
+		}
+	}
+	return count
+}
+
+// Write renders the report as plain text
+func (r *Report) Write(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "bonsai scheduled run - %s (took %s)\n", r.StartedAt.Format(time.RFC3339), r.Duration.Round(time.Second))
+
+	for _, repo := range r.Repositories {
+		fmt.Fprintf(&b, "\n%s\n", repo.Path)
+
+		switch {
+		case repo.Error != "":
+			fmt.Fprintf(&b, "  error: %s\n", repo.Error)
+			continue
+		case repo.Skipped != "":
+			fmt.Fprintf(&b, "  skipped: %s\n", repo.Skipped)
+			continue
+		case len(repo.Candidates) == 0:
+			b.WriteString("  no stale branches\n")
+			continue
+		}
+
+		if !repo.Unattended {
+			b.WriteString("  report only: set schedule.unattended to true to let scheduled runs delete branches\n")
+		}
+
+		for _, c := range repo.Candidates {
+			status := "would prune"
+			switch {
+			case c.Error != "":
+				status = "failed: " + c.Error
+			case c.Kept != "":
+				status = "kept: " + c.Kept
+			case c.Pruned && c.ArchiveTag != "":
+				status = "pruned, archived as " + c.ArchiveTag
+			case c.Pruned:
+				status = "pruned"
+			}
+			fmt.Fprintf(&b, "  - %s (%s, %d days old): %s\n", c.Name, c.Reason, int(c.Age.Hours()/24), status)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Save writes the report into dir and returns the path of the file
+func (r *Report) Save(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("report-%s.txt", r.StartedAt.UTC().Format("20060102T150405Z")))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	defer file.Close()
+
+	if err := r.Write(file); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return path, nil
+}
+
+// DefaultReportDir returns where reports go when no directory is configured
+func DefaultReportDir() (string, error) {
+	if state := os.Getenv("XDG_STATE_HOME"); state != "" {
+		return filepath.Join(state, "bonsai", "reports"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "bonsai", "reports"), nil
+}
@@ -0,0 +1,73 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	report := &Report{
+		StartedAt: time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC),
+		Duration:  4 * time.Second,
+		Repositories: []*RepositoryReport{
+			{
+				Path: "/src/app",
+				Candidates: []Candidate{
+					{Name: "feature/old", Age: 40 * 24 * time.Hour, Reason: "stale"},
+				},
+			},
+			{
+				Path:       "/src/api",
+				Unattended: true,
+				Candidates: []Candidate{
+					{Name: "feature/done", Age: 20 * 24 * time.Hour, Reason: "pull request merged", Pruned: true, ArchiveTag: "archive/feature/done"},
+					{Name: "feature/wip", Age: 30 * 24 * time.Hour, Reason: "stale", Error: "not fully merged"},
+					{Name: "feature/local", Age: 50 * 24 * time.Hour, Reason: "stale", Kept: "2 unpushed commit(s)"},
+				},
+			},
+			{Path: "/src/busy", Skipped: "a rebase is in progress"},
+			{Path: "/src/clean"},
+		},
+	}
+
+	if got := report.Failures(); got != 1 {
+		t.Errorf("Failures() = %d, want 1", got)
+	}
+
+	var b strings.Builder
+	if err := report.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	text := b.String()
+
+	for _, want := range []string{
+		"bonsai scheduled run - 2024-03-01T03:00:00Z (took 4s)",
+		"report only: set schedule.unattended to true",
+		"- feature/old (stale, 40 days old): would prune",
+		"- feature/done (pull request merged, 20 days old): pruned, archived as archive/feature/done",
+		"- feature/wip (stale, 30 days old): failed: not fully merged",
+		"- feature/local (stale, 50 days old): kept: 2 unpushed commit(s)",
+		"skipped: a rebase is in progress",
+		"no stale branches",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Write() output is missing %q:\n%s", want, text)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	path, err := report.Save(dir)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if filepath.Base(path) != "report-20240301T030000Z.txt" {
+		t.Errorf("Save() path = %s", path)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil || string(saved) != text {
+		t.Errorf("Saved report differs from written one: %v", err)
+	}
+}
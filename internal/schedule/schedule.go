@@ -0,0 +1,337 @@
+// Package schedule generates and installs the systemd timers and cron entries
+// that run bonsai periodically
+package schedule
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// UnitName is the name shared by the generated systemd service and timer
+const UnitName = "bonsai-prune"
+
+// CronMarker tags the crontab line bonsai manages
+const CronMarker = "# bonsai schedule"
+
+// Spec describes a periodic bonsai run
+type Spec struct {
+	Every   time.Duration
+	Command []string // The bonsai executable followed by its arguments
+}
+
+// SystemdService returns the oneshot service unit that performs a run
+func SystemdService(spec Spec) string {
+	args := make([]string, len(spec.Command))
+	for i, arg := range spec.Command {
+		args[i] = systemdQuote(arg)
+	}
+
+	return fmt.Sprintf(`[Unit]
+Description=Prune stale branches with bonsai
+
+[Service]
+Type=oneshot
+ExecStart=%s
+`, strings.Join(args, " "))
+}
+
+// SystemdTimer returns the timer unit that triggers the service
+func SystemdTimer(spec Spec) string {
+	return fmt.Sprintf(`[Unit]
+Description=Run bonsai every %s
+
+[Timer]
+OnBootSec=15min
+OnUnitActiveSec=%ds
+Unit=%s.service
+
+[Install]
+WantedBy=timers.target
+`, Describe(spec.Every), int64(spec.Every/time.Second), UnitName)
+}
+
+// Describe renders an interval in the largest whole unit, e.g. "2 weeks"
+func Describe(every time.Duration) string {
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{7 * 24 * time.Hour, "week"},
+		{24 * time.Hour, "day"},
+		{time.Hour, "hour"},
+		{time.Minute, "minute"},
+	}
+
+	for _, unit := range units {
+		if every >= unit.size && every%unit.size == 0 {
+			count := int(every / unit.size)
+			if count == 1 {
+				return "1 " + unit.name
+			}
+			return fmt.Sprintf("%d %ss", count, unit.name)
+		}
+	}
+	return every.String()
+}
+
+// CronExpression converts an interval to the closest cron schedule. Cron can
+// only express intervals that divide an hour, a day or a month evenly, so
+// day intervals restart at the beginning of each month.
+func CronExpression(every time.Duration) (string, error) {
+	const day = 24 * time.Hour
+
+	unsupported := fmt.Errorf("cron cannot run something every %s; use the systemd backend instead", every)
+
+	switch {
+	case every < time.Minute || every%time.Minute != 0:
+		return "", unsupported
+	case every < time.Hour:
+		minutes := int(every / time.Minute)
+		if 60%minutes != 0 {
+			return "", unsupported
+		}
+		return fmt.Sprintf("*/%d * * * *", minutes), nil
+	case every < day:
+		if every%time.Hour != 0 || 24%int(every/time.Hour) != 0 {
+			return "", unsupported
+		}
+		if every == time.Hour {
+			return "0 * * * *", nil
+		}
+		return fmt.Sprintf("0 */%d * * *", int(every/time.Hour)), nil
+	case every%day != 0:
+		return "", unsupported
+	}
+
+	days := int(every / day)
+	switch {
+	case days == 1:
+		return "0 3 * * *", nil
+	case days == 7:
+		return "0 3 * * 0", nil
+	case days == 365:
+		return "0 3 1 1 *", nil
+	case days%30 == 0 && 12%(days/30) == 0:
+		if days == 30 {
+			return "0 3 1 * *", nil
+		}
+		return fmt.Sprintf("0 3 1 */%d *", days/30), nil
+	case days <= 28:
+		return fmt.Sprintf("0 3 */%d * *", days), nil
+	default:
+		return "", unsupported
+	}
+}
+
+// CronLine returns the crontab line that performs a run
+func CronLine(spec Spec) (string, error) {
+	expression, err := CronExpression(spec.Every)
+	if err != nil {
+		return "", err
+	}
+
+	args := make([]string, len(spec.Command))
+	for i, arg := range spec.Command {
+		args[i] = shellQuote(arg)
+	}
+
+	// Cron turns unescaped % signs into newlines
+	command := strings.ReplaceAll(strings.Join(args, " "), "%", `\%`)
+
+	return fmt.Sprintf("%s %s %s", expression, command, CronMarker), nil
+}
+
+// WithCronLine returns the crontab with bonsai's line replaced by line
+func WithCronLine(crontab, line string) string {
+	result := WithoutCronLine(crontab)
+	if result != "" && !strings.HasSuffix(result, "\n") {
+		result += "\n"
+	}
+	return result + line + "\n"
+}
+
+// WithoutCronLine returns the crontab without bonsai's line
+func WithoutCronLine(crontab string) string {
+	var kept []string
+	for _, line := range strings.SplitAfter(crontab, "\n") {
+		if !strings.Contains(line, CronMarker) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "")
+}
+
+// SystemdAvailable reports whether a systemd user instance can be managed
+func SystemdAvailable() bool {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return false
+	}
+	return exec.Command("systemctl", "--user", "show-environment").Run() == nil
+}
+
+// SystemdUnitDir returns the directory systemd reads user units from
+func SystemdUnitDir() (string, error) {
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		return filepath.Join(xdgConfig, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+// InstallSystemd writes the service and timer units and starts the timer.
+// It returns the path of the timer unit.
+func InstallSystemd(spec Spec) (string, error) {
+	dir, err := SystemdUnitDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	servicePath := filepath.Join(dir, UnitName+".service")
+	timerPath := filepath.Join(dir, UnitName+".timer")
+	if err := os.WriteFile(servicePath, []byte(SystemdService(spec)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", servicePath, err)
+	}
+	if err := os.WriteFile(timerPath, []byte(SystemdTimer(spec)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", timerPath, err)
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return "", err
+	}
+	if err := systemctl("enable", "--now", UnitName+".timer"); err != nil {
+		return "", err
+	}
+
+	return timerPath, nil
+}
+
+// UninstallSystemd stops the timer and removes both units. It reports
+// whether anything was installed.
+func UninstallSystemd() (bool, error) {
+	dir, err := SystemdUnitDir()
+	if err != nil {
+		return false, err
+	}
+
+	timerPath := filepath.Join(dir, UnitName+".timer")
+	if _, err := os.Stat(timerPath); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err := systemctl("disable", "--now", UnitName+".timer"); err != nil {
+		return false, err
+	}
+	for _, path := range []string{timerPath, filepath.Join(dir, UnitName+".service")} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+
+	return true, systemctl("daemon-reload")
+}
+
+// InstallCron adds (or replaces) bonsai's line in the user's crontab
+func InstallCron(spec Spec) (string, error) {
+	line, err := CronLine(spec)
+	if err != nil {
+		return "", err
+	}
+
+	crontab, err := readCrontab()
+	if err != nil {
+		return "", err
+	}
+
+	return line, writeCrontab(WithCronLine(crontab, line))
+}
+
+// UninstallCron removes bonsai's line from the user's crontab. It reports
+// whether the line was present.
+func UninstallCron() (bool, error) {
+	crontab, err := readCrontab()
+	if err != nil {
+		return false, err
+	}
+
+	updated := WithoutCronLine(crontab)
+	if updated == crontab {
+		return false, nil
+	}
+
+	return true, writeCrontab(updated)
+}
+
+// readCrontab returns the user's crontab, which is empty if there is none yet
+func readCrontab() (string, error) {
+	if _, err := exec.LookPath("crontab"); err != nil {
+		return "", fmt.Errorf("crontab is not installed")
+	}
+
+	output, err := exec.Command("crontab", "-l").Output()
+	if err != nil {
+		// crontab -l fails when the user has no crontab
+		return "", nil
+	}
+	return string(output), nil
+}
+
+// writeCrontab replaces the user's crontab
+func writeCrontab(crontab string) error {
+	cmd := exec.Command("crontab", "-")
+	cmd.Stdin = strings.NewReader(crontab)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		errorMsg := strings.TrimSpace(string(output))
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("failed to update crontab: %s", errorMsg)
+	}
+	return nil
+}
+
+// systemctl runs a systemctl --user command
+func systemctl(args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		errorMsg := strings.TrimSpace(stderr.String())
+		if errorMsg == "" {
+			errorMsg = err.Error()
+		}
+		return fmt.Errorf("systemctl --user %s: %s", strings.Join(args, " "), errorMsg)
+	}
+	return nil
+}
+
+// shellQuote quotes s for a POSIX shell when it contains special characters
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`&;|<>()*?[]#~%") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// systemdQuote quotes s for an ExecStart= line when it contains special characters
+func systemdQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\%$;") {
+		return s
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(s)
+	return `"` + escaped + `"`
+}
@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronExpression(t *testing.T) {
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		every   time.Duration
+		want    string
+		wantErr bool
+	}{
+		{15 * time.Minute, "*/15 * * * *", false},
+		{time.Hour, "0 * * * *", false},
+		{6 * time.Hour, "0 */6 * * *", false},
+		{day, "0 3 * * *", false},
+		{3 * day, "0 3 */3 * *", false},
+		{7 * day, "0 3 * * 0", false},
+		{14 * day, "0 3 */14 * *", false},
+		{30 * day, "0 3 1 * *", false},
+		{90 * day, "0 3 1 */3 *", false},
+		{365 * day, "0 3 1 1 *", false},
+		{30 * time.Second, "", true},
+		{7 * time.Minute, "", true},
+		{5 * time.Hour, "", true},
+		{36 * time.Hour, "", true},
+		{45 * day, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.every.String(), func(t *testing.T) {
+			got, err := CronExpression(tt.every)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CronExpression(%v) error = %v, wantErr %v", tt.every, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CronExpression(%v) = %q, want %q", tt.every, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronLine(t *testing.T) {
+	spec := Spec{
+		Every:   7 * 24 * time.Hour,
+		Command: []string{"/opt/my tools/bonsai", "--repo", "/src/100%", "schedule", "run"},
+	}
+
+	line, err := CronLine(spec)
+	if err != nil {
+		t.Fatalf("CronLine() error = %v", err)
+	}
+
+	want := `0 3 * * 0 '/opt/my tools/bonsai' --repo '/src/100\%' schedule run # bonsai schedule`
+	if line != want {
+		t.Errorf("CronLine() = %q, want %q", line, want)
+	}
+}
+
+func TestCrontabEditing(t *testing.T) {
+	existing := "MAILTO=me@example.com\n0 1 * * * backup.sh\n0 3 * * 0 bonsai schedule run # bonsai schedule\n"
+	line := "0 3 * * * bonsai schedule run # bonsai schedule"
+
+	updated := WithCronLine(existing, line)
+	want := "MAILTO=me@example.com\n0 1 * * * backup.sh\n" + line + "\n"
+	if updated != want {
+		t.Errorf("WithCronLine() = %q, want %q", updated, want)
+	}
+
+	if got := WithCronLine("", line); got != line+"\n" {
+		t.Errorf("WithCronLine() on an empty crontab = %q", got)
+	}
+
+	// A last line without a trailing newline must not be glued to ours
+	if got := WithCronLine("0 1 * * * backup.sh", line); got != "0 1 * * * backup.sh\n"+line+"\n" {
+		t.Errorf("WithCronLine() without trailing newline = %q", got)
+	}
+
+	if got := WithoutCronLine(updated); got != "MAILTO=me@example.com\n0 1 * * * backup.sh\n" {
+		t.Errorf("WithoutCronLine() = %q", got)
+	}
+}
+
+func TestSystemdUnits(t *testing.T) {
+	spec := Spec{
+		Every:   14 * 24 * time.Hour,
+		Command: []string{"/opt/my tools/bonsai", "schedule", "run"},
+	}
+
+	service := SystemdService(spec)
+	if !strings.Contains(service, "Type=oneshot") {
+		t.Error("SystemdService() should be a oneshot service")
+	}
+	if !strings.Contains(service, `ExecStart="/opt/my tools/bonsai" schedule run`) {
+		t.Errorf("SystemdService() does not quote the executable:\n%s", service)
+	}
+
+	timer := SystemdTimer(spec)
+	if !strings.Contains(timer, "OnUnitActiveSec=1209600s") {
+		t.Errorf("SystemdTimer() has the wrong interval:\n%s", timer)
+	}
+	if !strings.Contains(timer, "Description=Run bonsai every 2 weeks") {
+		t.Errorf("SystemdTimer() has the wrong description:\n%s", timer)
+	}
+	if !strings.Contains(timer, "Unit="+UnitName+".service") {
+		t.Errorf("SystemdTimer() does not trigger the service:\n%s", timer)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		every time.Duration
+		want  string
+	}{
+		{7 * 24 * time.Hour, "1 week"},
+		{3 * 24 * time.Hour, "3 days"},
+		{36 * time.Hour, "36 hours"},
+		{90 * time.Minute, "90 minutes"},
+		{90 * time.Second, "1m30s"},
+	}
+
+	for _, tt := range tests {
+		if got := Describe(tt.every); got != tt.want {
+			t.Errorf("Describe(%v) = %q, want %q", tt.every, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package stats summarizes the health of a repository's branches without
+// changing anything
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+)
+
+// OldestLimit is how many of the oldest branches are listed
+const OldestLimit = 5
+
+// buckets are the age ranges of the histogram, each up to its upper bound
+var buckets = []struct {
+	label string
+	below time.Duration
+}{
+	{"< 1 week", 7 * 24 * time.Hour},
+	{"1-4 weeks", 28 * 24 * time.Hour},
+	{"1-3 months", 90 * 24 * time.Hour},
+	{"3-6 months", 180 * 24 * time.Hour},
+	{"6-12 months", 365 * 24 * time.Hour},
+	{"> 1 year", 0},
+}
+
+// Input is what the stats are computed from
+type Input struct {
+	Local           []*git.Branch
+	Remote          []*git.Branch
+	LocalThreshold  time.Duration   // Age at which a local branch is stale
+	RemoteThreshold time.Duration   // Age at which a remote branch is stale
+	Merged          map[string]bool // Full names of branches merged into the base branch
+}
+
+// Stats is a summary of branch health
+type Stats struct {
+	GeneratedAt   time.Time     `json:"generated_at"`
+	Remote        string        `json:"remote"`
+	BaseBranch    string        `json:"base_branch,omitempty"`
+	AgeBasis      string        `json:"age_basis"` // What ages count from
+	Local         Side          `json:"local"`
+	RemoteSide    Side          `json:"remote_branches"`
+	Ages          []Bucket      `json:"ages"`
+	StaleByAuthor []AuthorCount `json:"stale_by_author"`
+	Oldest        []BranchAge   `json:"oldest"`
+}
+
+// Side counts the branches of the local repository or of the remote
+type Side struct {
+	Total     int `json:"total"`
+	Stale     int `json:"stale"`
+	Merged    int `json:"merged"` // Merged into the base branch but not deleted
+	Protected int `json:"protected"`
+	Held      int `json:"held"` // Pinned or snoozed
+}
+
+// Bucket is one age range of the histogram
+type Bucket struct {
+	Label  string `json:"label"`
+	Local  int    `json:"local"`
+	Remote int    `json:"remote"`
+}
+
+// Total returns the number of branches in the bucket
+func (b Bucket) Total() int {
+	return b.Local + b.Remote
+}
+
+// AuthorCount is how many stale branches an author last committed to
+type AuthorCount struct {
+	Author string `json:"author"`
+	Stale  int    `json:"stale"`
+}
+
+// BranchAge is a branch and how long it has gone without commits
+type BranchAge struct {
+	Name         string    `json:"name"`
+	Author       string    `json:"author"`
+	LastCommitAt time.Time `json:"last_commit_at"`
+	AgeDays      int       `json:"age_days"`
+}
+
+// Compute summarizes the branches
+func Compute(in Input) *Stats {
+	s := &Stats{GeneratedAt: time.Now()}
+	for _, bucket := range buckets {
+		s.Ages = append(s.Ages, Bucket{Label: bucket.label})
+	}
+
+	staleByAuthor := map[string]int{}
+	var candidates []*git.Branch
+
+	count := func(side *Side, branches []*git.Branch, threshold time.Duration, isRemote bool) {
+		for _, branch := range branches {
+			side.Total++
+			switch {
+			case branch.IsProtected:
+				side.Protected++
+			case branch.IsHeld():
+				side.Held++
+			}
+			if in.Merged[branch.FullName()] && !branch.IsProtected && !branch.IsCurrent {
+				side.Merged++
+			}
+			if prune.Candidate(branch, threshold) {
+				side.Stale++
+				staleByAuthor[branch.LastAuthor]++
+			}
+
+			bucket := &s.Ages[bucketOf(branch.Age())]
+			if isRemote {
+				bucket.Remote++
+			} else {
+				bucket.Local++
+			}
+
+			if !branch.IsProtected && !branch.IsCurrent {
+				candidates = append(candidates, branch)
+			}
+		}
+	}
+	count(&s.Local, in.Local, in.LocalThreshold, false)
+	count(&s.RemoteSide, in.Remote, in.RemoteThreshold, true)
+
+	for author, stale := range staleByAuthor {
+		s.StaleByAuthor = append(s.StaleByAuthor, AuthorCount{Author: author, Stale: stale})
+	}
+	sort.Slice(s.StaleByAuthor, func(i, j int) bool {
+		a, b := s.StaleByAuthor[i], s.StaleByAuthor[j]
+		if a.Stale != b.Stale {
+			return a.Stale > b.Stale
+		}
+		return a.Author < b.Author
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ActiveAt().Before(candidates[j].ActiveAt())
+	})
+	for _, branch := range candidates[:min(len(candidates), OldestLimit)] {
+		s.Oldest = append(s.Oldest, BranchAge{
+			Name:         branch.FullName(),
+			Author:       branch.LastAuthor,
+			LastCommitAt: branch.LastCommitAt,
+			AgeDays:      int(branch.Age().Hours() / 24),
+		})
+	}
+
+	return s
+}
+
+// bucketOf returns the index of the age range age falls in
+func bucketOf(age time.Duration) int {
+	for i, bucket := range buckets {
+		if bucket.below == 0 || age < bucket.below {
+			return i
+		}
+	}
+	return len(buckets) - 1
+}
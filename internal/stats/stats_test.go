@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+const day = 24 * time.Hour
+
+func branch(name, author string, age time.Duration) *git.Branch {
+	return &git.Branch{Name: name, LastAuthor: author, LastCommitAt: time.Now().Add(-age)}
+}
+
+func remoteBranch(name, author string, age time.Duration) *git.Branch {
+	b := branch(name, author, age)
+	b.IsRemote = true
+	b.RemoteName = "origin"
+	return b
+}
+
+func TestCompute(t *testing.T) {
+	main := branch("main", "alice", 500*day)
+	main.IsProtected = true
+	main.IsCurrent = true
+	pinned := branch("spike/cache", "bob", 60*day)
+	pinned.IsPinned = true
+
+	s := Compute(Input{
+		Local: []*git.Branch{
+			main,
+			pinned,
+			branch("feature/done", "alice", 20*day),
+			branch("feature/new", "bob", 2*day),
+		},
+		Remote: []*git.Branch{
+			remoteBranch("feature/done", "alice", 20*day),
+			remoteBranch("feature/old", "carol", 400*day),
+		},
+		LocalThreshold:  14 * day,
+		RemoteThreshold: 28 * day,
+		Merged:          map[string]bool{"main": true, "feature/done": true, "origin/feature/done": true},
+	})
+
+	if want := (Side{Total: 4, Stale: 1, Merged: 1, Protected: 1, Held: 1}); s.Local != want {
+		t.Errorf("Local = %+v, want %+v", s.Local, want)
+	}
+	if want := (Side{Total: 2, Stale: 1, Merged: 1}); s.RemoteSide != want {
+		t.Errorf("RemoteSide = %+v, want %+v", s.RemoteSide, want)
+	}
+
+	wantAges := []Bucket{
+		{Label: "< 1 week", Local: 1},
+		{Label: "1-4 weeks", Local: 1, Remote: 1},
+		{Label: "1-3 months", Local: 1},
+		{Label: "3-6 months"},
+		{Label: "6-12 months"},
+		{Label: "> 1 year", Local: 1, Remote: 1},
+	}
+	for i, want := range wantAges {
+		if s.Ages[i] != want {
+			t.Errorf("Ages[%d] = %+v, want %+v", i, s.Ages[i], want)
+		}
+	}
+
+	wantAuthors := []AuthorCount{{Author: "alice", Stale: 1}, {Author: "carol", Stale: 1}}
+	if len(s.StaleByAuthor) != len(wantAuthors) {
+		t.Fatalf("StaleByAuthor = %+v, want %+v", s.StaleByAuthor, wantAuthors)
+	}
+	for i, want := range wantAuthors {
+		if s.StaleByAuthor[i] != want {
+			t.Errorf("StaleByAuthor[%d] = %+v, want %+v", i, s.StaleByAuthor[i], want)
+		}
+	}
+
+	// The protected current branch is left out of the oldest branches
+	if len(s.Oldest) != OldestLimit || s.Oldest[0].Name != "origin/feature/old" || s.Oldest[0].AgeDays != 400 {
+		t.Errorf("Oldest = %+v, want origin/feature/old first", s.Oldest)
+	}
+}
+
+func TestBucketOf(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{time.Hour, "< 1 week"},
+		{7 * day, "1-4 weeks"},
+		{60 * day, "1-3 months"},
+		{200 * day, "6-12 months"},
+		{3 * 365 * day, "> 1 year"},
+	}
+
+	for _, tt := range tests {
+		if got := buckets[bucketOf(tt.age)].label; got != tt.want {
+			t.Errorf("bucketOf(%v) = %s, want %s", tt.age, got, tt.want)
+		}
+	}
+}
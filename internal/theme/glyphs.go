@@ -0,0 +1,68 @@
+package theme
+
+import (
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// glyphs pairs each emoji and symbol bonsai prints with the plain glyph that
+// replaces it in ASCII mode
+var glyphs = [][2]string{
+	{"🌳", "*"}, {"🌿", "*"}, {"🌱", "+"}, {"🍃", "~"}, {"🌍", "@"}, {"🌀", "~"},
+	{"🛡️", "#"}, {"🗄️", "#"}, {"👁️", "o"}, {"✏️", "~"}, {"⚠️", "!"},
+	{"🪝", "^"}, {"📌", "!"}, {"💤", "z"}, {"⏰", "!"}, {"🔗", "&"}, {"👤", "@"},
+	{"💡", "?"}, {"🔎", "?"}, {"💜", "<3"}, {"🪓", "x"}, {"📜", "="}, {"💬", ">"},
+	{"💾", "#"}, {"📊", "%"}, {"⏳", "~"}, {"🪵", "|"},
+	{"🧾", "="}, {"⚙️", "*"},
+	{"✓", "+"}, {"✗", "x"}, {"•", "-"}, {"○", "o"}, {"●", "*"}, {"◐", "~"},
+	{"▸", ">"}, {"▾", "v"}, {"→", ">"}, {"↑", "^"}, {"↓", "v"}, {"⇄", "="},
+	{"█", "#"},
+	// Variation selectors left behind by emoji missing above
+	{"\uFE0F", ""},
+}
+
+// plain swaps every glyph for its plain counterpart, padded to the width
+// of what it replaces
+var plain = newPlainReplacer()
+
+func newPlainReplacer() *strings.Replacer {
+	var pairs []string
+	for _, glyph := range glyphs {
+		replacement := glyph[1]
+		if pad := lipgloss.Width(glyph[0]) - lipgloss.Width(replacement); pad > 0 {
+			replacement += strings.Repeat(" ", pad)
+		}
+		pairs = append(pairs, glyph[0], replacement)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// Text swaps emoji for plain glyphs in ASCII mode. Each glyph takes the
+// width of the emoji it replaces, so rendered boxes and columns stay aligned.
+func Text(s string) string {
+	if !ascii {
+		return s
+	}
+	return plain.Replace(s)
+}
+
+// Writer returns a writer that passes everything written through Text
+func Writer(w io.Writer) io.Writer {
+	return textWriter{w: w}
+}
+
+type textWriter struct {
+	w io.Writer
+}
+
+func (t textWriter) Write(p []byte) (int, error) {
+	if !ascii {
+		return t.w.Write(p)
+	}
+	if _, err := io.WriteString(t.w, plain.Replace(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,141 @@
+// Package theme holds how bonsai's output looks: the color palette and
+// whether emoji or plain glyphs decorate it.
+package theme
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+// Palette is the set of colors bonsai renders with
+type Palette struct {
+	Leaf      lipgloss.Color // Titles, selections and the bonsai itself
+	Accent    lipgloss.Color // Authors and quotes
+	Highlight lipgloss.Color // Branch names and borders
+	Muted     lipgloss.Color // Secondary text such as ages and hints
+	Warning   lipgloss.Color // Errors and destructive actions
+	Success   lipgloss.Color // Completed work
+	Caution   lipgloss.Color // Hints and warnings that need attention
+	Text      lipgloss.Color // Body text
+	Subtle    lipgloss.Color // Commit messages
+}
+
+var (
+	// Dark suits terminals with a dark background
+	Dark = Palette{
+		Leaf:      "#7FB069", // Fresh leaf green
+		Accent:    "#C792EA", // Charm purple accent
+		Highlight: "#89DDFF", // Soft cyan highlight
+		Muted:     "#8F8F8F", // Elegant gray
+		Warning:   "#FF6B6B", // Gentle warning red
+		Success:   "#51CF66", // Success green
+		Caution:   "#FFD43B", // Warm yellow
+		Text:      "#D0D0D0",
+		Subtle:    "#949494",
+	}
+
+	// Light suits terminals with a light background
+	Light = Palette{
+		Leaf:      "#3F7D20",
+		Accent:    "#7B3FA0",
+		Highlight: "#0B6E99",
+		Muted:     "#5F5F5F",
+		Warning:   "#C0392B",
+		Success:   "#2B8A3E",
+		Caution:   "#9C6500",
+		Text:      "#262626",
+		Subtle:    "#444444",
+	}
+
+	// HighContrast uses the brightest colors for the most legible output
+	HighContrast = Palette{
+		Leaf:      "#00FF00",
+		Accent:    "#FF55FF",
+		Highlight: "#00FFFF",
+		Muted:     "#E0E0E0",
+		Warning:   "#FF5555",
+		Success:   "#00FF00",
+		Caution:   "#FFFF00",
+		Text:      "#FFFFFF",
+		Subtle:    "#FFFFFF",
+	}
+)
+
+// palettes are the palettes that can be picked by name
+var palettes = map[string]Palette{
+	"dark":          Dark,
+	"light":         Light,
+	"high-contrast": HighContrast,
+}
+
+// Lookup returns the palette with the given name; an empty name is Dark
+func Lookup(name string) (Palette, error) {
+	if name == "" {
+		return Dark, nil
+	}
+
+	palette, ok := palettes[name]
+	if !ok {
+		return Palette{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return palette, nil
+}
+
+// Names returns the names of the available palettes
+func Names() []string {
+	names := make([]string, 0, len(palettes))
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options select how output looks
+type Options struct {
+	Palette string // Name of the palette; empty for the dark one
+	ASCII   bool   // Plain glyphs instead of emoji
+}
+
+var (
+	colors = Dark
+	ascii  bool
+)
+
+// Setup applies the options to all further output. Color is left out
+// entirely when NO_COLOR is set or standard output is not a terminal.
+func Setup(opts Options) error {
+	palette, err := Lookup(opts.Palette)
+	if err != nil {
+		return err
+	}
+
+	colors = palette
+	ascii = opts.ASCII
+
+	if NoColor() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	return nil
+}
+
+// NoColor reports whether output must not be colored
+func NoColor() bool {
+	return termenv.EnvNoColor() || !term.IsTerminal(os.Stdout.Fd())
+}
+
+// Colors returns the palette in use
+func Colors() Palette {
+	return colors
+}
+
+// ASCII reports whether plain glyphs replace emoji
+func ASCII() bool {
+	return ascii
+}
@@ -0,0 +1,84 @@
+package theme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Palette
+		wantErr bool
+	}{
+		{"", Dark, false},
+		{"dark", Dark, false},
+		{"light", Light, false},
+		{"high-contrast", HighContrast, false},
+		{"solarized", Palette{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := Lookup(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Lookup(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	defer func() { ascii = false }()
+
+	line := "🌿 3 branch(es) selected • ⚠️ careful ✓ done 💜"
+
+	ascii = false
+	if got := Text(line); got != line {
+		t.Errorf("Text() = %q, want the line unchanged outside ASCII mode", got)
+	}
+
+	ascii = true
+	got := Text(line)
+	for _, r := range got {
+		if r > 127 {
+			t.Errorf("Text() = %q, which still has %q", got, r)
+		}
+	}
+	if lipgloss.Width(got) != lipgloss.Width(line) {
+		t.Errorf("Text() is %d columns wide, want %d", lipgloss.Width(got), lipgloss.Width(line))
+	}
+}
+
+func TestGlyphsArePlain(t *testing.T) {
+	for _, glyph := range glyphs {
+		if strings.ContainsFunc(glyph[1], func(r rune) bool { return r > 127 }) {
+			t.Errorf("%q is replaced by %q, which is not ASCII", glyph[0], glyph[1])
+		}
+		if lipgloss.Width(glyph[1]) > lipgloss.Width(glyph[0]) {
+			t.Errorf("%q is replaced by %q, which is wider", glyph[0], glyph[1])
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	defer func() { ascii = false }()
+	ascii = true
+
+	var out bytes.Buffer
+	n, err := Writer(&out).Write([]byte("🌳 done\n"))
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if n != len("🌳 done\n") {
+		t.Errorf("Write() = %d, want the length of the input", n)
+	}
+	if out.String() != "*  done\n" {
+		t.Errorf("Writer wrote %q", out.String())
+	}
+}
@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+// actionCompleteMsg reports an action on a branch that ran in the background.
+// apply brings the model in line with what the action changed, and runs even
+// when the action failed partway, e.g. after renaming one side of a pair.
+type actionCompleteMsg struct {
+	notice string
+	err    error
+	apply  func(m *model)
+}
+
+// sides returns the branches the row stands for
+func (i branchItem) sides() []*git.Branch {
+	if i.pair != nil {
+		return i.pair.Sides()
+	}
+	return []*git.Branch{i.branch}
+}
+
+// actionComplete applies the result of a background action to the model
+func (m *model) actionComplete(msg actionCompleteMsg) tea.Cmd {
+	if msg.apply != nil {
+		msg.apply(m)
+	}
+
+	m.notice = msg.notice
+	if msg.err != nil {
+		m.notice = "✗ " + msg.err.Error()
+	}
+	return m.refreshList()
+}
+
+// renameBranch prompts for a new name for the row's branch, renaming every
+// side of a pair and keeping the local side tracking the remote one
+func (m *model) renameBranch(item branchItem) tea.Cmd {
+	oldName := item.branch.Name
+	if item.pair != nil {
+		oldName = item.pair.Name
+	}
+
+	return m.openPrompt("Rename to: ", oldName, "new branch name", func(m *model, newName string) tea.Cmd {
+		if newName == oldName {
+			return nil
+		}
+
+		repo, sides := m.repo, item.sides()
+		return func() tea.Msg {
+			var renamed []*git.Branch
+			var err error
+			for _, side := range sides {
+				if side.IsRemote {
+					err = repo.RenameRemoteBranch(side.RemoteName, side.Name, newName)
+				} else {
+					err = repo.RenameLocalBranch(side.Name, newName)
+				}
+				if err != nil {
+					err = fmt.Errorf("failed to rename %s: %w", side.FullName(), err)
+					break
+				}
+				renamed = append(renamed, side)
+			}
+
+			pair := item.pair
+			if err == nil && pair != nil && pair.Tracking {
+				if upstreamErr := repo.SetUpstream(newName, pair.Remote.RemoteName, newName); upstreamErr != nil {
+					err = fmt.Errorf("renamed %s, but failed to track it on %s: %w", newName, pair.Remote.RemoteName, upstreamErr)
+				}
+			}
+
+			return actionCompleteMsg{
+				notice: fmt.Sprintf("✏️  Renamed %s to %s", oldName, newName),
+				err:    err,
+				apply: func(m *model) {
+					for _, side := range renamed {
+						side.Name = newName
+						if m.repo.Protection.Matches(newName) {
+							side.IsProtected = true
+						}
+					}
+					if pair != nil && len(renamed) == len(sides) {
+						pair.Name = newName
+					}
+				},
+			}
+		}
+	})
+}
+
+// checkoutBranch switches the working tree to the row's local branch
+func (m *model) checkoutBranch(item branchItem) tea.Cmd {
+	var local *git.Branch
+	for _, side := range item.sides() {
+		if !side.IsRemote {
+			local = side
+		}
+	}
+	if local == nil {
+		m.notice = fmt.Sprintf("%s has no local branch to check out", item.name())
+		return nil
+	}
+
+	repo := m.repo
+	return func() tea.Msg {
+		if err := repo.CheckoutBranch(local.Name); err != nil {
+			return actionCompleteMsg{err: fmt.Errorf("failed to check out %s: %w", local.Name, err)}
+		}
+
+		return actionCompleteMsg{
+			notice: fmt.Sprintf("🌱 Switched to %s", local.Name),
+			apply: func(m *model) {
+				// The current branch is never offered for pruning
+				for _, item := range m.items {
+					for _, side := range item.sides() {
+						if !side.IsRemote {
+							side.IsCurrent = side == local
+						}
+					}
+				}
+			},
+		}
+	}
+}
+
+// protectBranch adds the row's branch to the protected branches of the
+// repository's configuration file
+func (m *model) protectBranch(item branchItem) tea.Cmd {
+	name := item.branch.Name
+	if item.pair != nil {
+		name = item.pair.Name
+	}
+
+	dir, sides := m.repo.Path, item.sides()
+	return func() tea.Msg {
+		path, err := config.AddProtectedBranch(dir, name)
+		if err != nil {
+			return actionCompleteMsg{err: fmt.Errorf("failed to protect %s: %w", name, err)}
+		}
+
+		return actionCompleteMsg{
+			notice: fmt.Sprintf("🛡️  Protected %s in %s", name, path),
+			apply: func(m *model) {
+				for _, side := range sides {
+					side.IsProtected = true
+				}
+			},
+		}
+	}
+}
+
+// snoozeBranch prompts for how long the row's branch should be left alone,
+// then hides it from this and future runs until then. A snoozed branch is
+// woken up instead.
+func (m *model) snoozeBranch(item branchItem) tea.Cmd {
+	if !item.snoozedUntil().IsZero() {
+		return m.holdBranch(item, "⏰ Woke up", func(b *git.Branch) bool { return b.IsSnoozed() },
+			m.repo.UnsnoozeBranch, func(b *git.Branch) { b.SnoozedUntil = time.Time{} })
+	}
+
+	return m.openPrompt("Snooze for: ", "", "e.g. 1w, 3w, 2M", func(m *model, value string) tea.Cmd {
+		duration, err := config.ParseDuration(value)
+		if err != nil {
+			m.notice = fmt.Sprintf("✗ Invalid snooze duration %q: %v", value, err)
+			return nil
+		}
+
+		until, repo := time.Now().Add(duration).Truncate(time.Second), m.repo
+		return m.holdBranch(item, "💤 Snoozed", func(*git.Branch) bool { return true },
+			func(b *git.Branch) error { return repo.SnoozeBranch(b, until) },
+			func(b *git.Branch) { b.SnoozedUntil = until })
+	})
+}
+
+// togglePin pins the row's branch so it is never offered for pruning, or
+// unpins it when it is pinned
+func (m *model) togglePin(item branchItem) tea.Cmd {
+	if item.pinned() {
+		return m.holdBranch(item, "Unpinned", func(b *git.Branch) bool { return b.IsPinned },
+			m.repo.UnpinBranch, func(b *git.Branch) { b.IsPinned = false })
+	}
+	return m.holdBranch(item, "📌 Pinned", func(b *git.Branch) bool { return !b.IsPinned },
+		m.repo.PinBranch, func(b *git.Branch) { b.IsPinned = true })
+}
+
+// holdBranch changes the hold on the sides of the row that need it in the
+// background, then records the change on them with apply
+func (m *model) holdBranch(item branchItem, done string, needs func(*git.Branch) bool, change func(*git.Branch) error, apply func(*git.Branch)) tea.Cmd {
+	var sides []*git.Branch
+	for _, side := range item.sides() {
+		if needs(side) {
+			sides = append(sides, side)
+		}
+	}
+
+	return func() tea.Msg {
+		var changed []*git.Branch
+		var err error
+		for _, side := range sides {
+			if err = change(side); err != nil {
+				break
+			}
+			changed = append(changed, side)
+		}
+
+		return actionCompleteMsg{
+			notice: fmt.Sprintf("%s %s", done, item.name()),
+			err:    err,
+			apply: func(*model) {
+				for _, side := range changed {
+					apply(side)
+				}
+			},
+		}
+	}
+}
+
+// pinned reports whether any side of the row is pinned
+func (i branchItem) pinned() bool {
+	for _, side := range i.sides() {
+		if side.IsPinned {
+			return true
+		}
+	}
+	return false
+}
+
+// snoozedUntil returns when the last snooze on a side of the row ends, or
+// the zero time when none is snoozed
+func (i branchItem) snoozedUntil() time.Time {
+	var until time.Time
+	for _, side := range i.sides() {
+		if side.IsSnoozed() && side.SnoozedUntil.After(until) {
+			until = side.SnoozedUntil
+		}
+	}
+	return until
+}
+
+// held reports whether any side of the row is pinned or snoozed
+func (i branchItem) held() bool {
+	return i.pinned() || !i.snoozedUntil().IsZero()
+}
+
+// holdBadge renders how the row's branch is held, if it is
+func holdBadge(i branchItem) string {
+	badge := ""
+	switch until := i.snoozedUntil(); {
+	case i.pinned():
+		badge = "📌 pinned"
+	case !until.IsZero():
+		badge = "💤 until " + until.Local().Format("Jan 2")
+	default:
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(accentPurple).Render("[" + badge + "]")
+}
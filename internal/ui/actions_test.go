@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+)
+
+func TestCheckoutBranchNeedsLocalSide(t *testing.T) {
+	remote := &git.Branch{Name: "spike", RemoteName: "origin", IsRemote: true}
+	item := branchItem{branch: remote, pair: &git.BranchPair{Name: "spike", Remote: remote}}
+
+	m := model{}
+	if cmd := m.checkoutBranch(item); cmd != nil {
+		t.Error("checkoutBranch() should not run for a branch that only exists on the remote")
+	}
+	if m.notice == "" {
+		t.Error("checkoutBranch() should explain why nothing was checked out")
+	}
+}
+
+func TestActionCompleteRefreshesList(t *testing.T) {
+	snoozed := testItem("feature/snoozed", "", 30, -1)
+	kept := testItem("feature/kept", "", 30, -1)
+	m := newModel(nil, []branchItem{snoozed, kept}, 7*day, false, prune.Options{})
+	m.refreshList()
+
+	until := time.Now().Add(3 * 7 * day)
+	m.actionComplete(actionCompleteMsg{
+		notice: "snoozed",
+		apply:  func(*model) { snoozed.branch.SnoozedUntil = until },
+	})
+
+	if m.notice != "snoozed" {
+		t.Errorf("notice = %q, want %q", m.notice, "snoozed")
+	}
+	if rows := m.list.Items(); len(rows) != 1 || rowKey(rows[0]) != rowKey(kept) {
+		t.Errorf("rows = %v, want only %s", rows, kept.branch.Name)
+	}
+
+	m.actionComplete(actionCompleteMsg{notice: "renamed", err: errors.New("failed to rename")})
+	if m.notice != "✗ failed to rename" {
+		t.Errorf("notice = %q, want the error", m.notice)
+	}
+}
+
+func TestHeldBranchesAreShownButNotSelectable(t *testing.T) {
+	until := time.Now().Add(3 * 7 * day)
+	local := &git.Branch{Name: "spike", LastCommitAt: time.Now().Add(-30 * day)}
+	remote := &git.Branch{Name: "spike", RemoteName: "origin", IsRemote: true, LastCommitAt: local.LastCommitAt, SnoozedUntil: until}
+	item := branchItem{branch: local, pair: &git.BranchPair{Name: "spike", Local: local, Remote: remote}}
+
+	if !item.held() || !item.snoozedUntil().Equal(until) {
+		t.Errorf("a pair with a snoozed side should be held until %v", until)
+	}
+	if holdBadge(item) == "" {
+		t.Error("a held branch should have a badge")
+	}
+
+	m := model{threshold: 7 * day}
+	if m.visible(item) {
+		t.Error("held branches should be hidden by default")
+	}
+
+	m.showHeld = true
+	if !m.visible(item) || m.selectable(item) {
+		t.Error("held branches should be shown on request, but not offered for pruning")
+	}
+
+	remote.SnoozedUntil = time.Time{}
+	local.IsPinned = true
+	if !item.pinned() || !item.held() {
+		t.Error("a pair with a pinned side should be held")
+	}
+}
+
+func TestFilterTextDoesNotTriggerActions(t *testing.T) {
+	m := newModel(nil, []branchItem{testItem("feature/Sz", "", 30, -1)}, 7*day, false, prune.Options{})
+	m.refreshList()
+
+	var updated tea.Model = m
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("/")},
+		{Type: tea.KeyRunes, Runes: []rune("S")},
+		{Type: tea.KeyRunes, Runes: []rune("z")},
+	} {
+		updated, _ = updated.Update(msg)
+	}
+
+	m = updated.(model)
+	if m.prompt != nil || m.notice != "" {
+		t.Errorf("typing into the filter fired an action: prompt = %v, notice = %q", m.prompt, m.notice)
+	}
+	if got := m.list.FilterValue(); got != "Sz" {
+		t.Errorf("filter = %q, want %q", got, "Sz")
+	}
+}
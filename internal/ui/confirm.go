@@ -0,0 +1,237 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+)
+
+// confirmListLimit is how many branch names the confirmation dialog lists
+const confirmListLimit = 12
+
+// confirmBoxStyle frames the confirmation dialog; applyTheme sets it
+var confirmBoxStyle lipgloss.Style
+
+// confirmation is a pending deletion waiting for the user's go-ahead
+type confirmation struct {
+	branches []*git.Branch
+	opts     prune.Options
+
+	// In the unified view the user picks which sides of the pairs go
+	pairs []*git.BranchPair
+	scope pairScope
+}
+
+// targets returns the branches the confirmation would delete
+func (c *confirmation) targets() []*git.Branch {
+	if c.pairs == nil {
+		return c.branches
+	}
+
+	var branches []*git.Branch
+	for _, pair := range c.pairs {
+		branches = append(branches, c.scope.sides(pair)...)
+	}
+	return branches
+}
+
+// confirm asks before deleting the branches with opts
+func (m *model) confirm(branches []*git.Branch, opts prune.Options) {
+	if len(branches) == 0 {
+		return
+	}
+	m.confirming = &confirmation{branches: branches, opts: opts}
+}
+
+// confirmSelection asks before deleting the selected branches, or the
+// selected pairs in the unified view
+func (m *model) confirmSelection(opts prune.Options) {
+	if !m.unified {
+		m.confirm(m.getSelectedBranches(), opts)
+		return
+	}
+
+	var pairs []*git.BranchPair
+	for _, item := range m.items {
+		if item.selected && item.pair != nil {
+			pairs = append(pairs, item.pair)
+		}
+	}
+	if len(pairs) > 0 {
+		m.confirming = &confirmation{pairs: pairs, opts: opts, scope: scopeBoth}
+	}
+}
+
+// updateConfirmation handles the keys of the confirmation dialog
+func (m *model) updateConfirmation(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("y", "enter"))):
+		branches := m.confirming.targets()
+		if len(branches) == 0 {
+			return nil
+		}
+		opts := m.confirming.opts
+		m.confirming = nil
+		return m.startDeletion(branches, opts)
+
+	case m.confirming.pairs != nil && key.Matches(msg, key.NewBinding(key.WithKeys("b"))):
+		m.confirming.scope = scopeBoth
+
+	case m.confirming.pairs != nil && key.Matches(msg, key.NewBinding(key.WithKeys("l"))):
+		m.confirming.scope = scopeLocal
+
+	case m.confirming.pairs != nil && key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+		m.confirming.scope = scopeRemote
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("n", "esc", "q"))):
+		m.confirming = nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
+		m.confirming = nil
+		if m.reviewing {
+			return m.quit()
+		}
+		m.quitting = true
+		return tea.Quit
+	}
+
+	return nil
+}
+
+// renderConfirmation renders the dialog listing the branches about to go
+func (m model) renderConfirmation() string {
+	c := m.confirming
+
+	action := "Delete"
+	switch {
+	case c.opts.Archive:
+		action = "Archive and delete"
+	case c.opts.Force:
+		action = "Force delete"
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Foreground(warningRed).Bold(true).
+			Render(fmt.Sprintf("🪓 %s %d branch(es)?", action, len(c.targets()))),
+		"",
+	}
+
+	if c.pairs != nil {
+		lines = append(lines, c.renderPairs()...)
+	} else {
+		for i, branch := range c.branches {
+			if i == confirmListLimit {
+				lines = append(lines, ageStyle.Render(fmt.Sprintf("  ... and %d more", len(c.branches)-confirmListLimit)))
+				break
+			}
+			lines = append(lines, "  • "+branchNameStyle.Render(branch.FullName()))
+		}
+	}
+
+	if c.opts.Force && !c.opts.Archive {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(warningRed).Italic(true).
+			Render("Commits that exist only on these branches will be lost."))
+	}
+
+	if c.pairs != nil {
+		lines = append(lines, "", scopeHint(c.scope))
+	}
+	lines = append(lines, "", ageStyle.Render("y/enter = confirm • n/esc = go back"))
+
+	return confirmBoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// showResults switches to the results of the last deletion, where branches
+// that were not fully merged can be picked for a forced retry
+func (m *model) showResults() tea.Cmd {
+	m.reviewing = true
+	m.notice = ""
+	m.setSelected(func(branchItem) bool { return true }, false)
+	return m.refreshList()
+}
+
+// updateResults handles the keys of the results screen
+func (m *model) updateResults(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("q", "esc", "ctrl+c"))):
+		return m.quit(), true
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys(" ", "x"))):
+		item, ok := m.list.SelectedItem().(branchItem)
+		if !ok || !item.retryable() {
+			return nil, true
+		}
+		name := item.branch.FullName()
+		m.setSelected(func(b branchItem) bool { return b.branch.FullName() == name }, !item.selected)
+		return m.refreshList(), true
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
+		m.setSelected(branchItem.retryable, true)
+		return m.refreshList(), true
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("n"))):
+		m.setSelected(func(branchItem) bool { return true }, false)
+		return m.refreshList(), true
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("f", "enter", "d"))):
+		// Only the side that failed is retried
+		var branches []*git.Branch
+		for _, item := range m.items {
+			if item.selected && item.retryable() {
+				branches = append(branches, item.failedSide)
+			}
+		}
+
+		opts := m.pruneOpts
+		opts.Force = true
+		m.confirm(branches, opts)
+		return nil, true
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("o", "t", "c", "C", "A", "s", "tab", "R", "S", "P", "p", "z", "H"))):
+		// Actions of the selection screen make no sense for the results
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// renderResultsStatus renders the status line of the results screen
+func (m model) renderResultsStatus() string {
+	failed, retryable, selected := 0, 0, 0
+	for _, item := range m.items {
+		if item.status == statusFailed {
+			failed++
+		}
+		if item.retryable() {
+			retryable++
+			if item.selected {
+				selected++
+			}
+		}
+	}
+
+	status := lipgloss.NewStyle().Foreground(warningRed).Bold(true).
+		Render(fmt.Sprintf("✗ %d branch(es) could not be deleted", failed))
+
+	hint := "q = done"
+	switch {
+	case selected > 0:
+		hint = fmt.Sprintf("f/enter = force delete %d selected • space/x = toggle • n = none • q = done", selected)
+	case retryable > 0:
+		hint = fmt.Sprintf("%d not fully merged • space/x = select for force delete • a = all • q = done", retryable)
+	}
+
+	return lipgloss.NewStyle().MarginLeft(2).Render(status + "\n" + ageStyle.Render(hint))
+}
+
+// retryable reports whether the branch failed only because it is not fully
+// merged, which deleting it with force overcomes
+func (i branchItem) retryable() bool {
+	return i.status == statusFailed && i.failedSide != nil && !i.failedSide.IsRemote &&
+		i.err != nil && strings.Contains(i.err.Error(), "not fully merged")
+}
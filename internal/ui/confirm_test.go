@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+func TestBranchItemRetryable(t *testing.T) {
+	unmerged := errors.New("error: the branch 'feature' is not fully merged")
+	local := &git.Branch{Name: "feature"}
+	remote := &git.Branch{Name: "feature", IsRemote: true, RemoteName: "origin"}
+
+	tests := []struct {
+		name string
+		item branchItem
+		want bool
+	}{
+		{"unmerged local", branchItem{branch: local, status: statusFailed, err: unmerged, failedSide: local}, true},
+		{"other failure", branchItem{branch: local, status: statusFailed, err: errors.New("permission denied"), failedSide: local}, false},
+		{"remote", branchItem{branch: remote, status: statusFailed, err: unmerged, failedSide: remote}, false},
+		{"deleted", branchItem{branch: local, status: statusDeleted}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.item.retryable(); got != tt.want {
+			t.Errorf("%s: retryable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfirmIgnoresEmptySelection(t *testing.T) {
+	m := model{}
+	m.confirm(nil, m.pruneOpts)
+	if m.confirming != nil {
+		t.Error("confirm() should not open a dialog without selected branches")
+	}
+}
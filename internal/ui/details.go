@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+// detailCommitLimit is how many unique commits the detail pane lists
+const detailCommitLimit = 8
+
+// detailsBoxStyle frames the detail pane; applyTheme sets it
+var detailsBoxStyle lipgloss.Style
+
+// detailsEntry caches what is known about one branch's details
+type detailsEntry struct {
+	details *git.BranchDetails
+	err     error
+	loading bool
+}
+
+type detailsLoadedMsg struct {
+	name    string
+	details *git.BranchDetails
+	err     error
+}
+
+// loadHighlightedDetails starts loading the highlighted branch's details in
+// the background unless the pane is hidden or they are already known
+func (m *model) loadHighlightedDetails() tea.Cmd {
+	if !m.showDetails || m.pruneOpts.Base == "" {
+		return nil
+	}
+
+	item, ok := m.list.SelectedItem().(branchItem)
+	if !ok {
+		return nil
+	}
+
+	name := item.branch.FullName()
+	if _, known := m.details[name]; known {
+		return nil
+	}
+	m.details[name] = &detailsEntry{loading: true}
+
+	repo, branch, base := m.repo, item.branch, m.pruneOpts.Base
+	return func() tea.Msg {
+		details, err := repo.BranchDetails(branch, base, detailCommitLimit)
+		return detailsLoadedMsg{name: name, details: details, err: err}
+	}
+}
+
+// renderDetails renders the detail pane for the highlighted branch
+func (m model) renderDetails() string {
+	item, ok := m.list.SelectedItem().(branchItem)
+	if !ok {
+		return ""
+	}
+
+	muted := lipgloss.NewStyle().Foreground(mutedGray).Italic(true)
+	name := item.branch.FullName()
+
+	var body string
+	entry := m.details[name]
+	switch {
+	case m.pruneOpts.Base == "":
+		body = muted.Render("No base branch to compare against - set base_branch in .bonsai.yaml")
+	case entry == nil || entry.loading:
+		body = muted.Render("Loading details...")
+	case entry.err != nil:
+		body = lipgloss.NewStyle().Foreground(warningRed).Render(fmt.Sprintf("✗ %v", entry.err))
+	default:
+		body = formatDetails(item.branch, entry.details)
+	}
+
+	title := fmt.Sprintf("📜 %s", branchNameStyle.Render(name))
+	if m.pruneOpts.Base != "" {
+		title += ageStyle.Render(" compared to " + m.pruneOpts.Base)
+	}
+
+	return detailsBoxStyle.Render(title + "\n" + body)
+}
+
+// formatDetails lays out the merge state, tracking status, commits and
+// diffstat of a branch
+func formatDetails(branch *git.Branch, details *git.BranchDetails) string {
+	var lines []string
+
+	mergeState := lipgloss.NewStyle().Foreground(warningRed).Render("not merged")
+	if details.Merged {
+		mergeState = lipgloss.NewStyle().Foreground(successGreen).Render("merged into " + details.Base)
+	}
+	lines = append(lines, fmt.Sprintf("↑ %d ahead • ↓ %d behind • %s", details.Ahead, details.Behind, mergeState))
+
+	switch {
+	case branch.IsRemote:
+		lines = append(lines, "🔗 "+ageStyle.Render("remote-tracking branch"))
+	case details.Upstream == "":
+		lines = append(lines, "🔗 "+ageStyle.Render("no upstream"))
+	default:
+		upstream := "🔗 " + details.Upstream
+		if details.UpstreamTrack == "gone" {
+			upstream += " " + lipgloss.NewStyle().Foreground(warningRed).Render("(gone)")
+		} else if details.UpstreamTrack != "" {
+			upstream += " " + ageStyle.Render("("+details.UpstreamTrack+")")
+		} else {
+			upstream += " " + ageStyle.Render("(up to date)")
+		}
+		lines = append(lines, upstream)
+	}
+
+	if len(details.Commits) > 0 {
+		lines = append(lines, "")
+		for _, commit := range details.Commits {
+			subject := ansi.Truncate(commit.Subject, 60, "...")
+			lines = append(lines, fmt.Sprintf("%s %s %s",
+				authorStyle.Render(commit.Hash),
+				subject,
+				ageStyle.Render("- "+commit.Author+", "+FormatAge(time.Since(commit.CommittedAt)))))
+		}
+		if more := details.Ahead - len(details.Commits); more > 0 {
+			lines = append(lines, ageStyle.Render(fmt.Sprintf("... and %d more", more)))
+		}
+	}
+
+	if details.DiffStat != "" {
+		lines = append(lines, "", commitMsgStyle.Render(details.DiffStat))
+	}
+
+	return strings.Join(lines, "\n")
+}
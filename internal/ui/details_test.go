@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+func TestFormatDetails_TruncatesSubjectsByRune(t *testing.T) {
+	subject := strings.Repeat("é", 70)
+	details := &git.BranchDetails{
+		Base:    "main",
+		Ahead:   1,
+		Commits: []git.Commit{{Hash: "abc1234", Subject: subject, Author: "Ana"}},
+	}
+
+	out := formatDetails(&git.Branch{Name: "feature"}, details)
+	if !utf8.ValidString(out) {
+		t.Fatalf("formatDetails() split a multi-byte character: %q", out)
+	}
+	if want := strings.Repeat("é", 57) + "..."; !strings.Contains(out, want) {
+		t.Errorf("formatDetails() = %q, want subject truncated to %q", out, want)
+	}
+}
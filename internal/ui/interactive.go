@@ -8,19 +8,23 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+	"github.com/kriscoleman/bonsai/internal/theme"
 )
 
 var (
-	// Bonsai color palette
-	leafGreen    = lipgloss.Color("#7FB069") // Fresh leaf green
-	accentPurple = lipgloss.Color("#C792EA") // Charm purple accent
-	softCyan     = lipgloss.Color("#89DDFF") // Soft cyan highlight
-	mutedGray    = lipgloss.Color("#8F8F8F") // Elegant gray
-	warningRed   = lipgloss.Color("#FF6B6B") // Gentle warning red
-	successGreen = lipgloss.Color("#51CF66") // Success green
+	// Bonsai color palette, set from the theme by applyTheme
+	leafGreen    lipgloss.Color // Fresh leaf green
+	accentPurple lipgloss.Color // Charm purple accent
+	softCyan     lipgloss.Color // Soft cyan highlight
+	mutedGray    lipgloss.Color // Elegant gray
+	warningRed   lipgloss.Color // Gentle warning red
+	successGreen lipgloss.Color // Success green
+	cautionGold  lipgloss.Color // Hint yellow
 
 	// Bonsai tree ASCII art for header
 	bonsaiHeader = `
@@ -31,68 +35,123 @@ var (
    ╰─────────────────────────────────────────────╯
 `
 
+	titleStyle        lipgloss.Style
+	headerStyle       lipgloss.Style
+	itemStyle         lipgloss.Style
+	selectedItemStyle lipgloss.Style
+	branchNameStyle   lipgloss.Style
+	ageStyle          lipgloss.Style
+	authorStyle       lipgloss.Style
+	commitMsgStyle    lipgloss.Style
+	paginationStyle   lipgloss.Style
+	helpStyle         lipgloss.Style
+	successStyle      lipgloss.Style
+)
+
+func init() {
+	applyTheme(theme.Colors())
+}
+
+// applyTheme sets the colors and styles from a palette
+func applyTheme(p theme.Palette) {
+	leafGreen = p.Leaf
+	accentPurple = p.Accent
+	softCyan = p.Highlight
+	mutedGray = p.Muted
+	warningRed = p.Warning
+	successGreen = p.Success
+	cautionGold = p.Caution
+
 	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(leafGreen).
-			MarginLeft(2).
-			MarginTop(1)
+		Bold(true).
+		Foreground(leafGreen).
+		MarginLeft(2).
+		MarginTop(1)
 
 	headerStyle = lipgloss.NewStyle().
-			Foreground(softCyan).
-			Align(lipgloss.Center).
-			Bold(true)
+		Foreground(softCyan).
+		Align(lipgloss.Center).
+		Bold(true)
 
 	itemStyle = lipgloss.NewStyle().
-			PaddingLeft(4).
-			Foreground(lipgloss.Color("252"))
+		PaddingLeft(4).
+		Foreground(p.Text)
 
 	selectedItemStyle = lipgloss.NewStyle().
-				PaddingLeft(2).
-				Foreground(leafGreen).
-				Bold(true)
+		PaddingLeft(2).
+		Foreground(leafGreen).
+		Bold(true)
 
 	branchNameStyle = lipgloss.NewStyle().
-			Foreground(softCyan).
-			Bold(true)
+		Foreground(softCyan).
+		Bold(true)
 
 	ageStyle = lipgloss.NewStyle().
-			Foreground(mutedGray).
-			Italic(true)
+		Foreground(mutedGray).
+		Italic(true)
 
 	authorStyle = lipgloss.NewStyle().
-			Foreground(accentPurple)
+		Foreground(accentPurple)
 
 	commitMsgStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("246"))
+		Foreground(p.Subtle)
 
 	paginationStyle = list.DefaultStyles().PaginationStyle.
-			PaddingLeft(4).
-			Foreground(mutedGray)
+		PaddingLeft(4).
+		Foreground(mutedGray)
 
 	helpStyle = list.DefaultStyles().HelpStyle.
-			PaddingLeft(4).
-			PaddingBottom(1).
-			Foreground(mutedGray)
-
-	deletingStyle = lipgloss.NewStyle().
-			Margin(1, 0, 2, 4).
-			Foreground(warningRed).
-			Bold(true)
+		PaddingLeft(4).
+		PaddingBottom(1).
+		Foreground(mutedGray)
 
 	successStyle = lipgloss.NewStyle().
-			Foreground(successGreen).
-			Bold(true)
-)
+		Foreground(successGreen).
+		Bold(true)
+
+	confirmBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(warningRed).
+		Padding(0, 2).
+		MarginLeft(2)
+
+	detailsBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(mutedGray).
+		Padding(0, 1).
+		MarginLeft(2)
+
+	groupStyle = lipgloss.NewStyle().
+		Foreground(leafGreen).
+		Bold(true)
+}
 
 type branchItem struct {
 	branch   *git.Branch
 	selected bool
+	ahead    int  // Commits ahead of the base branch, -1 until counted
+	grouped  bool // Shown inside a group node
+	status   deleteStatus
+	err      error // Why deleting the branch failed
+
+	// In the unified view, branch is the primary side of pair
+	pair       *git.BranchPair
+	failedSide *git.Branch // The side whose deletion failed
 }
 
 func (i branchItem) FilterValue() string {
 	return i.branch.Name
 }
 
+// name is how the row refers to its branch: the logical name for a pair,
+// the full name otherwise
+func (i branchItem) name() string {
+	if i.pair != nil {
+		return i.pair.Name
+	}
+	return i.branch.FullName()
+}
+
 func (i branchItem) Title() string {
 	checkbox := "○"
 	checkboxColor := mutedGray
@@ -102,16 +161,83 @@ func (i branchItem) Title() string {
 	}
 
 	checkboxStyle := lipgloss.NewStyle().Foreground(checkboxColor).Bold(true)
-	age := formatAge(i.branch.Age())
+	age := FormatAge(i.branch.Age())
 
-	return fmt.Sprintf("%s %s %s",
+	title := fmt.Sprintf("%s %s %s",
 		checkboxStyle.Render(checkbox),
-		branchNameStyle.Render(i.branch.FullName()),
+		branchNameStyle.Render(i.name()),
 		ageStyle.Render("("+age+")"),
 	)
+
+	if badge := unpushedBadge(i); badge != "" {
+		title += " " + badge
+	}
+
+	if i.pair != nil {
+		title += " " + pairBadge(i.pair)
+	}
+
+	if i.ahead >= 0 {
+		title += " " + authorStyle.Render(fmt.Sprintf("↑%d", i.ahead))
+	}
+
+	if pr := i.branch.PullRequest; pr != nil {
+		title += " " + pullRequestBadge(pr)
+	}
+
+	if badge := holdBadge(i); badge != "" {
+		title += " " + badge
+	}
+
+	if badge := statusBadge(i.status); badge != "" {
+		title += " " + badge
+	}
+
+	if i.grouped {
+		title = "  " + title
+	}
+
+	return title
+}
+
+// pullRequestBadge renders a compact, color-coded pull request label
+func pullRequestBadge(pr *git.PullRequest) string {
+	color := mutedGray
+	switch pr.State {
+	case git.PullRequestOpen:
+		color = successGreen
+	case git.PullRequestMerged:
+		color = accentPurple
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(color).
+		Bold(true).
+		Render("[" + pr.String() + "]")
+}
+
+// unpushedBadge warns that deleting the row's branch would lose commits that
+// exist nowhere else
+func unpushedBadge(i branchItem) string {
+	branch := i.branch
+	if i.pair != nil && i.pair.Local != nil {
+		branch = i.pair.Local
+	}
+	if !branch.IsAtRisk() {
+		return ""
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(warningRed).
+		Bold(true).
+		Render(fmt.Sprintf("[⚠️ %d unpushed]", branch.Unpushed))
 }
 
 func (i branchItem) Description() string {
+	if i.status == statusFailed && i.err != nil {
+		return "  " + lipgloss.NewStyle().Foreground(warningRed).Render(i.err.Error())
+	}
+
 	commitMsg := i.branch.LastCommitMsg
 	if len(commitMsg) > 80 {
 		commitMsg = commitMsg[:77] + "..."
@@ -135,18 +261,46 @@ type model struct {
 	branches     []*git.Branch
 	items        []branchItem
 	isRemote     bool
+	unified      bool   // Rows are local/remote pairs
+	remoteName   string // Remote of the unified view
 	verbose      bool
-	force        bool
+	pruneOpts    prune.Options
 	quitting     bool
 	deleting     bool
+	deletion     *deletion
+	progress     progress.Model
+	confirming   *confirmation // Deletion waiting for the user's go-ahead
+	reviewing    bool          // Showing the results of a deletion that had failures
+	archived     bool          // Some branches were archived before deletion
 	message      string
+	notice       string // Result of the last action on the highlighted branch
 	errorDetails []string
+
+	// Detail pane for the highlighted branch, loaded in the background
+	showDetails bool
+	details     map[string]*detailsEntry
+
+	// Ordering and prefix grouping of the branch list
+	sort         sortMode
+	grouped      bool
+	collapsed    map[string]bool
+	aheadCounted bool
+
+	// Only branches older than threshold are offered; it can be changed live
+	threshold time.Duration
+
+	prompt   *prompt // Text prompt for a threshold or an action, when open
+	showHeld bool    // Pinned and snoozed branches are listed too
 }
 
-type deleteCompleteMsg struct {
-	success      int
-	failed       int
-	errorDetails []string
+type aheadCountsMsg struct {
+	counts map[string]int
+}
+
+type salvageCompleteMsg struct {
+	branch  *git.Branch
+	salvage *prune.Salvage
+	err     error
 }
 
 func (m model) Init() tea.Cmd {
@@ -157,67 +311,238 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.list.SetWidth(msg.Width)
+		m.progress.Width = max(min(msg.Width-4, 60), 10)
 		return m, nil
 
 	case tea.KeyMsg:
 		if m.deleting {
+			// Let the branch being deleted finish, then skip the rest
+			if key.Matches(msg, key.NewBinding(key.WithKeys("esc"))) {
+				m.deletion.cancelled = true
+			}
 			return m, nil
 		}
 
+		if m.confirming != nil {
+			return m, m.updateConfirmation(msg)
+		}
+
+		if m.prompt != nil {
+			return m, m.updatePrompt(msg)
+		}
+
+		if m.list.FilterState() == list.Filtering {
+			// Keys typed into the filter are text, not actions
+			var cmd tea.Cmd
+			m.list, cmd = m.list.Update(msg)
+			return m, cmd
+		}
+
+		if m.reviewing {
+			if cmd, handled := m.updateResults(msg); handled {
+				return m, cmd
+			}
+			break
+		}
+
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("q", "esc", "ctrl+c"))):
 			m.quitting = true
 			return m, tea.Quit
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys(" ", "x"))):
-			// Toggle selection
-			if _, ok := m.list.SelectedItem().(branchItem); ok {
-				idx := m.list.Index()
-				m.items[idx].selected = !m.items[idx].selected
-				m.list.SetItem(idx, m.items[idx])
+			// Toggle selection of a branch, or of every branch in a group
+			switch item := m.list.SelectedItem().(type) {
+			case branchItem:
+				name := item.branch.FullName()
+				m.setSelected(func(b branchItem) bool { return b.branch.FullName() == name }, !item.selected)
+			case groupItem:
+				m.setSelected(func(b branchItem) bool { return groupPrefix(b.branch.Name) == item.prefix }, item.selected < item.total)
+			default:
+				return m, nil
 			}
-			return m, nil
+			return m, m.refreshList()
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
 			// Select all
-			for i := range m.items {
-				m.items[i].selected = true
-				m.list.SetItem(i, m.items[i])
-			}
-			return m, nil
+			m.setSelected(func(branchItem) bool { return true }, true)
+			return m, m.refreshList()
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("n"))):
 			// Select none
-			for i := range m.items {
-				m.items[i].selected = false
-				m.list.SetItem(i, m.items[i])
+			m.setSelected(func(branchItem) bool { return true }, false)
+			return m, m.refreshList()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("o"))):
+			// Cycle the sort order; counting ahead commits needs a base branch
+			m.sort = m.sort.next()
+			if m.sort == sortByAhead && m.pruneOpts.Base == "" {
+				m.sort = m.sort.next()
 			}
-			return m, nil
+			m.notice = fmt.Sprintf("Sorted by %s", m.sort)
+			if m.sort == sortByAhead && !m.aheadCounted {
+				m.notice = fmt.Sprintf("Counting commits ahead of %s...", m.pruneOpts.Base)
+				return m, tea.Batch(m.refreshList(), m.countAhead())
+			}
+			return m, m.refreshList()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("t"))):
+			// Toggle folding branches into a tree by name prefix
+			m.grouped = !m.grouped
+			return m, m.refreshList()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("c"))):
+			// Fold or unfold the highlighted group, or the group of the highlighted branch
+			var prefix string
+			switch item := m.list.SelectedItem().(type) {
+			case groupItem:
+				prefix = item.prefix
+			case branchItem:
+				if !item.grouped {
+					return m, nil
+				}
+				prefix = groupPrefix(item.branch.Name)
+			default:
+				return m, nil
+			}
+			m.collapsed[prefix] = !m.collapsed[prefix]
+			return m, m.refreshList()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("C"))):
+			// Fold every group, or unfold them all if they already are
+			folded := m.grouped
+			for _, row := range m.list.Items() {
+				if group, ok := row.(groupItem); ok && !group.collapsed {
+					folded = false
+				}
+			}
+			m.collapsed = map[string]bool{}
+			if !folded {
+				for _, item := range m.items {
+					if prefix := groupPrefix(item.branch.Name); prefix != "" {
+						m.collapsed[prefix] = true
+					}
+				}
+			}
+			return m, m.refreshList()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("+"))):
+			// Raise the age threshold, offering fewer branches
+			return m, m.setThreshold(longerThreshold(m.threshold))
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("-"))):
+			// Lower the age threshold, offering more branches
+			return m, m.setThreshold(shorterThreshold(m.threshold))
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("="))):
+			// Type an age threshold such as 10d or 3w
+			return m, m.editThreshold()
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter", "d"))):
-			// Delete selected branches
-			selected := m.getSelectedBranches()
-			if len(selected) == 0 {
+			// Delete selected branches once confirmed
+			m.confirmSelection(m.pruneOpts)
+			return m, nil
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("A"))):
+			// Archive selected branches as tags, then delete them once confirmed
+			opts := m.pruneOpts
+			opts.Archive = true
+
+			m.confirmSelection(opts)
+			return m, nil
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("s"))):
+			// Salvage the highlighted branch's unique commits as patches
+			item, ok := m.list.SelectedItem().(branchItem)
+			if !ok {
+				return m, nil
+			}
+			if m.pruneOpts.SalvageDir == "" {
+				m.notice = "Start bonsai with --salvage-dir <dir> to salvage branches"
 				return m, nil
 			}
+			m.notice = fmt.Sprintf("Salvaging %s...", item.branch.FullName())
+			return m, m.salvageBranch(item.branch)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("H"))):
+			// Show or hide the pinned and snoozed branches
+			m.showHeld = !m.showHeld
+			m.notice = "Hiding pinned and snoozed branches"
+			if m.showHeld {
+				m.notice = "Showing pinned and snoozed branches"
+			}
+			return m, m.refreshList()
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("R", "S", "P", "p", "z"))):
+			// Rename, switch to, protect, pin or snooze the highlighted branch
+			item, ok := m.list.SelectedItem().(branchItem)
+			if !ok {
+				return m, nil
+			}
+			switch msg.String() {
+			case "R":
+				return m, m.renameBranch(item)
+			case "S":
+				return m, m.checkoutBranch(item)
+			case "P":
+				return m, m.protectBranch(item)
+			case "p":
+				return m, m.togglePin(item)
+			}
+			return m, m.snoozeBranch(item)
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
+			// Toggle the detail pane
+			m.showDetails = !m.showDetails
+			return m, m.loadHighlightedDetails()
+		}
+
+	case aheadCountsMsg:
+		for i := range m.items {
+			m.items[i].ahead = msg.counts[m.items[i].branch.FullName()]
+		}
+		m.aheadCounted = true
+		if m.sort == sortByAhead {
+			m.notice = fmt.Sprintf("Sorted by %s", m.sort)
+		}
+		return m, m.refreshList()
+
+	case actionCompleteMsg:
+		return m, m.actionComplete(msg)
 
-			m.deleting = true
-			return m, m.deleteBranches(selected)
+	case detailsLoadedMsg:
+		m.details[msg.name] = &detailsEntry{details: msg.details, err: msg.err}
+		return m, nil
+
+	case salvageCompleteMsg:
+		switch {
+		case msg.err != nil:
+			m.notice = fmt.Sprintf("✗ Failed to salvage %s: %v", msg.branch.FullName(), msg.err)
+		case msg.salvage == nil:
+			m.notice = fmt.Sprintf("%s has no commits beyond its base branch - nothing to salvage", msg.branch.FullName())
+		default:
+			m.notice = fmt.Sprintf("💾 Salvaged %d patch(es) from %s to %s",
+				len(msg.salvage.Patches), msg.branch.FullName(), msg.salvage.Dir)
 		}
+		return m, nil
 
-	case deleteCompleteMsg:
-		m.message = fmt.Sprintf("Deleted %d branch(es), %d failed", msg.success, msg.failed)
-		m.errorDetails = msg.errorDetails
-		m.quitting = true
-		return m, tea.Quit
+	case branchDeletedMsg:
+		return m, m.branchDeleted(msg)
 	}
 
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+
+	// Navigation may have highlighted a branch whose details aren't loaded yet
+	return m, tea.Batch(cmd, m.loadHighlightedDetails())
 }
 
+// View renders the UI, in plain glyphs when the theme asks for them
 func (m model) View() string {
+	return theme.Text(m.view())
+}
+
+func (m model) view() string {
 	if m.quitting {
 		if m.message != "" {
 			// Create an elegant exit message with proper box alignment
@@ -253,9 +578,9 @@ func (m model) View() string {
 				result += "\n"
 
 				// Suggest using --force if branches aren't merged
-				if !m.force && unmergedCount > 0 {
+				if !m.pruneOpts.Force && unmergedCount > 0 {
 					hintStyle := lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#FFD43B")).
+						Foreground(cautionGold).
 						Italic(true)
 
 					hintBox := lipgloss.NewStyle().
@@ -265,7 +590,10 @@ func (m model) View() string {
 						MarginBottom(1)
 
 					branchType := "local"
-					if m.isRemote {
+					switch {
+					case m.unified:
+						branchType = "all"
+					case m.isRemote:
 						branchType = "remote"
 					}
 
@@ -289,16 +617,18 @@ func (m model) View() string {
 		return "\n  " + cancelMsg + "\n"
 	}
 
-	if m.deleting {
-		spinner := "🌀"
-		deleteMsg := fmt.Sprintf("%s Carefully pruning selected branches...", spinner)
-
-		return deletingStyle.Render("\n" + deleteMsg + "\n")
-	}
-
 	// Show the header with bonsai art
 	header := headerStyle.Render(bonsaiHeader)
 
+	switch {
+	case m.confirming != nil:
+		return fmt.Sprintf("\n%s\n\n%s\n", header, m.renderConfirmation())
+	case m.deleting:
+		return fmt.Sprintf("\n%s\n\n%s\n\n%s\n", header, m.list.View(), m.renderProgress())
+	case m.reviewing:
+		return fmt.Sprintf("\n%s\n\n%s\n\n%s\n", header, m.list.View(), m.renderResultsStatus())
+	}
+
 	// Get selected count
 	selectedCount := 0
 	for _, item := range m.items {
@@ -319,7 +649,22 @@ func (m model) View() string {
 			Foreground(mutedGray).
 			Italic(true).
 			MarginLeft(2).
-			Render("Select branches to prune with space/x • a = all • n = none • enter/d = delete")
+			Render("Select branches to prune with space/x • a = all • n = none • enter/d = delete • A = archive • tab = details • o = sort • t = tree • +/- = age")
+	}
+
+	if m.prompt != nil {
+		statusBar += "\n" + m.renderPrompt()
+	}
+
+	if m.notice != "" {
+		statusBar += "\n" + lipgloss.NewStyle().
+			Foreground(softCyan).
+			MarginLeft(2).
+			Render(m.notice)
+	}
+
+	if m.showDetails {
+		statusBar = m.renderDetails() + "\n\n" + statusBar
 	}
 
 	return fmt.Sprintf("\n%s\n\n%s\n\n%s\n", header, m.list.View(), statusBar)
@@ -335,37 +680,116 @@ func (m model) getSelectedBranches() []*git.Branch {
 	return selected
 }
 
-func (m *model) deleteBranches(branches []*git.Branch) tea.Cmd {
-	return func() tea.Msg {
-		successCount := 0
-		errorCount := 0
-		var errorDetails []string
+// setSelected marks the branches matching match as selected or not. Only
+// selectable branches can be selected.
+func (m *model) setSelected(match func(branchItem) bool, selected bool) {
+	for i := range m.items {
+		if match(m.items[i]) && (!selected || m.selectable(m.items[i])) {
+			m.items[i].selected = selected
+		}
+	}
+}
 
-		for _, branch := range branches {
-			var err error
-			if m.isRemote {
-				err = m.repo.DeleteRemoteBranch(branch.RemoteName, branch.Name)
-			} else {
-				err = m.repo.DeleteLocalBranch(branch.Name, m.force)
+// selectable reports whether a branch can be picked: the results only
+// concern the branches that deletion was attempted on, otherwise only
+// candidates at the current threshold are offered
+func (m model) selectable(item branchItem) bool {
+	if m.reviewing {
+		return item.status != statusNone
+	}
+	return item.candidate(m.threshold)
+}
+
+// visible reports whether a branch is on display: the selectable ones, and
+// the pinned and snoozed ones when they are asked for
+func (m model) visible(item branchItem) bool {
+	return m.selectable(item) || (m.showHeld && !m.reviewing && item.held())
+}
+
+// refreshList re-sorts and regroups the branches and rebuilds the list rows,
+// keeping the highlight on the same branch or on the group it was folded into
+func (m *model) refreshList() tea.Cmd {
+	highlighted := m.list.SelectedItem()
+
+	sortItems(m.items, m.sort)
+
+	// Selections survive a threshold change only while still in range
+	var items []branchItem
+	for i := range m.items {
+		if !m.selectable(m.items[i]) {
+			m.items[i].selected = false
+		}
+		if m.visible(m.items[i]) {
+			items = append(items, m.items[i])
+		}
+	}
+
+	cmd := m.list.SetItems(buildRows(items, m.grouped, m.collapsed))
+	m.list.Title = m.title()
+
+	keys := []string{rowKey(highlighted)}
+	if item, ok := highlighted.(branchItem); ok {
+		keys = append(keys, rowKey(groupItem{prefix: groupPrefix(item.branch.Name)}))
+	}
+	m.highlight(keys...)
+
+	return cmd
+}
+
+// highlight moves the cursor to the first of the rows identified by keys
+// that is visible
+func (m *model) highlight(keys ...string) {
+	for _, want := range keys {
+		for i, row := range m.list.VisibleItems() {
+			if rowKey(row) == want {
+				m.list.Select(i)
+				return
 			}
+		}
+	}
+}
 
+// title is the list heading, naming the branch type and the sort order
+func (m model) title() string {
+	branchType := "local"
+	switch {
+	case m.unified:
+		branchType = "local + " + m.remoteName
+	case m.isRemote:
+		branchType = "remote"
+	}
+	return fmt.Sprintf("🌿 Branches ready for pruning (%s) • older than %s • by %s", branchType, formatThreshold(m.threshold), m.sort)
+}
+
+// countAhead counts in the background how many commits each branch has
+// beyond the base branch. Branches that cannot be compared count as -1.
+func (m *model) countAhead() tea.Cmd {
+	repo, branches, base := m.repo, m.branches, m.pruneOpts.Base
+
+	return func() tea.Msg {
+		counts := make(map[string]int, len(branches))
+		for _, branch := range branches {
+			ahead, err := repo.AheadCount(branch, base)
 			if err != nil {
-				errorDetails = append(errorDetails, fmt.Sprintf("%s: %v", branch.FullName(), err))
-				errorCount++
-			} else {
-				successCount++
+				ahead = -1
 			}
+			counts[branch.FullName()] = ahead
 		}
+		return aheadCountsMsg{counts: counts}
+	}
+}
 
-		return deleteCompleteMsg{
-			success:      successCount,
-			failed:       errorCount,
-			errorDetails: errorDetails,
-		}
+func (m *model) salvageBranch(branch *git.Branch) tea.Cmd {
+	pruner := prune.New(m.repo, m.pruneOpts)
+
+	return func() tea.Msg {
+		salvage, err := pruner.Salvage(branch)
+		return salvageCompleteMsg{branch: branch, salvage: salvage, err: err}
 	}
 }
 
-func formatAge(duration time.Duration) string {
+// FormatAge renders a duration as a friendly relative age, e.g. "2 weeks ago"
+func FormatAge(duration time.Duration) string {
 	days := int(duration.Hours() / 24)
 	if days == 0 {
 		return "today"
@@ -394,30 +818,38 @@ func formatAge(duration time.Duration) string {
 	}
 }
 
-// RunInteractiveSelection starts the interactive branch selection UI
-func RunInteractiveSelection(repo *git.Repository, branches []*git.Branch, isRemote bool, verbose bool, force bool) error {
-	items := make([]list.Item, len(branches))
+// RunInteractiveSelection starts the interactive branch selection UI. All
+// branches are passed in; those qualifying for pruning at threshold are offered.
+func RunInteractiveSelection(repo *git.Repository, branches []*git.Branch, threshold time.Duration, isRemote bool, verbose bool, pruneOpts prune.Options) error {
 	branchItems := make([]branchItem, len(branches))
 
 	for i, branch := range branches {
 		branchItems[i] = branchItem{
 			branch:   branch,
 			selected: false,
+			ahead:    -1,
 		}
-		items[i] = branchItems[i]
 	}
 
-	const defaultWidth = 80
-	const listHeight = 20
+	m := newModel(repo, branchItems, threshold, verbose, pruneOpts)
+	m.isRemote = isRemote
+	return run(m)
+}
 
-	l := list.New(items, itemDelegate{}, defaultWidth, listHeight)
+// newModel sets up the branch list and the model around the items
+func newModel(repo *git.Repository, branchItems []branchItem, threshold time.Duration, verbose bool, pruneOpts prune.Options) model {
+	// The theme is set up once the flags are parsed, after the styles were first built
+	applyTheme(theme.Colors())
 
-	// Elegant title with bonsai metaphor
-	branchType := "local"
-	if isRemote {
-		branchType = "remote"
+	branches := make([]*git.Branch, len(branchItems))
+	for i, item := range branchItems {
+		branches[i] = item.branch
 	}
-	l.Title = fmt.Sprintf("🌿 Branches ready for pruning (%s)", branchType)
+
+	const defaultWidth = 80
+	const listHeight = 20
+
+	l := list.New(nil, itemDelegate{}, defaultWidth, listHeight)
 
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
@@ -444,24 +876,89 @@ func RunInteractiveSelection(repo *git.Repository, branches []*git.Branch, isRem
 				key.WithKeys("enter", "d"),
 				key.WithHelp("enter/d", "delete"),
 			),
+			key.NewBinding(
+				key.WithKeys("A"),
+				key.WithHelp("A", "archive & delete"),
+			),
+			key.NewBinding(
+				key.WithKeys("s"),
+				key.WithHelp("s", "salvage patches"),
+			),
+			key.NewBinding(
+				key.WithKeys("tab"),
+				key.WithHelp("tab", "details"),
+			),
+			key.NewBinding(
+				key.WithKeys("o"),
+				key.WithHelp("o", "sort order"),
+			),
+			key.NewBinding(
+				key.WithKeys("t"),
+				key.WithHelp("t", "group by prefix"),
+			),
+			key.NewBinding(
+				key.WithKeys("c", "C"),
+				key.WithHelp("c/C", "fold group/all"),
+			),
+			key.NewBinding(
+				key.WithKeys("+", "-", "="),
+				key.WithHelp("+/-/=", "age threshold"),
+			),
+			key.NewBinding(
+				key.WithKeys("R"),
+				key.WithHelp("R", "rename"),
+			),
+			key.NewBinding(
+				key.WithKeys("S"),
+				key.WithHelp("S", "check out"),
+			),
+			key.NewBinding(
+				key.WithKeys("P"),
+				key.WithHelp("P", "protect"),
+			),
+			key.NewBinding(
+				key.WithKeys("p"),
+				key.WithHelp("p", "pin"),
+			),
+			key.NewBinding(
+				key.WithKeys("z"),
+				key.WithHelp("z", "snooze"),
+			),
+			key.NewBinding(
+				key.WithKeys("H"),
+				key.WithHelp("H", "show held"),
+			),
 		}
 	}
 
-	m := model{
-		list:     l,
-		repo:     repo,
-		branches: branches,
-		items:    branchItems,
-		isRemote: isRemote,
-		verbose:  verbose,
-		force:    force,
+	return model{
+		list:      l,
+		repo:      repo,
+		branches:  branches,
+		items:     branchItems,
+		verbose:   verbose,
+		pruneOpts: pruneOpts,
+		details:   map[string]*detailsEntry{},
+		collapsed: map[string]bool{},
+		progress:  newProgress(),
+		threshold: threshold,
 	}
+}
+
+// run shows the model until the user is done with it
+func run(m model) error {
+	// Lay out the rows and the title, oldest branches first
+	m.refreshList()
 
 	p := tea.NewProgram(m)
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err != nil {
 		return fmt.Errorf("error running interactive selection: %w", err)
 	}
 
+	if m, ok := final.(model); ok {
+		return m.outcome()
+	}
 	return nil
 }
 
@@ -471,7 +968,7 @@ func (d itemDelegate) Height() int                             { return 2 }
 func (d itemDelegate) Spacing() int                            { return 1 }
 func (d itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
-	i, ok := listItem.(branchItem)
+	i, ok := listItem.(list.DefaultItem)
 	if !ok {
 		return
 	}
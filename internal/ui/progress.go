@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+)
+
+// deleteStatus is where a branch stands in a running deletion
+type deleteStatus int
+
+const (
+	statusNone deleteStatus = iota
+	statusPending
+	statusDeleting
+	statusDeleted
+	statusFailed
+	statusSkipped // Left alone because the deletion was cancelled
+)
+
+// deletion tracks a batch of branches that are pruned one at a time
+type deletion struct {
+	pruner    *prune.Pruner
+	queue     []*git.Branch
+	done      int // Branches of the queue that have been handled
+	cancelled bool
+}
+
+type branchDeletedMsg struct {
+	branch *git.Branch
+	err    error
+}
+
+// statusBadge renders the deletion status of a branch, if it has one
+func statusBadge(status deleteStatus) string {
+	switch status {
+	case statusPending:
+		return ageStyle.Render("⏳ pending")
+	case statusDeleting:
+		return lipgloss.NewStyle().Foreground(softCyan).Bold(true).Render("🌀 deleting")
+	case statusDeleted:
+		return successStyle.Render("✓ deleted")
+	case statusFailed:
+		return lipgloss.NewStyle().Foreground(warningRed).Bold(true).Render("✗ failed")
+	case statusSkipped:
+		return ageStyle.Render("– skipped")
+	}
+	return ""
+}
+
+// startDeletion marks the branches as pending and starts pruning the first
+func (m *model) startDeletion(branches []*git.Branch, opts prune.Options) tea.Cmd {
+	m.deleting = true
+	m.notice = ""
+	m.archived = m.archived || opts.Archive
+	m.deletion = &deletion{
+		pruner: prune.New(m.repo, opts),
+		queue:  branches,
+	}
+
+	for _, branch := range branches {
+		m.setStatus(branch, statusPending, nil)
+	}
+
+	return m.deleteNext()
+}
+
+// deleteNext prunes the next branch of the queue in the background, or wraps
+// up the deletion once the queue is exhausted or it was cancelled
+func (m *model) deleteNext() tea.Cmd {
+	d := m.deletion
+	if d.cancelled || d.done == len(d.queue) {
+		return m.finishDeletion()
+	}
+
+	branch := d.queue[d.done]
+	m.setStatus(branch, statusDeleting, nil)
+	cmd := m.refreshList()
+	m.highlight(rowKey(branchItem{branch: branch}), rowKey(groupItem{prefix: groupPrefix(branch.Name)}))
+
+	pruner := d.pruner
+	return tea.Batch(cmd, func() tea.Msg {
+		_, err := pruner.Prune(branch)
+		return branchDeletedMsg{branch: branch, err: err}
+	})
+}
+
+// branchDeleted records the outcome of pruning a branch and moves on
+func (m *model) branchDeleted(msg branchDeletedMsg) tea.Cmd {
+	m.recordDeleted(msg.branch, msg.err)
+	return m.deleteNext()
+}
+
+// recordDeleted marks a handled branch as deleted, or as failed with err
+func (m *model) recordDeleted(branch *git.Branch, err error) {
+	m.deletion.done++
+
+	if err != nil {
+		m.setStatus(branch, statusFailed, err)
+		return
+	}
+	m.setStatus(branch, statusDeleted, nil)
+}
+
+// finishDeletion skips whatever a cancellation left pending, then shows the
+// results when something failed and quits otherwise
+func (m *model) finishDeletion() tea.Cmd {
+	d := m.deletion
+	for _, branch := range d.queue[d.done:] {
+		m.setStatus(branch, statusSkipped, nil)
+	}
+	m.deleting = false
+
+	for _, item := range m.items {
+		if item.status == statusFailed {
+			return m.showResults()
+		}
+	}
+
+	return m.quit()
+}
+
+// quit ends the session with a summary of every branch that was handled
+func (m *model) quit() tea.Cmd {
+	m.summarize()
+	m.quitting = true
+	return tea.Quit
+}
+
+// summarize counts what happened to the branches handled this session and
+// collects the errors of those that failed
+func (m *model) summarize() {
+	var deleted, failed, skipped int
+	m.errorDetails = nil
+	for _, item := range m.items {
+		switch item.status {
+		case statusDeleted:
+			deleted++
+		case statusFailed:
+			failed++
+			m.errorDetails = append(m.errorDetails, fmt.Sprintf("%s: %v", item.branch.FullName(), item.err))
+		case statusSkipped:
+			skipped++
+		}
+	}
+
+	verb := "Deleted"
+	if m.archived {
+		verb = "Archived and deleted"
+	}
+	m.message = fmt.Sprintf("%s %d branch(es), %d failed", verb, deleted, failed)
+	if skipped > 0 {
+		m.message += fmt.Sprintf(", %d skipped", skipped)
+	}
+}
+
+// DeleteError reports branches that could not be deleted in a session
+type DeleteError struct {
+	Deleted int // Branches that were deleted
+	Failed  int // Branches that failed to be deleted
+}
+
+func (e *DeleteError) Error() string {
+	return fmt.Sprintf("%d of %d branch(es) could not be deleted", e.Failed, e.Deleted+e.Failed)
+}
+
+// outcome reports the branches that could not be deleted, if any
+func (m model) outcome() error {
+	var deleted, failed int
+	for _, item := range m.items {
+		switch item.status {
+		case statusDeleted:
+			deleted++
+		case statusFailed:
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		return nil
+	}
+	return &DeleteError{Deleted: deleted, Failed: failed}
+}
+
+// setStatus records the deletion status of a branch and the error it failed
+// with on the row showing it
+func (m *model) setStatus(branch *git.Branch, status deleteStatus, err error) {
+	for i := range m.items {
+		item := &m.items[i]
+		if !item.shows(branch) {
+			continue
+		}
+
+		// A pair keeps the failure of one side while its other side is handled
+		if item.status == statusFailed && status != statusPending {
+			continue
+		}
+
+		item.status = status
+		item.err = err
+		item.failedSide = nil
+		if status == statusFailed {
+			item.failedSide = branch
+		}
+	}
+}
+
+// shows reports whether the row stands for branch, directly or as a side of its pair
+func (i branchItem) shows(branch *git.Branch) bool {
+	name := branch.FullName()
+	if i.pair == nil {
+		return i.branch.FullName() == name
+	}
+	for _, side := range i.pair.Sides() {
+		if side.FullName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// renderProgress renders the progress bar and what is being deleted
+func (m model) renderProgress() string {
+	d := m.deletion
+
+	percent := 0.0
+	if len(d.queue) > 0 {
+		percent = float64(d.done) / float64(len(d.queue))
+	}
+
+	status := fmt.Sprintf("🌀 Carefully pruning %d/%d", d.done+1, len(d.queue))
+	hint := "esc = stop after the current branch"
+	if d.done < len(d.queue) {
+		status += ": " + branchNameStyle.Render(d.queue[d.done].FullName())
+	}
+	if d.cancelled {
+		hint = "Stopping after the current branch..."
+	}
+
+	return lipgloss.NewStyle().MarginLeft(2).Render(lipgloss.JoinVertical(lipgloss.Left,
+		m.progress.ViewAs(percent),
+		status,
+		ageStyle.Render(hint),
+	))
+}
+
+// newProgress creates the deletion progress bar in the bonsai palette
+func newProgress() progress.Model {
+	return progress.New(
+		progress.WithGradient(string(leafGreen), string(softCyan)),
+		progress.WithWidth(60),
+	)
+}
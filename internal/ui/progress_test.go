@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+func TestSummarizeCountsHandledBranches(t *testing.T) {
+	done := &git.Branch{Name: "feature/done"}
+	failed := &git.Branch{Name: "feature/failed"}
+	left := &git.Branch{Name: "feature/left"}
+
+	m := model{items: []branchItem{{branch: done}, {branch: failed}, {branch: left}}}
+	m.deletion = &deletion{queue: []*git.Branch{done, failed, left}}
+
+	m.recordDeleted(done, nil)
+	m.recordDeleted(failed, errors.New("not fully merged"))
+	m.setStatus(left, statusSkipped, nil)
+	m.summarize()
+
+	if want := "Deleted 1 branch(es), 1 failed, 1 skipped"; m.message != want {
+		t.Errorf("message = %q, want %q", m.message, want)
+	}
+	if len(m.errorDetails) != 1 || m.errorDetails[0] != "feature/failed: not fully merged" {
+		t.Errorf("errorDetails = %v, want the failed branch", m.errorDetails)
+	}
+
+	want := []deleteStatus{statusDeleted, statusFailed, statusSkipped}
+	for i, item := range m.items {
+		if item.status != want[i] {
+			t.Errorf("%s status = %d, want %d", item.branch.Name, item.status, want[i])
+		}
+	}
+	if m.items[1].err == nil {
+		t.Error("failed branch should keep its error")
+	}
+}
+
+func TestOutcomeReportsFailedDeletions(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []deleteStatus
+		want     *DeleteError
+	}{
+		{"nothing deleted", []deleteStatus{statusNone, statusNone}, nil},
+		{"all deleted", []deleteStatus{statusDeleted, statusDeleted}, nil},
+		{"some failed", []deleteStatus{statusDeleted, statusFailed, statusSkipped}, &DeleteError{Deleted: 1, Failed: 1}},
+		{"all failed", []deleteStatus{statusFailed, statusFailed}, &DeleteError{Failed: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m model
+			for _, status := range tt.statuses {
+				m.items = append(m.items, branchItem{branch: &git.Branch{Name: "feature"}, status: status})
+			}
+
+			err := m.outcome()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("outcome() = %v, want nil", err)
+				}
+				return
+			}
+
+			var deleteErr *DeleteError
+			if !errors.As(err, &deleteErr) || *deleteErr != *tt.want {
+				t.Errorf("outcome() = %#v, want %#v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// prompt is a one-line text input whose value is handed to apply on enter
+type prompt struct {
+	input textinput.Model
+	hint  string
+	apply func(m *model, value string) tea.Cmd
+}
+
+// openPrompt shows a prompt below the list, starting out with value
+func (m *model) openPrompt(label, value, hint string, apply func(m *model, value string) tea.Cmd) tea.Cmd {
+	input := textinput.New()
+	input.Prompt = label
+	input.SetValue(value)
+	input.CharLimit = 200
+	input.Width = 40
+	m.prompt = &prompt{input: input, hint: hint, apply: apply}
+	return m.prompt.input.Focus()
+}
+
+// updatePrompt handles the keys of the open prompt
+func (m *model) updatePrompt(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		m.prompt = nil
+		return nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		p := m.prompt
+		m.prompt = nil
+		if p.input.Value() == "" {
+			return nil
+		}
+		return p.apply(m, p.input.Value())
+	}
+
+	input, cmd := m.prompt.input.Update(msg)
+	m.prompt.input = input
+	return cmd
+}
+
+// renderPrompt renders the open prompt with its hint
+func (m model) renderPrompt() string {
+	return lipgloss.NewStyle().MarginLeft(2).Render(m.prompt.input.View() +
+		ageStyle.Render("  "+m.prompt.hint+" • enter = apply • esc = cancel"))
+}
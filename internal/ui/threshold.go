@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/prune"
+)
+
+const day = 24 * time.Hour
+
+// thresholdSteps are the age thresholds +/- step through
+var thresholdSteps = []time.Duration{
+	day, 3 * day, 7 * day, 14 * day, 21 * day, 28 * day,
+	42 * day, 60 * day, 90 * day, 180 * day, 365 * day,
+}
+
+// longerThreshold returns the first step above threshold, or threshold
+// itself when it is already at the top
+func longerThreshold(threshold time.Duration) time.Duration {
+	for _, step := range thresholdSteps {
+		if step > threshold {
+			return step
+		}
+	}
+	return threshold
+}
+
+// shorterThreshold returns the last step below threshold, or threshold
+// itself when it is already at the bottom
+func shorterThreshold(threshold time.Duration) time.Duration {
+	for i := len(thresholdSteps) - 1; i >= 0; i-- {
+		if thresholdSteps[i] < threshold {
+			return thresholdSteps[i]
+		}
+	}
+	return threshold
+}
+
+// formatThreshold renders a threshold in the units --age accepts, e.g. "2w"
+func formatThreshold(threshold time.Duration) string {
+	switch {
+	case threshold <= 0:
+		return "0"
+	case threshold%(365*day) == 0:
+		return fmt.Sprintf("%dy", threshold/(365*day))
+	case threshold%(7*day) == 0:
+		return fmt.Sprintf("%dw", threshold/(7*day))
+	case threshold%(30*day) == 0:
+		return fmt.Sprintf("%dM", threshold/(30*day))
+	case threshold%day == 0:
+		return fmt.Sprintf("%dd", threshold/day)
+	case threshold%time.Hour == 0:
+		return fmt.Sprintf("%dh", threshold/time.Hour)
+	}
+	return threshold.String()
+}
+
+// candidate reports whether the row's branch, or every side of its pair,
+// qualifies for pruning at the threshold
+func (i branchItem) candidate(threshold time.Duration) bool {
+	if i.pair != nil {
+		return prune.PairCandidate(i.pair, threshold)
+	}
+	return prune.Candidate(i.branch, threshold)
+}
+
+// setThreshold changes the age threshold and re-filters the list
+func (m *model) setThreshold(threshold time.Duration) tea.Cmd {
+	m.threshold = threshold
+	m.notice = fmt.Sprintf("Showing branches older than %s", formatThreshold(threshold))
+	return m.refreshList()
+}
+
+// editThreshold opens the prompt for typing a new threshold
+func (m *model) editThreshold() tea.Cmd {
+	return m.openPrompt("Age threshold: ", "", "e.g. 10d, 3w, 2M", func(m *model, value string) tea.Cmd {
+		threshold, err := config.ParseDuration(value)
+		if err != nil {
+			m.notice = fmt.Sprintf("✗ Invalid age threshold %q: %v", value, err)
+			return nil
+		}
+		return m.setThreshold(threshold)
+	})
+}
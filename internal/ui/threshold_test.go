@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kriscoleman/bonsai/internal/config"
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+func TestThresholdSteps(t *testing.T) {
+	tests := []struct {
+		threshold time.Duration
+		longer    time.Duration
+		shorter   time.Duration
+	}{
+		{14 * day, 21 * day, 7 * day},
+		{10 * day, 14 * day, 7 * day},
+		{day, 3 * day, day},
+		{365 * day, 365 * day, 180 * day},
+		{2 * 365 * day, 2 * 365 * day, 365 * day},
+	}
+
+	for _, tt := range tests {
+		if got := longerThreshold(tt.threshold); got != tt.longer {
+			t.Errorf("longerThreshold(%v) = %v, want %v", tt.threshold, got, tt.longer)
+		}
+		if got := shorterThreshold(tt.threshold); got != tt.shorter {
+			t.Errorf("shorterThreshold(%v) = %v, want %v", tt.threshold, got, tt.shorter)
+		}
+	}
+}
+
+func TestFormatThresholdRoundTrips(t *testing.T) {
+	for _, threshold := range append(thresholdSteps, 10*day, 36*time.Hour, 90*time.Minute) {
+		formatted := formatThreshold(threshold)
+		parsed, err := config.ParseDuration(formatted)
+		if err != nil {
+			t.Errorf("formatThreshold(%v) = %q, which does not parse: %v", threshold, formatted, err)
+			continue
+		}
+		if parsed != threshold {
+			t.Errorf("formatThreshold(%v) = %q, which parses as %v", threshold, formatted, parsed)
+		}
+	}
+}
+
+func TestVisibleFollowsThreshold(t *testing.T) {
+	item := testItem("feature/x", "", 10, -1)
+
+	m := model{threshold: 7 * day}
+	if !m.visible(item) {
+		t.Error("a 10 day old branch should be offered at a 1 week threshold")
+	}
+
+	m.threshold = 14 * day
+	if m.visible(item) {
+		t.Error("a 10 day old branch should not be offered at a 2 week threshold")
+	}
+
+	item.branch.IsProtected = true
+	m.threshold = day
+	if m.visible(item) {
+		t.Error("a protected branch should never be offered")
+	}
+
+	item = branchItem{branch: &git.Branch{Name: "done"}, status: statusDeleted}
+	m.reviewing = true
+	if !m.visible(item) {
+		t.Error("the results should show branches deletion was attempted on")
+	}
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sortMode is the order in which branches are listed
+type sortMode int
+
+const (
+	sortByAge    sortMode = iota // Oldest first
+	sortByName                   // Alphabetical
+	sortByAuthor                 // Grouped by last commit author
+	sortByAhead                  // Fewest commits ahead of the base branch first
+)
+
+var sortModeNames = [...]string{"age", "name", "author", "ahead"}
+
+func (s sortMode) String() string {
+	return sortModeNames[s]
+}
+
+// next returns the sort mode that follows s, wrapping around
+func (s sortMode) next() sortMode {
+	return (s + 1) % sortMode(len(sortModeNames))
+}
+
+// groupStyle renders the name prefix of a folded group of branches; applyTheme
+// sets it
+var groupStyle lipgloss.Style
+
+// groupItem is a collapsible tree node for the branches sharing a name prefix
+type groupItem struct {
+	prefix    string
+	total     int
+	selected  int
+	oldest    time.Time
+	collapsed bool
+}
+
+func (g groupItem) FilterValue() string {
+	return g.prefix
+}
+
+func (g groupItem) Title() string {
+	fold := "▾"
+	if g.collapsed {
+		fold = "▸"
+	}
+
+	checkbox := lipgloss.NewStyle().Foreground(mutedGray).Bold(true).Render("○")
+	switch {
+	case g.selected == g.total:
+		checkbox = lipgloss.NewStyle().Foreground(leafGreen).Bold(true).Render("●")
+	case g.selected > 0:
+		checkbox = lipgloss.NewStyle().Foreground(leafGreen).Bold(true).Render("◐")
+	}
+
+	return fmt.Sprintf("%s %s 🌿 %s %s",
+		fold,
+		checkbox,
+		groupStyle.Render(g.prefix),
+		ageStyle.Render(fmt.Sprintf("(%d branches)", g.total)),
+	)
+}
+
+func (g groupItem) Description() string {
+	return fmt.Sprintf("  %s",
+		commitMsgStyle.Render(fmt.Sprintf("%d selected • oldest %s", g.selected, FormatAge(time.Since(g.oldest)))))
+}
+
+// groupPrefix returns the prefix a branch name is folded under, such as
+// "feature/" or "users/jane/", or "" when the name has no prefix
+func groupPrefix(name string) string {
+	first, rest, found := strings.Cut(name, "/")
+	if !found || first == "" || rest == "" {
+		return ""
+	}
+
+	// Personal namespaces get a node per user rather than one for everyone
+	if first == "users" || first == "user" {
+		if second, tail, ok := strings.Cut(rest, "/"); ok && second != "" && tail != "" {
+			return first + "/" + second + "/"
+		}
+	}
+
+	return first + "/"
+}
+
+// sortItems orders branch items by mode, breaking ties by name
+func sortItems(items []branchItem, mode sortMode) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		switch mode {
+		case sortByAge:
+			if !a.branch.ActiveAt().Equal(b.branch.ActiveAt()) {
+				return a.branch.ActiveAt().Before(b.branch.ActiveAt())
+			}
+		case sortByAuthor:
+			if authorA, authorB := strings.ToLower(a.branch.LastAuthor), strings.ToLower(b.branch.LastAuthor); authorA != authorB {
+				return authorA < authorB
+			}
+		case sortByAhead:
+			// Branches whose count is unknown go last
+			if a.ahead != b.ahead {
+				if a.ahead < 0 || b.ahead < 0 {
+					return b.ahead < 0
+				}
+				return a.ahead < b.ahead
+			}
+		}
+		return a.branch.FullName() < b.branch.FullName()
+	})
+}
+
+// buildRows lays out sorted branch items as list rows. When grouped, branches
+// sharing a name prefix are folded under a group node placed where the first
+// of them sorts; collapsed groups hide their branches.
+func buildRows(items []branchItem, grouped bool, collapsed map[string]bool) []list.Item {
+	rows := make([]list.Item, 0, len(items))
+	if !grouped {
+		for _, item := range items {
+			item.grouped = false
+			rows = append(rows, item)
+		}
+		return rows
+	}
+
+	members := map[string][]branchItem{}
+	for _, item := range items {
+		prefix := groupPrefix(item.branch.Name)
+		members[prefix] = append(members[prefix], item)
+	}
+
+	placed := map[string]bool{}
+	for _, item := range items {
+		prefix := groupPrefix(item.branch.Name)
+
+		// A prefix shared by a single branch is not worth a node of its own
+		if prefix == "" || len(members[prefix]) < 2 {
+			item.grouped = false
+			rows = append(rows, item)
+			continue
+		}
+		if placed[prefix] {
+			continue
+		}
+		placed[prefix] = true
+
+		group := groupItem{prefix: prefix, total: len(members[prefix]), collapsed: collapsed[prefix]}
+		for _, member := range members[prefix] {
+			if member.selected {
+				group.selected++
+			}
+			if group.oldest.IsZero() || member.branch.ActiveAt().Before(group.oldest) {
+				group.oldest = member.branch.ActiveAt()
+			}
+		}
+		rows = append(rows, group)
+
+		if group.collapsed {
+			continue
+		}
+		for _, member := range members[prefix] {
+			member.grouped = true
+			rows = append(rows, member)
+		}
+	}
+
+	return rows
+}
+
+// rowKey identifies a list row across rebuilds of the list
+func rowKey(row list.Item) string {
+	switch row := row.(type) {
+	case branchItem:
+		return "branch:" + row.branch.FullName()
+	case groupItem:
+		return "group:" + row.prefix
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+func TestGroupPrefix(t *testing.T) {
+	tests := map[string]string{
+		"feature/login":       "feature/",
+		"bugfix/crash/ios":    "bugfix/",
+		"users/jane/spike":    "users/jane/",
+		"users/jane":          "users/",
+		"main":                "",
+		"trailing/":           "",
+		"/leading":            "",
+		"user/john/cleanup/a": "user/john/",
+	}
+
+	for name, want := range tests {
+		if got := groupPrefix(name); got != want {
+			t.Errorf("groupPrefix(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func testItem(name, author string, daysOld, ahead int) branchItem {
+	return branchItem{
+		branch: &git.Branch{
+			Name:         name,
+			LastAuthor:   author,
+			LastCommitAt: time.Now().Add(-time.Duration(daysOld) * 24 * time.Hour),
+		},
+		ahead: ahead,
+	}
+}
+
+func names(items []branchItem) []string {
+	var result []string
+	for _, item := range items {
+		result = append(result, item.branch.Name)
+	}
+	return result
+}
+
+func TestSortItems(t *testing.T) {
+	items := []branchItem{
+		testItem("b", "zoe", 10, 3),
+		testItem("a", "Yan", 40, -1),
+		testItem("c", "yan", 20, 0),
+	}
+
+	tests := []struct {
+		mode sortMode
+		want []string
+	}{
+		{sortByAge, []string{"a", "c", "b"}},
+		{sortByName, []string{"a", "b", "c"}},
+		{sortByAuthor, []string{"a", "c", "b"}},
+		{sortByAhead, []string{"c", "b", "a"}},
+	}
+
+	for _, tt := range tests {
+		sortItems(items, tt.mode)
+		got := names(items)
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("sortItems(%s) = %v, want %v", tt.mode, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSortModeNextWraps(t *testing.T) {
+	if got := sortByAhead.next(); got != sortByAge {
+		t.Errorf("sortByAhead.next() = %s, want %s", got, sortByAge)
+	}
+}
+
+func TestBuildRows(t *testing.T) {
+	items := []branchItem{
+		testItem("feature/old", "", 50, -1),
+		testItem("spike", "", 40, -1),
+		testItem("bugfix/lonely", "", 30, -1),
+		testItem("feature/new", "", 10, -1),
+	}
+	items[3].selected = true
+
+	rowKeys := func(rows []list.Item) []string {
+		var keys []string
+		for _, row := range rows {
+			keys = append(keys, rowKey(row))
+		}
+		return keys
+	}
+
+	flat := buildRows(items, false, nil)
+	if len(flat) != len(items) {
+		t.Fatalf("buildRows(ungrouped) returned %d rows, want %d", len(flat), len(items))
+	}
+
+	grouped := buildRows(items, true, map[string]bool{})
+	want := []string{"group:feature/", "branch:feature/old", "branch:feature/new", "branch:spike", "branch:bugfix/lonely"}
+	if got := rowKeys(grouped); len(got) != len(want) {
+		t.Fatalf("buildRows(grouped) = %v, want %v", got, want)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("buildRows(grouped) = %v, want %v", got, want)
+			}
+		}
+	}
+
+	group := grouped[0].(groupItem)
+	if group.total != 2 || group.selected != 1 || !group.oldest.Equal(items[0].branch.LastCommitAt) {
+		t.Errorf("group = %+v, want 2 branches with 1 selected and the oldest commit time", group)
+	}
+	if !grouped[1].(branchItem).grouped || grouped[3].(branchItem).grouped {
+		t.Error("buildRows() should indent only branches inside a group")
+	}
+
+	collapsed := buildRows(items, true, map[string]bool{"feature/": true})
+	if got := rowKeys(collapsed); len(got) != 3 || got[0] != "group:feature/" || !collapsed[0].(groupItem).collapsed {
+		t.Errorf("buildRows(collapsed) = %v, want the folded group followed by the ungrouped branches", got)
+	}
+}
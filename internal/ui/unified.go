@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kriscoleman/bonsai/internal/git"
+	"github.com/kriscoleman/bonsai/internal/prune"
+)
+
+// pairScope is which sides of a local/remote pair a deletion removes
+type pairScope int
+
+const (
+	scopeBoth pairScope = iota
+	scopeLocal
+	scopeRemote
+)
+
+// sides returns the branches of pair that the scope covers
+func (s pairScope) sides(pair *git.BranchPair) []*git.Branch {
+	switch s {
+	case scopeLocal:
+		if pair.Local != nil {
+			return []*git.Branch{pair.Local}
+		}
+		return nil
+	case scopeRemote:
+		if pair.Remote != nil {
+			return []*git.Branch{pair.Remote}
+		}
+		return nil
+	}
+	return pair.Sides()
+}
+
+// scopeHint renders the scope choices with the current one highlighted
+func scopeHint(scope pairScope) string {
+	choices := []string{"b = both", "l = local only", "r = remote only"}
+	for i := range choices {
+		if pairScope(i) == scope {
+			choices[i] = lipgloss.NewStyle().Foreground(leafGreen).Bold(true).Render("▸ " + choices[i])
+		} else {
+			choices[i] = ageStyle.Render(choices[i])
+		}
+	}
+	return strings.Join(choices, ageStyle.Render(" • "))
+}
+
+// pairBadge renders which counterparts a logical branch has and how they
+// relate, e.g. "[local ⇄ origin]" when the local branch tracks the remote one
+func pairBadge(pair *git.BranchPair) string {
+	badge := ""
+	switch {
+	case pair.Local != nil && pair.Remote != nil && pair.Tracking:
+		badge = "local ⇄ " + pair.Remote.RemoteName
+	case pair.Local != nil && pair.Remote != nil:
+		badge = "local · " + pair.Remote.RemoteName
+	case pair.Local != nil && pair.Track == "gone":
+		badge = "local, upstream gone"
+	case pair.Local != nil:
+		badge = "local only"
+	default:
+		badge = pair.Remote.RemoteName + " only"
+	}
+
+	if pair.Tracking && pair.Track != "" {
+		badge += ", " + pair.Track
+	}
+
+	return lipgloss.NewStyle().Foreground(softCyan).Render("[" + badge + "]")
+}
+
+// renderPairs lists the pairs of the confirmation with the sides its scope
+// would delete
+func (c *confirmation) renderPairs() []string {
+	var lines []string
+	for i, pair := range c.pairs {
+		if i == confirmListLimit {
+			lines = append(lines, ageStyle.Render(fmt.Sprintf("  ... and %d more", len(c.pairs)-confirmListLimit)))
+			break
+		}
+
+		var names []string
+		for _, side := range c.scope.sides(pair) {
+			names = append(names, branchNameStyle.Render(side.FullName()))
+		}
+		if len(names) == 0 {
+			lines = append(lines, ageStyle.Render(fmt.Sprintf("  • %s (nothing to delete)", pair.Name)))
+			continue
+		}
+		lines = append(lines, "  • "+strings.Join(names, ageStyle.Render(" + ")))
+	}
+	return lines
+}
+
+// RunUnifiedSelection starts the interactive selection UI with one row per
+// logical branch, pairing local branches with their counterparts on remote.
+// Pairs whose every side qualifies for pruning at threshold are offered.
+func RunUnifiedSelection(repo *git.Repository, pairs []*git.BranchPair, threshold time.Duration, remote string, verbose bool, pruneOpts prune.Options) error {
+	branchItems := make([]branchItem, len(pairs))
+
+	for i, pair := range pairs {
+		branchItems[i] = branchItem{
+			branch: pair.Primary(),
+			ahead:  -1,
+			pair:   pair,
+		}
+	}
+
+	m := newModel(repo, branchItems, threshold, verbose, pruneOpts)
+	m.unified = true
+	m.remoteName = remote
+	return run(m)
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kriscoleman/bonsai/internal/git"
+)
+
+func TestConfirmationTargetsFollowScope(t *testing.T) {
+	local := &git.Branch{Name: "feature"}
+	remote := &git.Branch{Name: "feature", IsRemote: true, RemoteName: "origin"}
+	remoteOnly := &git.Branch{Name: "spike", IsRemote: true, RemoteName: "origin"}
+
+	c := &confirmation{pairs: []*git.BranchPair{
+		{Name: "feature", Local: local, Remote: remote, Tracking: true},
+		{Name: "spike", Remote: remoteOnly},
+	}}
+
+	tests := []struct {
+		scope pairScope
+		want  []*git.Branch
+	}{
+		{scopeBoth, []*git.Branch{local, remote, remoteOnly}},
+		{scopeLocal, []*git.Branch{local}},
+		{scopeRemote, []*git.Branch{remote, remoteOnly}},
+	}
+
+	for _, tt := range tests {
+		c.scope = tt.scope
+		got := c.targets()
+		if len(got) != len(tt.want) {
+			t.Errorf("targets() with scope %d = %v, want %v", tt.scope, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("targets() with scope %d = %v, want %v", tt.scope, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSetStatusKeepsFailedSideOfPair(t *testing.T) {
+	local := &git.Branch{Name: "feature"}
+	remote := &git.Branch{Name: "feature", IsRemote: true, RemoteName: "origin"}
+	pair := &git.BranchPair{Name: "feature", Local: local, Remote: remote}
+
+	m := model{items: []branchItem{{branch: local, pair: pair}}}
+
+	m.setStatus(local, statusPending, nil)
+	m.setStatus(remote, statusPending, nil)
+	m.setStatus(local, statusFailed, errors.New("error: the branch 'feature' is not fully merged"))
+	m.setStatus(remote, statusDeleting, nil)
+	m.setStatus(remote, statusDeleted, nil)
+
+	item := m.items[0]
+	if item.status != statusFailed || item.failedSide != local {
+		t.Errorf("status = %d, failed side = %v, want the local failure to stick", item.status, item.failedSide)
+	}
+	if !item.retryable() {
+		t.Error("pair whose local side is not fully merged should be retryable")
+	}
+
+	m.setStatus(local, statusPending, nil)
+	if m.items[0].status != statusPending || m.items[0].failedSide != nil {
+		t.Errorf("a retry should reset the pair, got %+v", m.items[0])
+	}
+}